@@ -16,10 +16,12 @@ import (
 	"io"
 	"net/http"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/rclone/rclone/backend/b2/api"
 	"github.com/rclone/rclone/fs"
@@ -27,16 +29,21 @@ import (
 	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/configstruct"
+	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/fshttp"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/lib/bucket"
 	"github.com/rclone/rclone/lib/encoder"
 	"github.com/rclone/rclone/lib/multipart"
 	"github.com/rclone/rclone/lib/pacer"
+	"github.com/rclone/rclone/lib/percent"
 	"github.com/rclone/rclone/lib/pool"
+	"github.com/rclone/rclone/lib/random"
 	"github.com/rclone/rclone/lib/rest"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -46,6 +53,8 @@ const (
 	timeHeader          = headerPrefix + timeKey
 	sha1Key             = "large_file_sha1"
 	sha1Header          = "X-Bz-Content-Sha1"
+	crc32cKey           = "crc32c"
+	crc32cHeader        = headerPrefix + crc32cKey
 	testModeHeader      = "X-Bz-Test-Mode"
 	idHeader            = "X-Bz-File-Id"
 	nameHeader          = "X-Bz-File-Name"
@@ -53,7 +62,8 @@ const (
 	retryAfterHeader    = "Retry-After"
 	minSleep            = 10 * time.Millisecond
 	maxSleep            = 5 * time.Minute
-	decayConstant       = 1 // bigger for slower decay, exponential
+	decayConstant       = 1   // bigger for slower decay, exponential
+	retryJitter         = 0.5 // fraction of retry sleeps to randomize
 	maxParts            = 10000
 	maxVersions         = 100 // maximum number of versions we search in --b2-versions mode
 	minChunkSize        = 5 * fs.Mebi
@@ -61,12 +71,23 @@ const (
 	defaultUploadCutoff = 200 * fs.Mebi
 	largeFileCopyCutoff = 4 * fs.Gibi // 5E9 is the max
 	defaultMaxAge       = 24 * time.Hour
+	maxFileNameLength   = 1024 // max length of a file name in bytes
+	maxSegmentLength    = 250  // max length of a file name segment between slashes in bytes
+	minBucketNameLength = 6
+	maxBucketNameLength = 63
+)
+
+// API styles
+const (
+	apiStyleNative = "native" // the native B2 API
+	apiStyleS3     = "s3"     // the S3 compatible API
 )
 
 // Globals
 var (
 	errNotWithVersions  = errors.New("can't modify or delete files in --b2-versions mode")
 	errNotWithVersionAt = errors.New("can't modify or delete files in --b2-version-at mode")
+	errS3NotImplemented = errors.New("api_style \"s3\" is not implemented yet - use the s3 backend with the Backblaze endpoint")
 )
 
 // Register with Fs
@@ -76,6 +97,10 @@ func init() {
 		Description: "Backblaze B2",
 		NewFs:       NewFs,
 		CommandHelp: commandHelp,
+		MetadataInfo: &fs.MetadataInfo{
+			System: systemMetadataInfo,
+			Help:   `The "action" key is only returned when listing with --b2-versions.`,
+		},
 		Options: []fs.Option{{
 			Name:      "account",
 			Help:      "Account ID or Application Key ID.",
@@ -90,6 +115,21 @@ func init() {
 			Name:     "endpoint",
 			Help:     "Endpoint for the service.\n\nLeave blank normally.",
 			Advanced: true,
+		}, {
+			Name: "api_style",
+			Help: `The API to use to talk to B2.
+
+Only the native B2 API is supported at the moment. To use the S3
+compatible API use the s3 backend with the Backblaze endpoint instead.`,
+			Default: apiStyleNative,
+			Examples: []fs.OptionExample{{
+				Value: apiStyleNative,
+				Help:  "Native B2 API",
+			}, {
+				Value: apiStyleS3,
+				Help:  "S3 compatible API (not implemented yet)",
+			}},
+			Advanced: true,
 		}, {
 			Name: "test_mode",
 			Help: `A flag string for X-Bz-Test-Mode header for debugging.
@@ -173,6 +213,37 @@ for data integrity checking but can cause long delays for large files
 to start uploading.`,
 			Default:  false,
 			Advanced: true,
+		}, {
+			Name: "skip_unchanged",
+			Help: `Skip uploads which wouldn't change the file.
+
+Normally when rclone overwrites a file it uploads it, which makes a new
+version of the file with a new file ID and hides the old version.
+
+If this flag is set and the existing file has the same size and SHA1
+as the one being uploaded then rclone won't upload it. If only the
+modification time differs then rclone sets it instead. This needs a
+server-side copy on b2 so it still makes a new version with a new file
+ID, but the data isn't uploaded again.
+
+This is useful for workflows which depend on the file ID not changing
+when the content hasn't changed.`,
+			Default:  false,
+			Advanced: true,
+		}, {
+			Name: "auto_create_bucket",
+			Help: `Create the bucket on upload if it doesn't exist.
+
+If this is set (the default) then rclone will create the bucket when
+uploading if it doesn't exist, including when the bucket has been
+deleted since rclone last saw it.
+
+If it is not set then uploads to a bucket which doesn't exist will
+fail with a "directory not found" error. This is useful if the bucket
+should only ever be created deliberately with "rclone mkdir" or the
+key in use doesn't have permission to create buckets.`,
+			Default:  true,
+			Advanced: true,
 		}, {
 			Name: "download_url",
 			Help: `Custom endpoint for downloads.
@@ -188,6 +259,10 @@ The URL provided here SHOULD have the protocol and SHOULD NOT have
 a trailing slash or specify the /file/bucket subpath as rclone will
 request files with "{download_url}/file/{bucket_name}/{path}".
 
+Old versions shown with --b2-versions or --b2-version-at can only be
+read by file ID so these are always downloaded from the endpoint
+provided by Backblaze.
+
 Example:
 > https://mysubdomain.mydomain.tld
 (No trailing "/", "file" or "bucket")`,
@@ -203,6 +278,31 @@ authorization token will expire.
 The minimum value is 1 second. The maximum value is one week.`,
 			Default:  fs.Duration(7 * 24 * time.Hour),
 			Advanced: true,
+		}, {
+			Name: "idle_timeout",
+			Help: `How long an idle connection is kept open for reuse.
+
+Connections which have been idle for longer than this are closed
+rather than reused. Firewalls and NAT gateways may silently drop
+connections which have been idle for a while, which makes the next
+request on them fail and be retried slowly. If a long running mount
+sees errors after being idle, try setting this below the idle timeout
+of those devices, e.g. 30s.
+
+Set to 0 to keep idle connections open indefinitely.`,
+			Default:  fs.Duration(60 * time.Second),
+			Advanced: true,
+		}, {
+			Name: "keepalive",
+			Help: `Interval between TCP keepalive probes on connections.
+
+Keepalive probes stop intermediaries dropping connections which are
+open but not in use, and detect dead connections. Lower it if
+connections are dropped after less than the default.
+
+Set to 0 to use the Go default of 15s.`,
+			Default:  fs.Duration(30 * time.Second),
+			Advanced: true,
 		}, {
 			Name:     "memory_pool_flush_time",
 			Default:  fs.Duration(time.Minute),
@@ -240,6 +340,60 @@ See: [rclone backend lifecycle](#lifecycle) for setting lifecycles after bucket
 `,
 			Default:  0,
 			Advanced: true,
+		}, {
+			Name: "keep_versions",
+			Help: `Maximum number of versions of each file to keep on upload.
+
+If this is 0 (the default) then rclone doesn't remove any old
+versions when it uploads a file.
+
+If this is >0 then after a file has been uploaded successfully,
+rclone lists the versions of that file and deletes the oldest ones so
+that at most this many remain, including the one just uploaded.
+
+This is useful for buckets which don't have a lifecycle rule to
+remove old versions. Note that it costs an extra class C transaction
+for each file uploaded. At most 100 old versions are deleted after
+each upload, so a file with a longer history is trimmed over several
+uploads.
+`,
+			Default:  0,
+			Advanced: true,
+		}, {
+			Name: "archive_info_key",
+			Help: `File info key to store an archival date in.
+
+B2 sets the upload timestamp of a file itself and doesn't let it be
+changed, so files imported from an archive all appear to have been
+uploaded on the day of the import.
+
+If this is set, rclone writes the modification time of the source
+file, in milliseconds since the epoch, to this file info key as well
+as to src_last_modified_millis. Tools which apply retention rules can
+then read the archival date from this key. It is read back as the
+"archive-time" metadata.
+
+Note that B2's own lifecycle rules always count days from the upload
+timestamp, so they won't take this key into account.
+
+The key must be made of letters, digits, "-", "_" and "." and must
+not start with "b2-".`,
+			Default:  "",
+			Advanced: true,
+		}, {
+			Name: "crc32c",
+			Help: `Store a CRC32C checksum of uploaded files as well as the SHA1.
+
+If this is set, rclone computes the CRC32C of each file in the same
+pass that it computes the SHA1 and stores it in the "crc32c" file
+info key, base64 encoded as used by the S3 x-amz-checksum-crc32c
+header. It is read back as the "crc32c" metadata.
+
+B2 needs the file info before the upload starts, so files uploaded in
+a single part are buffered in memory (up to --b2-upload-cutoff) to
+compute the checksum. Files uploaded in chunks don't get a CRC32C.`,
+			Default:  false,
+			Advanced: true,
 		}, {
 			Name:     config.ConfigEncoding,
 			Help:     config.ConfigEncodingHelp,
@@ -259,6 +413,7 @@ type Options struct {
 	Account                       string               `config:"account"`
 	Key                           string               `config:"key"`
 	Endpoint                      string               `config:"endpoint"`
+	APIStyle                      string               `config:"api_style"`
 	TestMode                      string               `config:"test_mode"`
 	Versions                      bool                 `config:"versions"`
 	VersionAt                     fs.Time              `config:"version_at"`
@@ -268,9 +423,16 @@ type Options struct {
 	ChunkSize                     fs.SizeSuffix        `config:"chunk_size"`
 	UploadConcurrency             int                  `config:"upload_concurrency"`
 	DisableCheckSum               bool                 `config:"disable_checksum"`
+	SkipUnchanged                 bool                 `config:"skip_unchanged"`
+	AutoCreateBucket              bool                 `config:"auto_create_bucket"`
 	DownloadURL                   string               `config:"download_url"`
 	DownloadAuthorizationDuration fs.Duration          `config:"download_auth_duration"`
+	IdleTimeout                   fs.Duration          `config:"idle_timeout"`
+	KeepAlive                     fs.Duration          `config:"keepalive"`
 	Lifecycle                     int                  `config:"lifecycle"`
+	KeepVersions                  int                  `config:"keep_versions"`
+	ArchiveInfoKey                string               `config:"archive_info_key"`
+	CRC32C                        bool                 `config:"crc32c"`
 	Enc                           encoder.MultiEncoder `config:"encoding"`
 }
 
@@ -307,6 +469,7 @@ type Object struct {
 	size     int64             // Size of the object
 	mimeType string            // Content-Type of the object
 	meta     map[string]string // The object metadata if known - may be nil - with lower case keys
+	action   string            // The b2 action of this version ("upload", "hide" or "start") if known
 }
 
 // ------------------------------------------------------------
@@ -390,17 +553,10 @@ func (f *Fs) shouldRetryNoReauth(ctx context.Context, resp *http.Response, err e
 
 // shouldRetry returns a boolean as to whether this resp and err
 // deserve to be retried.  It returns the err as a convenience
+//
+// A 401 Unauthorized response reauthorizes the account before retrying.
 func (f *Fs) shouldRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
-	if resp != nil && resp.StatusCode == 401 {
-		fs.Debugf(f, "Unauthorized: %v", err)
-		// Reauth
-		authErr := f.authorizeAccount(ctx)
-		if authErr != nil {
-			err = authErr
-		}
-		return true, err
-	}
-	return f.shouldRetryNoReauth(ctx, resp, err)
+	return fs.RetryWithReauth(f, f.shouldRetryNoReauth, f.authorizeAccount, nil)(ctx, resp, err)
 }
 
 // errorHandler parses a non 2xx error response into an error
@@ -474,6 +630,35 @@ func (f *Fs) setRoot(root string) {
 	f.rootBucket, f.rootDirectory = bucket.Split(f.root)
 }
 
+// newDialer makes a dialer using the --b2-keepalive interval
+func newDialer(ctx context.Context, opt *Options) *fshttp.Dialer {
+	dialer := fshttp.NewDialer(ctx)
+	dialer.KeepAlive = time.Duration(opt.KeepAlive)
+	return dialer
+}
+
+// customizeTransport returns a function to set the idle timeout and
+// keepalive of the HTTP transport from the options
+func customizeTransport(ctx context.Context, opt *Options) func(*http.Transport) {
+	dialer := newDialer(ctx, opt)
+	return func(t *http.Transport) {
+		t.IdleConnTimeout = time.Duration(opt.IdleTimeout)
+		t.DialContext = dialer.DialContext
+	}
+}
+
+// newClient makes the client used to make API calls in the
+// configured api_style
+func newClient(ctx context.Context, opt *Options) (*rest.Client, error) {
+	switch opt.APIStyle {
+	case apiStyleNative, "":
+		return rest.NewClient(fshttp.NewClientCustom(ctx, customizeTransport(ctx, opt))).SetErrorHandler(errorHandler), nil
+	case apiStyleS3:
+		return nil, errS3NotImplemented
+	}
+	return nil, fmt.Errorf("unknown api_style %q - must be %q or %q", opt.APIStyle, apiStyleNative, apiStyleS3)
+}
+
 // NewFs constructs an Fs from the path, bucket:path
 func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
 	// Parse config into Options struct
@@ -482,6 +667,10 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if err != nil {
 		return nil, err
 	}
+	srv, err := newClient(ctx, opt)
+	if err != nil {
+		return nil, fmt.Errorf("b2: %w", err)
+	}
 	if opt.UploadCutoff < opt.ChunkSize {
 		opt.UploadCutoff = opt.ChunkSize
 		fs.Infof(nil, "b2: raising upload cutoff to chunk size: %v", opt.UploadCutoff)
@@ -500,6 +689,10 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if opt.Key == "" {
 		return nil, errors.New("key not found")
 	}
+	opt.ArchiveInfoKey, err = checkArchiveInfoKey(opt.ArchiveInfoKey)
+	if err != nil {
+		return nil, fmt.Errorf("b2: archive info key: %w", err)
+	}
 	if opt.Endpoint == "" {
 		opt.Endpoint = defaultEndpoint
 	}
@@ -508,21 +701,24 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		name:        name,
 		opt:         *opt,
 		ci:          ci,
-		srv:         rest.NewClient(fshttp.NewClient(ctx)).SetErrorHandler(errorHandler),
+		srv:         srv,
 		cache:       bucket.NewCache(),
 		_bucketID:   make(map[string]string, 1),
 		_bucketType: make(map[string]string, 1),
 		uploads:     make(map[string][]*api.GetUploadURLResponse),
-		pacer:       fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant))),
+		pacer:       fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant), pacer.Jitter(retryJitter))),
 		uploadToken: pacer.NewTokenDispenser(ci.Transfers),
 	}
 	f.setRoot(root)
 	f.features = (&fs.Features{
 		ReadMimeType:          true,
 		WriteMimeType:         true,
+		ReadMetadata:          true,
 		BucketBased:           true,
 		BucketBasedRootOK:     true,
 		ChunkWriterDoesntSeek: true,
+		GetTier:               true,
+		SetTier:               true,
 	}).Fill(ctx, f)
 	// Set the test flag if required
 	if opt.TestMode != "" {
@@ -603,7 +799,7 @@ func (f *Fs) hasPermission(permission string) bool {
 func (f *Fs) getUploadURL(ctx context.Context, bucket string) (upload *api.GetUploadURLResponse, err error) {
 	f.uploadMu.Lock()
 	defer f.uploadMu.Unlock()
-	bucketID, err := f.getBucketID(ctx, bucket)
+	bucketID, err := f.getUploadBucketID(ctx, bucket)
 	if err != nil {
 		return nil, err
 	}
@@ -649,6 +845,25 @@ func (f *Fs) clearUploadURL(bucketID string) {
 	f.uploadMu.Unlock()
 }
 
+// Reconnect forces a fresh authorization of the account and
+// discards any upload URLs obtained with the old authorization.
+func (f *Fs) Reconnect(ctx context.Context) error {
+	err := f.authorizeAccount(ctx)
+	if err != nil {
+		return err
+	}
+	f.uploadMu.Lock()
+	bucketIDs := make([]string, 0, len(f.uploads))
+	for bucketID := range f.uploads {
+		bucketIDs = append(bucketIDs, bucketID)
+	}
+	f.uploadMu.Unlock()
+	for _, bucketID := range bucketIDs {
+		f.clearUploadURL(bucketID)
+	}
+	return nil
+}
+
 // getRW gets a RW buffer and an upload token
 //
 // If noBuf is set then it just gets an upload token
@@ -839,14 +1054,17 @@ func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *api.File
 	} else {
 		*last = remote
 	}
-	// hide objects represent deleted files which we don't list
-	if object.Action == "hide" {
-		return nil, nil
-	}
 	o, err := f.newObjectWithInfo(ctx, remote, object)
 	if err != nil {
 		return nil, err
 	}
+	// hide objects represent deleted files which we don't list
+	if o.(*Object).isHideMarker() {
+		if f.opt.Versions {
+			fs.Debugf(o, "Skipping hide marker (id %q) dated %v", object.ID, time.Time(object.UploadTimestamp).Local())
+		}
+		return nil, nil
+	}
 	return o, nil
 }
 
@@ -1067,6 +1285,27 @@ func (f *Fs) getBucketID(ctx context.Context, bucket string) (bucketID string, e
 	return bucketID, err
 }
 
+// getUploadBucketID finds the ID for the bucket to upload to
+//
+// If the bucket isn't found and auto_create_bucket is set then it
+// creates the bucket first. Concurrent creates are serialised by the
+// bucket cache.
+func (f *Fs) getUploadBucketID(ctx context.Context, bucket string) (bucketID string, err error) {
+	bucketID, err = f.getBucketID(ctx, bucket)
+	if err != fs.ErrorDirNotFound || !f.opt.AutoCreateBucket {
+		return bucketID, err
+	}
+	fs.Debugf(f, "Bucket %q not found - creating it", bucket)
+	// The cache may think the bucket exists if it was deleted
+	// behind our back so make sure makeBucket creates it
+	f.cache.MarkDeleted(bucket)
+	err = f.makeBucket(ctx, bucket)
+	if err != nil {
+		return "", err
+	}
+	return f.getBucketID(ctx, bucket)
+}
+
 // setBucketID sets the ID for the current bucket name
 func (f *Fs) setBucketID(bucket, ID string) {
 	f.bucketIDMutex.Lock()
@@ -1109,6 +1348,13 @@ func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 // makeBucket creates the bucket if it doesn't exist
 func (f *Fs) makeBucket(ctx context.Context, bucket string) error {
 	return f.cache.Create(bucket, func() error {
+		if err := checkBucketName(f.opt.Enc.FromStandardName(bucket)); err != nil {
+			// Use the bucket if it exists in case it predates the rules
+			if _, getBucketErr := f.getBucketID(ctx, bucket); getBucketErr == nil {
+				return nil
+			}
+			return err
+		}
 		opts := rest.Opts{
 			Method: "POST",
 			Path:   "/b2_create_bucket",
@@ -1194,6 +1440,10 @@ func (f *Fs) Precision() time.Duration {
 
 // hide hides a file on the remote
 func (f *Fs) hide(ctx context.Context, bucket, bucketPath string) error {
+	if fs.GetConfig(ctx).DryRun {
+		fs.Logf(bucketPath, "Not hiding as --dry-run is set")
+		return nil
+	}
 	bucketID, err := f.getBucketID(ctx, bucket)
 	if err != nil {
 		return err
@@ -1226,6 +1476,10 @@ func (f *Fs) hide(ctx context.Context, bucket, bucketPath string) error {
 
 // deleteByID deletes a file version given Name and ID
 func (f *Fs) deleteByID(ctx context.Context, ID, Name string) error {
+	if fs.GetConfig(ctx).DryRun {
+		fs.Logf(Name, "Not deleting version (id %q) as --dry-run is set", ID)
+		return nil
+	}
 	opts := rest.Opts{
 		Method: "POST",
 		Path:   "/b2_delete_file_version",
@@ -1337,14 +1591,20 @@ func (f *Fs) purge(ctx context.Context, dir string, oldOnly bool, deleteHidden b
 	close(toBeDeleted)
 	wg.Wait()
 
-	if !oldOnly {
+	if !oldOnly && !fs.GetConfig(ctx).DryRun {
 		checkErr(f.Rmdir(ctx, dir))
 	}
 	return errReturn
 }
 
 // Purge deletes all the files and directories including the old versions.
+//
+// If filters are in use it returns fs.ErrorCantPurge so that only the
+// matching files are deleted one by one instead.
 func (f *Fs) Purge(ctx context.Context, dir string) error {
+	if !filter.GetConfig(ctx).InActive() {
+		return fs.ErrorCantPurge
+	}
 	return f.purge(ctx, dir, false, false, false, defaultMaxAge)
 }
 
@@ -1382,12 +1642,14 @@ func (f *Fs) copy(ctx context.Context, dstObj *Object, srcObj *Object, newInfo *
 	}
 
 	dstBucket, dstPath := dstObj.split()
-	err = f.makeBucket(ctx, dstBucket)
-	if err != nil {
-		return err
+	if f.opt.AutoCreateBucket {
+		err = f.makeBucket(ctx, dstBucket)
+		if err != nil {
+			return err
+		}
 	}
 
-	destBucketID, err := f.getBucketID(ctx, dstBucket)
+	destBucketID, err := f.getUploadBucketID(ctx, dstBucket)
 	if err != nil {
 		return err
 	}
@@ -1563,6 +1825,65 @@ func (o *Object) Size() int64 {
 	return o.size
 }
 
+// checkFileName checks the (encoded) file name against B2's rules,
+// returning an error wrapping fs.ErrorFileNameInvalid if it breaks
+// them.
+//
+// See: https://www.backblaze.com/docs/cloud-storage-files
+func checkFileName(name string) error {
+	invalid := func(reason string) error {
+		return fserrors.NoRetryError(fmt.Errorf("%w: %q %s", fs.ErrorFileNameInvalid, name, reason))
+	}
+	switch {
+	case name == "":
+		return invalid("is empty")
+	case len(name) > maxFileNameLength:
+		return invalid(fmt.Sprintf("is longer than %d bytes", maxFileNameLength))
+	case !utf8.ValidString(name):
+		return invalid("is not valid UTF-8")
+	case strings.HasPrefix(name, "/"):
+		return invalid("starts with /")
+	case strings.HasSuffix(name, "/"):
+		return invalid("ends with /")
+	case strings.Contains(name, "//"):
+		return invalid("contains //")
+	}
+	for _, c := range name {
+		if c < 32 || c == 127 {
+			return invalid(fmt.Sprintf("contains control character %#x", c))
+		}
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if len(segment) > maxSegmentLength {
+			return invalid(fmt.Sprintf("has a segment longer than %d bytes", maxSegmentLength))
+		}
+	}
+	return nil
+}
+
+// checkBucketName checks the (encoded) bucket name against B2's
+// rules, returning an error wrapping fs.ErrorFileNameInvalid if it
+// breaks them.
+//
+// See: https://www.backblaze.com/docs/cloud-storage-buckets
+func checkBucketName(name string) error {
+	invalid := func(reason string) error {
+		return fserrors.NoRetryError(fmt.Errorf("%w: bucket name %q %s", fs.ErrorFileNameInvalid, name, reason))
+	}
+	if len(name) < minBucketNameLength || len(name) > maxBucketNameLength {
+		return invalid(fmt.Sprintf("must be between %d and %d characters long", minBucketNameLength, maxBucketNameLength))
+	}
+	if strings.HasPrefix(strings.ToLower(name), "b2-") {
+		return invalid("must not start with \"b2-\"")
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return invalid("may only contain letters, digits and \"-\"")
+		}
+	}
+	return nil
+}
+
 // Clean the SHA1
 //
 // Make sure it is lower case.
@@ -1592,15 +1913,29 @@ func (o *Object) decodeMetaDataRaw(ID, SHA1 string, Size int64, UploadTimestamp
 	}
 	o.sha1 = cleanSHA1(o.sha1)
 	o.size = Size
-	// Use the UploadTimestamp if can't get file info
+	// Use the UploadTimestamp if can't get file info or if
+	// --use-server-modtime is set
 	o.modTime = time.Time(UploadTimestamp)
-	err = o.parseTimeString(Info[timeKey])
-	if err != nil {
-		return err
+	if !o.fs.ci.UseServerModTime {
+		err = o.parseTimeString(Info[timeKey])
+		if err != nil {
+			return err
+		}
 	}
-	// For now, just set "mtime" in metadata
-	o.meta = make(map[string]string, 1)
+	// For now, just set "mtime" and "archive-time" in metadata
+	o.meta = make(map[string]string, 2)
 	o.meta["mtime"] = o.modTime.Format(time.RFC3339Nano)
+	if key := o.fs.opt.ArchiveInfoKey; key != "" && Info[key] != "" {
+		archiveTime, err := parseTimeStringHelper(Info[key])
+		if err != nil {
+			fs.Debugf(o, "Failed to parse archive time %s=%q: %v", key, Info[key], err)
+		} else {
+			o.meta["archive-time"] = archiveTime.Format(time.RFC3339Nano)
+		}
+	}
+	if crc := Info[crc32cKey]; crc != "" {
+		o.meta["crc32c"] = crc
+	}
 	return nil
 }
 
@@ -1613,6 +1948,7 @@ func (o *Object) decodeMetaDataRaw(ID, SHA1 string, Size int64, UploadTimestamp
 //	o.size
 //	o.sha1
 func (o *Object) decodeMetaData(info *api.File) (err error) {
+	o.action = info.Action
 	return o.decodeMetaDataRaw(info.ID, info.SHA1, info.Size, info.UploadTimestamp, info.Info, info.ContentType)
 }
 
@@ -1625,6 +1961,7 @@ func (o *Object) decodeMetaData(info *api.File) (err error) {
 //	o.size
 //	o.sha1
 func (o *Object) decodeMetaDataFileInfo(info *api.FileInfo) (err error) {
+	o.action = info.Action
 	return o.decodeMetaDataRaw(info.ID, info.SHA1, info.Size, info.UploadTimestamp, info.Info, info.ContentType)
 }
 
@@ -1703,6 +2040,30 @@ func timeString(modTime time.Time) string {
 	return strconv.FormatInt(modTime.UnixNano()/1e6, 10)
 }
 
+// checkArchiveInfoKey checks key is usable as a B2 file info name and
+// returns it lower cased as that is how the server returns it.
+func checkArchiveInfoKey(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	key = strings.ToLower(key)
+	if len(key) > 50 {
+		return "", fmt.Errorf("%q is longer than 50 characters", key)
+	}
+	if strings.HasPrefix(key, "b2-") {
+		return "", fmt.Errorf("%q must not start with \"b2-\"", key)
+	}
+	if key == timeKey || key == sha1Key || key == crc32cKey {
+		return "", fmt.Errorf("%q is used by rclone", key)
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return "", fmt.Errorf("%q contains invalid character %q", key, c)
+		}
+	}
+	return key, nil
+}
+
 // parseTimeStringHelper converts a decimal string number of milliseconds
 // elapsed since January 1, 1970 UTC into a time.Time
 func parseTimeStringHelper(timeString string) (time.Time, error) {
@@ -1825,16 +2186,21 @@ func (o *Object) getOrHead(ctx context.Context, method string, options []fs.Open
 		NoResponse: method == "HEAD",
 	}
 
-	// Use downloadUrl from backblaze if downloadUrl is not set
-	// otherwise use the custom downloadUrl
-	if o.fs.opt.DownloadURL == "" {
+	// Download by id if set and not using DownloadURL otherwise by
+	// name. Downloading by name always returns the latest version
+	// so objects from a version listing must be downloaded by id.
+	isVersion := o.fs.opt.Versions || o.fs.opt.VersionAt.IsSet()
+	byID := o.id != "" && (o.fs.opt.DownloadURL == "" || isVersion)
+
+	// Use downloadUrl from backblaze if downloadUrl is not set or
+	// downloading by id otherwise use the custom downloadUrl
+	if o.fs.opt.DownloadURL == "" || byID {
 		opts.RootURL = o.fs.info.DownloadURL
 	} else {
 		opts.RootURL = o.fs.opt.DownloadURL
 	}
 
-	// Download by id if set and not using DownloadURL otherwise by name
-	if o.id != "" && o.fs.opt.DownloadURL == "" {
+	if byID {
 		opts.Path += "/b2api/v1/b2_download_file_by_id?fileId=" + urlEncode(o.id)
 	} else {
 		bucket, bucketPath := o.split()
@@ -1865,6 +2231,12 @@ func (o *Object) getOrHead(ctx context.Context, method string, options []fs.Open
 		k = strings.ToLower(k)
 		for _, v := range vs {
 			if strings.HasPrefix(k, headerPrefix) {
+				// The values are returned % encoded
+				if decoded, err := percent.Decode(v); err == nil {
+					v = decoded
+				} else {
+					fs.Debugf(o, "Bad %s header %q: %v", k, v, err)
+				}
 				Info[k[len(headerPrefix):]] = v
 			}
 		}
@@ -1898,6 +2270,12 @@ func (o *Object) getOrHead(ctx context.Context, method string, options []fs.Open
 }
 
 // Open an object for read
+//
+// Downloads can be resumed by opening the object with an fs.SeekOption
+// or fs.RangeOption starting at the number of bytes already read. B2
+// only has the SHA1 of the whole file so the SHA1 isn't checked on
+// partial reads. The caller should check the SHA1 of the resumed file
+// itself if it needs to.
 func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	fs.FixRangeOption(options, o.size)
 
@@ -1908,6 +2286,7 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 
 	// Don't check length or hash or metadata on partial content
 	if resp.StatusCode == http.StatusPartialContent {
+		fs.Debugf(o, "Not checking SHA1 of partial read")
 		return resp.Body, nil
 	}
 
@@ -1926,32 +2305,14 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 // letters, digits, ".", "_", "-", "/", "~", "!", "$", "'", "(", ")",
 // "*", ";", "=", ":", and "@". All other byte values in a UTF-8 must
 // be replaced with "%" and the two-digit hex value of the byte.
-const dontEncode = (`abcdefghijklmnopqrstuvwxyz` +
+var dontEncode = percent.NewSafeSet(`abcdefghijklmnopqrstuvwxyz` +
 	`ABCDEFGHIJKLMNOPQRSTUVWXYZ` +
 	`0123456789` +
 	`._-/~!$'()*;=:@`)
 
-// noNeedToEncode is a bitmap of characters which don't need % encoding
-var noNeedToEncode [256]bool
-
-func init() {
-	for _, c := range dontEncode {
-		noNeedToEncode[c] = true
-	}
-}
-
 // urlEncode encodes in with % encoding
 func urlEncode(in string) string {
-	var out bytes.Buffer
-	for i := 0; i < len(in); i++ {
-		c := in[i]
-		if noNeedToEncode[c] {
-			_ = out.WriteByte(c)
-		} else {
-			_, _ = out.WriteString(fmt.Sprintf("%%%2X", c))
-		}
-	}
-	return out.String()
+	return percent.Encode(in, dontEncode)
 }
 
 // Update the object with the contents of the io.Reader, modTime and size
@@ -1964,13 +2325,32 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	if o.fs.opt.VersionAt.IsSet() {
 		return errNotWithVersionAt
 	}
+	if o.fs.opt.SkipUnchanged {
+		unchanged, err := o.updateUnchanged(ctx, src)
+		if unchanged || err != nil {
+			return err
+		}
+	}
+	if o.fs.opt.KeepVersions > 0 {
+		defer func() {
+			if err == nil {
+				o.pruneVersions(ctx)
+			}
+		}()
+	}
 	size := src.Size()
 
 	bucket, bucketPath := o.split()
-	err = o.fs.makeBucket(ctx, bucket)
+	err = checkFileName(o.fs.opt.Enc.FromStandardPath(bucketPath))
 	if err != nil {
 		return err
 	}
+	if o.fs.opt.AutoCreateBucket {
+		err = o.fs.makeBucket(ctx, bucket)
+		if err != nil {
+			return err
+		}
+	}
 	if size < 0 {
 		// Check if the file is large enough for a chunked upload (needs to be at least two chunks)
 		rw := o.fs.getRW(false)
@@ -2022,6 +2402,18 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	}
 
 	calculatedSha1, _ := src.Hash(ctx, hash.SHA1)
+	var crc32c string
+	if o.fs.opt.CRC32C {
+		rw, sha1Sum, crc32cSum, err := bufferAndHash(in)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rw.Close()
+		}()
+		in, size = rw, rw.Size()
+		calculatedSha1, crc32c = sha1Sum, crc32cSum
+	}
 	if calculatedSha1 == "" {
 		calculatedSha1 = "hex_digits_at_end"
 		har := newHashAppendingReader(in, sha1.New())
@@ -2106,6 +2498,12 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		},
 		ContentLength: &size,
 	}
+	if key := o.fs.opt.ArchiveInfoKey; key != "" {
+		opts.ExtraHeaders[headerPrefix+key] = timeString(modTime)
+	}
+	if crc32c != "" {
+		opts.ExtraHeaders[crc32cHeader] = urlEncode(crc32c)
+	}
 	var response api.FileInfo
 	// Don't retry, return a retry error instead
 	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
@@ -2124,6 +2522,73 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	return o.decodeMetaDataFileInfo(&response)
 }
 
+// pruneVersions deletes the oldest versions of the object so that at
+// most --b2-keep-versions of them remain.
+//
+// It should be called after the upload has succeeded. It never
+// deletes the current version and logs rather than returns errors
+// since the upload itself is complete.
+func (o *Object) pruneVersions(ctx context.Context) {
+	bucket, bucketPath := o.split()
+	keep := o.fs.opt.KeepVersions
+	var old []*api.File
+	versions := 0
+	err := o.fs.list(ctx, bucket, bucketPath, "", false, true, 0, true, true, func(remote string, object *api.File, isDirectory bool) error {
+		if remote != bucketPath {
+			return errEndList // past the versions of this file
+		}
+		if isDirectory || object.Action != "upload" {
+			return nil
+		}
+		versions++
+		if versions > keep && object.ID != o.id {
+			old = append(old, object)
+		}
+		if versions >= keep+maxVersions {
+			return errEndList // only delete maxVersions at once
+		}
+		return nil
+	})
+	if err != nil {
+		fs.Errorf(o, "Failed to list versions to prune: %v", err)
+		return
+	}
+	for _, object := range old {
+		fs.Debugf(o, "Deleting old version (id %q) dated %v as --b2-keep-versions is %d", object.ID, time.Time(object.UploadTimestamp).Local(), keep)
+		err = o.fs.deleteByID(ctx, object.ID, object.Name)
+		if err != nil {
+			fs.Errorf(o, "Failed to prune old version: %v", err)
+		}
+	}
+}
+
+// updateUnchanged checks to see if src has the same size and SHA1 as
+// the existing object and returns true if so, so the upload can be
+// skipped.
+//
+// If only the modification time differs it is set with a server-side
+// copy which is much quicker than uploading the data again.
+func (o *Object) updateUnchanged(ctx context.Context, src fs.ObjectInfo) (unchanged bool, err error) {
+	if o.id == "" || src.Size() < 0 || src.Size() != o.size {
+		return false, nil
+	}
+	dstSHA1, err := o.Hash(ctx, hash.SHA1)
+	if err != nil || dstSHA1 == "" {
+		return false, nil
+	}
+	srcSHA1, err := src.Hash(ctx, hash.SHA1)
+	if err != nil || srcSHA1 != dstSHA1 {
+		return false, nil
+	}
+	modTime := src.ModTime(ctx)
+	if !o.modTime.Equal(modTime.Truncate(time.Millisecond)) {
+		fs.Debugf(o, "Content unchanged so skipping upload and setting modification time")
+		return true, o.SetModTime(ctx, modTime)
+	}
+	fs.Debugf(o, "Content unchanged so skipping upload")
+	return true, nil
+}
+
 // Get modTime from the source; if --metadata is set, fetch the src metadata and get it from there.
 // When metadata support is added to b2, this method will need a more generic name
 func (o *Object) getModTime(ctx context.Context, src fs.ObjectInfo, options []fs.OpenOption) (time.Time, error) {
@@ -2175,9 +2640,11 @@ func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectIn
 	}
 
 	bucket, _ := o.split()
-	err = f.makeBucket(ctx, bucket)
-	if err != nil {
-		return info, nil, err
+	if f.opt.AutoCreateBucket {
+		err = f.makeBucket(ctx, bucket)
+		if err != nil {
+			return info, nil, err
+		}
 	}
 
 	info = fs.ChunkWriterInfo{
@@ -2189,6 +2656,30 @@ func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectIn
 	return info, up, err
 }
 
+// OpenWriterAt opens with a handle for random access writes
+//
+// Pass in the remote desired and the size if known.
+//
+// The writes are buffered until each chunk is complete then uploaded
+// as a part of a large file. Files of one chunk or less are uploaded
+// in one go when the handle is closed.
+func (f *Fs) OpenWriterAt(ctx context.Context, remote string, size int64) (fs.WriterAtCloser, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("%s: can't open for random access writes without knowing the size", remote)
+	}
+	src := object.NewStaticObjectInfo(remote, time.Now(), size, true, nil, f)
+	chunkSize := int64(f.opt.ChunkSize)
+	if size <= chunkSize {
+		cw := &singleChunkWriter{o: &Object{fs: f, remote: remote}, src: src}
+		return newChunkWriterAt(ctx, cw, remote, chunkSize, size), nil
+	}
+	info, cw, err := f.OpenChunkWriter(ctx, remote, src)
+	if err != nil {
+		return nil, err
+	}
+	return newChunkWriterAt(ctx, cw, remote, info.ChunkSize, size), nil
+}
+
 // Remove an object
 func (o *Object) Remove(ctx context.Context) error {
 	bucket, bucketPath := o.split()
@@ -2214,6 +2705,72 @@ func (o *Object) ID() string {
 	return o.id
 }
 
+// standardTier is the only storage tier B2 has
+const standardTier = "standard"
+
+// GetTier returns the storage tier of the Object
+//
+// B2 only has one storage class so this is always the same.
+func (o *Object) GetTier() string {
+	return standardTier
+}
+
+// SetTier does nothing as B2 only has one storage class so any tier
+// other than that one is an error
+func (o *Object) SetTier(tier string) error {
+	if tier != standardTier {
+		return fmt.Errorf("tier %q not supported by B2 which only has the %q tier", tier, standardTier)
+	}
+	return nil
+}
+
+// isHideMarker returns true if this version is a b2 "hide" marker
+// rather than real file content.
+func (o *Object) isHideMarker() bool {
+	return o.action == "hide"
+}
+
+// system metadata keys which this backend owns
+var systemMetadataInfo = map[string]fs.MetadataHelp{
+	"mtime": {
+		Help:    "Time of last modification, read from the source object",
+		Type:    "RFC 3339",
+		Example: "2006-01-02T15:04:05.999Z",
+	},
+	"action": {
+		Help:     "The b2 action of this version - upload, hide or start",
+		Type:     "string",
+		Example:  "upload",
+		ReadOnly: true,
+	},
+	"archive-time": {
+		Help:     "Archival date read from the --b2-archive-info-key file info",
+		Type:     "RFC 3339",
+		Example:  "2006-01-02T15:04:05.999Z",
+		ReadOnly: true,
+	},
+	"crc32c": {
+		Help:     "Base64 encoded CRC32C of the contents, stored with --b2-crc32c",
+		Type:     "string",
+		Example:  "yZRlqg==",
+		ReadOnly: true,
+	},
+}
+
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	metadata = make(fs.Metadata, len(o.meta)+1)
+	for k, v := range o.meta {
+		metadata[k] = v
+	}
+	if o.fs.opt.Versions && o.action != "" {
+		metadata["action"] = o.action
+	}
+	return metadata, nil
+}
+
 var lifecycleHelp = fs.CommandHelp{
 	Name:  "lifecycle",
 	Short: "Read or set the lifecycle for a bucket",
@@ -2378,10 +2935,338 @@ func (f *Fs) cleanupHiddenCommand(ctx context.Context, name string, arg []string
 	return nil, f.cleanUp(ctx, true, false, 0)
 }
 
+var getInfoHelp = fs.CommandHelp{
+	Name:  "getinfo",
+	Short: "Show the raw file info for a file ID.",
+	Long: `This command calls b2_get_file_info for the file ID given and shows
+the raw file info returned by B2 as JSON.
+
+    rclone backend getinfo b2: 4_z27c88f1d182b150646ff0b16_f1004ba650fe24e6b_d20180809_m012345_c002_v0001095_t0047
+
+This will dump something like this.
+
+    {
+        "fileId": "4_z27c88f1d182b150646ff0b16_f1004ba650fe24e6b_d20180809_m012345_c002_v0001095_t0047",
+        "fileName": "path/to/file.txt",
+        "action": "upload",
+        ...
+    }
+
+File IDs can be found with ` + "`rclone lsf --format i b2:bucket`" + `.
+`,
+}
+
+func (f *Fs) getInfoCommand(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	if len(arg) != 1 {
+		return nil, errors.New("need exactly one file ID argument")
+	}
+	return f.getFileInfo(ctx, arg[0])
+}
+
+// getFileInfo reads the file info for the file ID with b2_get_file_info
+func (f *Fs) getFileInfo(ctx context.Context, ID string) (info *api.FileInfo, err error) {
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_get_file_info",
+	}
+	var request = api.GetFileInfoRequest{
+		ID: ID,
+	}
+	var response api.FileInfo
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
+		return f.shouldRetry(ctx, resp, err)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	return &response, nil
+}
+
+var bucketInfoHelp = fs.CommandHelp{
+	Name:  "bucketinfo",
+	Short: "Show the bucket info for the remote's bucket.",
+	Long: `This command shows the ID, type and account ID of the bucket the
+remote points to as JSON.
+
+    rclone backend bucketinfo b2:bucket
+
+This will dump something like this.
+
+    {
+        "bucketId": "4a48fe8875c6214145260818",
+        "accountId": "010203040506",
+        "bucketName": "bucket",
+        "bucketType": "allPrivate"
+    }
+
+The bucket ID is also stored in rclone's cache so is read from there
+by later operations.
+`,
+}
+
+func (f *Fs) bucketInfoCommand(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	if f.rootBucket == "" {
+		return nil, errors.New("need a bucket")
+	}
+	return f.getBucketInfo(ctx, f.rootBucket)
+}
+
+// getBucketInfo reads the info for bucketName with b2_list_buckets
+func (f *Fs) getBucketInfo(ctx context.Context, bucketName string) (info *api.Bucket, err error) {
+	err = f.listBucketsToFn(ctx, bucketName, func(bucket *api.Bucket) error {
+		if bucket.Name == bucketName {
+			info = bucket
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("bucket %q: %w", bucketName, fs.ErrorDirNotFound)
+	}
+	return info, nil
+}
+
+var inventoryHelp = fs.CommandHelp{
+	Name:  "inventory",
+	Short: "List the objects in all the buckets.",
+	Long: `This command lists all the objects in all the buckets of the account
+as JSON, listing --transfers buckets at once. If the remote points to
+a bucket then only that bucket is listed.
+
+    rclone backend inventory b2:
+    rclone backend inventory --transfers 8 b2:
+
+This will dump something like this.
+
+    [
+        {
+            "Path": "bucket/path/to/file.txt",
+            "Size": 6,
+            "MimeType": "text/plain",
+            "ModTime": "2024-01-02T03:04:05.006Z",
+            "ID": "4_z27c88f1d182b150646ff0b16_f1004ba650fe24e6b_d20180809_m012345_c002_v0001095_t0047",
+            "SHA1": "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"
+        },
+        ...
+    ]
+
+The paths start with the bucket name. Use --b2-versions to include
+the old versions of the files too.
+`,
+}
+
+// inventoryItem is an object listed by the inventory command
+type inventoryItem struct {
+	Path     string
+	Size     int64
+	MimeType string
+	ModTime  time.Time
+	ID       string
+	SHA1     string `json:",omitempty"`
+}
+
+func (f *Fs) inventoryCommand(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	return f.inventory(ctx)
+}
+
+// inventory lists all the objects in the buckets the remote can see
+// with bucket qualified paths, listing --transfers buckets at once.
+func (f *Fs) inventory(ctx context.Context) (items []inventoryItem, err error) {
+	var buckets []string
+	if f.rootBucket != "" {
+		buckets = append(buckets, f.rootBucket)
+	} else {
+		err = f.listBucketsToFn(ctx, "", func(bucket *api.Bucket) error {
+			buckets = append(buckets, bucket.Name)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list buckets: %w", err)
+		}
+	}
+	var mu sync.Mutex
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(f.ci.Transfers)
+	for _, bucket := range buckets {
+		bucket := bucket
+		g.Go(func() error {
+			last := ""
+			err := f.list(gCtx, bucket, f.rootDirectory, "", true, true, 0, f.opt.Versions, false, func(remote string, object *api.File, isDirectory bool) error {
+				entry, err := f.itemToDirEntry(gCtx, remote, object, isDirectory, &last)
+				if err != nil {
+					return err
+				}
+				o, ok := entry.(*Object)
+				if !ok {
+					return nil
+				}
+				mu.Lock()
+				items = append(items, inventoryItem{
+					Path:     o.remote,
+					Size:     o.size,
+					MimeType: o.mimeType,
+					ModTime:  o.modTime,
+					ID:       o.id,
+					SHA1:     o.sha1,
+				})
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list bucket %q: %w", bucket, err)
+			}
+			return nil
+		})
+	}
+	err = g.Wait()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+	return items, nil
+}
+
+var selfTestHelp = fs.CommandHelp{
+	Name:  "selftest",
+	Short: "Check the remote is configured and working.",
+	Long: `This command checks the remote can be used by authorizing with the
+account, checking the bucket exists, then uploading, downloading and
+deleting a small test object, checking its SHA1 on the way.
+
+    rclone backend selftest b2:bucket
+
+The test object is written to the directory the remote points to.
+This will dump something like this. The steps stop at the first one
+which fails, except that once the upload has succeeded the delete
+step is always run to remove the test object, even if the download
+failed.
+
+    {
+        "OK": true,
+        "Steps": [
+            {
+                "Step": "authorize",
+                "OK": true
+            },
+            {
+                "Step": "bucket",
+                "OK": true
+            },
+            ...
+        ]
+    }
+
+Failed steps have an "Error" with the reason.
+`,
+}
+
+// selfTestStep is the result of one step of the selftest command
+type selfTestStep struct {
+	Step  string
+	OK    bool
+	Error string `json:",omitempty"`
+}
+
+// selfTestResult is the output of the selftest command
+type selfTestResult struct {
+	OK    bool
+	Steps []selfTestStep
+}
+
+func (f *Fs) selfTestCommand(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	if f.rootBucket == "" {
+		return nil, errors.New("need a bucket")
+	}
+	return f.selfTest(ctx), nil
+}
+
+// selfTest checks the remote end to end with a small test object
+func (f *Fs) selfTest(ctx context.Context) (result *selfTestResult) {
+	result = &selfTestResult{OK: true}
+	step := func(name string, fn func() error) bool {
+		err := fn()
+		s := selfTestStep{Step: name, OK: err == nil}
+		if err != nil {
+			s.Error = err.Error()
+			result.OK = false
+			fs.Errorf(f, "selftest: %s failed: %v", name, err)
+		} else {
+			fs.Debugf(f, "selftest: %s OK", name)
+		}
+		result.Steps = append(result.Steps, s)
+		return err == nil
+	}
+
+	data := []byte(random.String(64))
+	sha1sum := fmt.Sprintf("%x", sha1.Sum(data))
+	o := &Object{
+		fs:     f,
+		remote: ".rclone-selftest-" + random.String(8),
+	}
+
+	if !step("authorize", func() error {
+		return f.authorizeAccount(ctx)
+	}) {
+		return result
+	}
+	if !step("bucket", func() error {
+		_, err := f.getBucketInfo(ctx, f.rootBucket)
+		return err
+	}) {
+		return result
+	}
+	if !step("upload", func() error {
+		src := object.NewStaticObjectInfo(o.remote, time.Now(), int64(len(data)), true, map[hash.Type]string{hash.SHA1: sha1sum}, nil)
+		err := o.Update(ctx, bytes.NewReader(data), src)
+		if err != nil {
+			return err
+		}
+		if o.sha1 != sha1sum {
+			return fmt.Errorf("uploaded SHA1 %q doesn't match %q", o.sha1, sha1sum)
+		}
+		return nil
+	}) {
+		return result
+	}
+	step("download", func() error {
+		in, err := o.Open(ctx)
+		if err != nil {
+			return err
+		}
+		got, err := io.ReadAll(in)
+		closeErr := in.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+		if gotSHA1 := fmt.Sprintf("%x", sha1.Sum(got)); gotSHA1 != sha1sum {
+			return fmt.Errorf("downloaded SHA1 %q doesn't match %q", gotSHA1, sha1sum)
+		}
+		return nil
+	})
+	// Delete the test object even if the download failed
+	step("delete", func() error {
+		_, bucketPath := o.split()
+		return f.deleteByID(ctx, o.id, bucketPath)
+	})
+	return result
+}
+
 var commandHelp = []fs.CommandHelp{
 	lifecycleHelp,
 	cleanupHelp,
 	cleanupHiddenHelp,
+	getInfoHelp,
+	bucketInfoHelp,
+	inventoryHelp,
+	selfTestHelp,
 }
 
 // Command the backend to run a named command
@@ -2401,6 +3286,14 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 		return f.cleanupCommand(ctx, name, arg, opt)
 	case "cleanup-hidden":
 		return f.cleanupHiddenCommand(ctx, name, arg, opt)
+	case "getinfo":
+		return f.getInfoCommand(ctx, name, arg, opt)
+	case "bucketinfo":
+		return f.bucketInfoCommand(ctx, name, arg, opt)
+	case "inventory":
+		return f.inventoryCommand(ctx, name, arg, opt)
+	case "selftest":
+		return f.selfTestCommand(ctx, name, arg, opt)
 	default:
 		return nil, fs.ErrorCommandNotFound
 	}
@@ -2416,8 +3309,13 @@ var (
 	_ fs.ListRer         = &Fs{}
 	_ fs.PublicLinker    = &Fs{}
 	_ fs.OpenChunkWriter = &Fs{}
+	_ fs.OpenWriterAter  = &Fs{}
 	_ fs.Commander       = &Fs{}
+	_ fs.Reconnecter     = &Fs{}
 	_ fs.Object          = &Object{}
 	_ fs.MimeTyper       = &Object{}
 	_ fs.IDer            = &Object{}
+	_ fs.Metadataer      = &Object{}
+	_ fs.GetTierer       = &Object{}
+	_ fs.SetTierer       = &Object{}
 )
@@ -1,22 +1,40 @@
 package b2
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/rclone/rclone/backend/b2/api"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/fstest/fstests"
 	"github.com/rclone/rclone/lib/bucket"
+	"github.com/rclone/rclone/lib/pacer"
+	"github.com/rclone/rclone/lib/percent"
 	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/lib/rest"
 	"github.com/rclone/rclone/lib/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -184,6 +202,1466 @@ func TestParseTimeString(t *testing.T) {
 
 }
 
+func TestDecodeMetaDataLargeFileSHA1(t *testing.T) {
+	const sha1sum = "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"
+	for _, test := range []struct {
+		what string
+		sha1 string
+		info map[string]string
+		want string
+	}{
+		{"Small", sha1sum, nil, sha1sum},
+		{"SmallUnverified", "unverified:" + strings.ToUpper(sha1sum), nil, sha1sum},
+		{"Large", "none", map[string]string{sha1Key: sha1sum}, sha1sum},
+		{"LargeNoInfo", "none", nil, ""},
+		{"Empty", "", map[string]string{sha1Key: sha1sum}, sha1sum},
+	} {
+		o := Object{fs: &Fs{ci: fs.GetConfig(context.Background())}}
+		err := o.decodeMetaDataRaw("id", test.sha1, 11, api.Timestamp{}, test.info, "text/plain")
+		require.NoError(t, err, test.what)
+		assert.Equal(t, test.want, o.sha1, test.what)
+	}
+}
+
+func TestDecodeMetaDataServerModTime(t *testing.T) {
+	ctx := context.Background()
+	uploaded := fstest.Time("2011-12-25T12:59:59.123000000Z")
+	modified := fstest.Time("2001-02-03T04:05:06.499000000Z")
+	info := map[string]string{timeKey: timeString(modified)}
+	for _, useServerModTime := range []bool{false, true} {
+		ctx, ci := fs.AddConfig(ctx)
+		ci.UseServerModTime = useServerModTime
+		o := Object{fs: &Fs{ci: ci}}
+		err := o.decodeMetaDataRaw("id", "none", 11, api.Timestamp(uploaded), info, "text/plain")
+		require.NoError(t, err)
+		want := modified
+		if useServerModTime {
+			want = uploaded
+		}
+		assert.Equal(t, want, o.ModTime(ctx), fmt.Sprintf("UseServerModTime=%v", useServerModTime))
+	}
+}
+
+func TestDecodeMetaDataArchiveTime(t *testing.T) {
+	archived := fstest.Time("1999-12-31T23:59:59.123000000Z")
+	info := map[string]string{
+		timeKey:        timeString(archived),
+		"archive_date": timeString(archived),
+	}
+	for _, test := range []struct {
+		key  string
+		info map[string]string
+		want string
+	}{
+		{"", info, ""},
+		{"archive_date", info, "1999-12-31T23:59:59.123Z"},
+		{"archive_date", map[string]string{"archive_date": "potato"}, ""},
+		{"other", info, ""},
+	} {
+		o := Object{fs: &Fs{ci: fs.GetConfig(context.Background()), opt: Options{ArchiveInfoKey: test.key}}}
+		err := o.decodeMetaDataRaw("id", "none", 11, api.Timestamp{}, test.info, "text/plain")
+		require.NoError(t, err)
+		assert.Equal(t, test.want, o.meta["archive-time"], test.key)
+	}
+}
+
+func TestCheckArchiveInfoKey(t *testing.T) {
+	for _, test := range []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"archive_date", "archive_date", false},
+		{"Archive-Date.v1", "archive-date.v1", false},
+		{strings.Repeat("a", 50), strings.Repeat("a", 50), false},
+		{strings.Repeat("a", 51), "", true},
+		{"B2-archive", "", true},
+		{timeKey, "", true},
+		{sha1Key, "", true},
+		{crc32cKey, "", true},
+		{"archive date", "", true},
+		{"archive/date", "", true},
+	} {
+		got, err := checkArchiveInfoKey(test.key)
+		if test.wantErr {
+			assert.Error(t, err, test.key)
+		} else {
+			require.NoError(t, err, test.key)
+			assert.Equal(t, test.want, got, test.key)
+		}
+	}
+}
+
+func TestCheckFileName(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		wantErr string
+	}{
+		{"file.txt", ""},
+		{"dir/sub dir/file with spaces.txt", ""},
+		{"back\\slash", ""},
+		{"unicode/自由/𐐀", ""},
+		{strings.Repeat("a", 250) + "/" + strings.Repeat("b", 250), ""},
+		{strings.Repeat("a/", 511) + "a", ""},
+		{"", "is empty"},
+		{strings.Repeat("a/", 512) + "a", "is longer than 1024 bytes"},
+		{strings.Repeat("a", 251), "has a segment longer than 250 bytes"},
+		{"invalid\xff", "is not valid UTF-8"},
+		{"/file.txt", "starts with /"},
+		{"dir/", "ends with /"},
+		{"dir//file.txt", "contains //"},
+		{"tab\tfile", "contains control character 0x9"},
+		{"del\x7ffile", "contains control character 0x7f"},
+	} {
+		err := checkFileName(test.name)
+		if test.wantErr == "" {
+			assert.NoError(t, err, test.name)
+			continue
+		}
+		require.Error(t, err, test.name)
+		assert.ErrorIs(t, err, fs.ErrorFileNameInvalid, test.name)
+		assert.True(t, fserrors.IsNoRetryError(err), test.name)
+		assert.Contains(t, err.Error(), test.wantErr, test.name)
+	}
+}
+
+func TestCheckBucketName(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		wantErr string
+	}{
+		{"bucket", ""},
+		{"My-Bucket-123", ""},
+		{strings.Repeat("b", 63), ""},
+		{"short", "must be between 6 and 63 characters long"},
+		{strings.Repeat("b", 64), "must be between 6 and 63 characters long"},
+		{"b2-bucket", `must not start with "b2-"`},
+		{"B2-bucket", `must not start with "b2-"`},
+		{"my_bucket", `may only contain letters, digits and "-"`},
+		{"my.bucket", `may only contain letters, digits and "-"`},
+	} {
+		err := checkBucketName(test.name)
+		if test.wantErr == "" {
+			assert.NoError(t, err, test.name)
+			continue
+		}
+		require.Error(t, err, test.name)
+		assert.ErrorIs(t, err, fs.ErrorFileNameInvalid, test.name)
+		assert.Contains(t, err.Error(), test.wantErr, test.name)
+	}
+}
+
+func TestUpdateInvalidFileName(t *testing.T) {
+	ctx := context.Background()
+	f := &Fs{
+		root:       "bucket",
+		rootBucket: "bucket",
+	}
+	o := &Object{fs: f, remote: strings.Repeat("a", 251)}
+	src := object.NewStaticObjectInfo(o.remote, time.Now(), 5, true, nil, nil)
+	err := o.Update(ctx, strings.NewReader("hello"), src)
+	assert.ErrorIs(t, err, fs.ErrorFileNameInvalid)
+}
+
+func TestItemToDirEntryVersions(t *testing.T) {
+	ctx := context.Background()
+	f := &Fs{ci: fs.GetConfig(ctx)}
+	f.opt.Versions = true
+	t0 := api.Timestamp(fstest.Time("2001-02-03T04:05:06.000000000Z"))
+	t1 := api.Timestamp(fstest.Time("2001-02-03T04:05:07.000000000Z"))
+	t2 := api.Timestamp(fstest.Time("2001-02-03T04:05:08.000000000Z"))
+	// newest version first as returned by b2_list_file_versions
+	files := []api.File{
+		{ID: "3", Name: "hidden.txt", Action: "hide", UploadTimestamp: t2},
+		{ID: "2", Name: "hidden.txt", Action: "upload", UploadTimestamp: t1, Size: 2},
+		{ID: "1", Name: "file.txt", Action: "upload", UploadTimestamp: t1, Size: 1},
+		{ID: "0", Name: "file.txt", Action: "upload", UploadTimestamp: t0, Size: 0},
+	}
+	last := ""
+	var entries fs.DirEntries
+	for i := range files {
+		entry, err := f.itemToDirEntry(ctx, files[i].Name, &files[i], false, &last)
+		require.NoError(t, err)
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	require.Len(t, entries, 3)
+	assert.Equal(t, t1.AddVersion("hidden.txt"), entries[0].Remote())
+	assert.Equal(t, "file.txt", entries[1].Remote())
+	assert.Equal(t, t0.AddVersion("file.txt"), entries[2].Remote())
+	for _, entry := range entries {
+		o := entry.(*Object)
+		assert.False(t, o.isHideMarker(), o.Remote())
+		metadata, err := o.Metadata(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "upload", metadata["action"], o.Remote())
+	}
+
+	// Check the action isn't returned without --b2-versions
+	f.opt.Versions = false
+	metadata, err := entries[0].(*Object).Metadata(ctx)
+	require.NoError(t, err)
+	_, found := metadata["action"]
+	assert.False(t, found)
+}
+
+// newTestFs makes an Fs which sends its API calls to a test server
+// running handler. The server is closed when the test finishes.
+func newTestFs(t testing.TB, handler http.HandlerFunc) (*Fs, *httptest.Server) {
+	ctx := context.Background()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	f := &Fs{
+		ci:          fs.GetConfig(ctx),
+		srv:         rest.NewClient(http.DefaultClient).SetRoot(server.URL).SetErrorHandler(errorHandler),
+		pacer:       fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant))),
+		cache:       bucket.NewCache(),
+		_bucketID:   make(map[string]string),
+		_bucketType: make(map[string]string),
+		uploads:     make(map[string][]*api.GetUploadURLResponse),
+		uploadToken: pacer.NewTokenDispenser(1),
+	}
+	return f, server
+}
+
+func TestGetInfoCommand(t *testing.T) {
+	ctx := context.Background()
+	const fileID = "4_z27c88f1d182b150646ff0b16_f1004ba650fe24e6b_d20180809_m012345_c002_v0001095_t0047"
+	var (
+		mu                    sync.Mutex
+		method, urlPath, body string
+	)
+	f, _ := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		method, urlPath, body = r.Method, r.URL.Path, string(data)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+	"accountId": "accountID",
+	"action": "hide",
+	"bucketId": "bucketID",
+	"contentLength": 12,
+	"contentSha1": "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+	"contentType": "text/plain",
+	"fileId": "` + fileID + `",
+	"fileInfo": {"src_last_modified_millis": "1533776733000"},
+	"fileName": "path/to/file.txt",
+	"uploadTimestamp": 1533776789000
+}`))
+	})
+
+	_, err := f.Command(ctx, "getinfo", nil, nil)
+	assert.Error(t, err)
+
+	out, err := f.Command(ctx, "getinfo", []string{fileID}, nil)
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, "POST", method)
+	assert.Equal(t, "/b2_get_file_info", urlPath)
+	assert.JSONEq(t, `{"fileId":"`+fileID+`"}`, body)
+	mu.Unlock()
+	info, ok := out.(*api.FileInfo)
+	require.True(t, ok)
+	assert.Equal(t, &api.FileInfo{
+		ID:              fileID,
+		Name:            "path/to/file.txt",
+		Action:          "hide",
+		AccountID:       "accountID",
+		BucketID:        "bucketID",
+		Size:            12,
+		UploadTimestamp: api.Timestamp(time.UnixMilli(1533776789000).UTC()),
+		SHA1:            "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+		ContentType:     "text/plain",
+		Info:            map[string]string{"src_last_modified_millis": "1533776733000"},
+	}, info)
+}
+
+func TestReconnect(t *testing.T) {
+	ctx := context.Background()
+	var (
+		server     *httptest.Server
+		mu         sync.Mutex
+		token      = "token1"
+		user, pass string
+		basicAuth  bool
+		authTokens []string
+		unexpected []string
+	)
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2api/v1/b2_authorize_account":
+			user, pass, basicAuth = r.BasicAuth()
+			_, _ = fmt.Fprintf(w, `{"accountId":"accountID","apiUrl":%q,"authorizationToken":%q}`, server.URL, token)
+		case "/b2api/v1/b2_get_file_info":
+			authTokens = append(authTokens, r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"fileId":"id"}`))
+		default:
+			unexpected = append(unexpected, r.URL.Path)
+		}
+	})
+	f.opt.Endpoint = server.URL
+	f.opt.Account = "account"
+	f.opt.Key = "key"
+	require.NoError(t, f.authorizeAccount(ctx))
+	assert.Equal(t, "token1", f.info.AuthorizationToken)
+	mu.Lock()
+	assert.True(t, basicAuth)
+	assert.Equal(t, "account", user)
+	assert.Equal(t, "key", pass)
+	mu.Unlock()
+	_, err := f.getFileInfo(ctx, "id")
+	require.NoError(t, err)
+
+	f.returnUploadURL(&api.GetUploadURLResponse{BucketID: "bucket1", UploadURL: "url1", AuthorizationToken: "token1"})
+	f.returnUploadURL(&api.GetUploadURLResponse{BucketID: "bucket2", UploadURL: "url2", AuthorizationToken: "token1"})
+	assert.Len(t, f.uploads, 2)
+
+	mu.Lock()
+	token = "token2"
+	mu.Unlock()
+	require.NoError(t, f.Reconnect(ctx))
+	assert.Equal(t, "token2", f.info.AuthorizationToken)
+	assert.Len(t, f.uploads, 0)
+
+	// Check the new token is used for API calls
+	_, err = f.getFileInfo(ctx, "id")
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, []string{"token1", "token2"}, authTokens)
+	assert.Empty(t, unexpected)
+	mu.Unlock()
+}
+
+func TestUpdateSkipUnchanged(t *testing.T) {
+	ctx := context.Background()
+	const (
+		contents = "hello world"
+		sha1sum  = "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"
+	)
+	t0 := fstest.Time("2001-02-03T04:05:06.000000000Z")
+	t1 := t0.Add(time.Hour)
+	var (
+		requests atomic.Int32
+		mu       sync.Mutex
+		copyReq  api.CopyFileRequest
+	)
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.Method == "HEAD" && r.URL.Path == "/b2api/v1/b2_download_file_by_id":
+			w.Header().Set(idHeader, "id")
+			w.Header().Set(nameHeader, "file.txt")
+			w.Header().Set(sha1Header, sha1sum)
+			w.Header().Set(headerPrefix+timeKey, timeString(t0))
+			w.Header().Set("Content-Length", strconv.Itoa(len(contents)))
+		case r.Method == "POST" && r.URL.Path == "/b2_copy_file":
+			mu.Lock()
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&copyReq))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"fileId":"id2","fileName":"file.txt","action":"upload","contentLength":%d,"contentSha1":%q,"fileInfo":{%q:%q}}`,
+				len(contents), sha1sum, timeKey, timeString(t1))
+		default:
+			t.Errorf("unexpected request %s %q", r.Method, r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.info.DownloadURL = server.URL
+	f.opt.CopyCutoff = largeFileCopyCutoff
+	f.opt.SkipUnchanged = true
+	f.setRoot("bucket")
+	f.setBucketID("bucket", "bucketID")
+	o := &Object{
+		fs:      f,
+		remote:  "file.txt",
+		id:      "id",
+		size:    int64(len(contents)),
+		sha1:    sha1sum,
+		modTime: t0,
+	}
+	newSrc := func(size int64, sha1sum string) fs.ObjectInfo {
+		var hashes map[hash.Type]string
+		if sha1sum != "" {
+			hashes = map[hash.Type]string{hash.SHA1: sha1sum}
+		}
+		return object.NewStaticObjectInfo("file.txt", t0, size, true, hashes, nil)
+	}
+
+	// Identical content shouldn't be uploaded
+	in := strings.NewReader(contents)
+	err := o.Update(ctx, in, newSrc(int64(len(contents)), sha1sum))
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), requests.Load())
+	assert.Equal(t, len(contents), in.Len(), "input should not have been read")
+	assert.Equal(t, "id", o.id)
+
+	// Identical content with a different modification time shouldn't
+	// be uploaded but should have its modification time set
+	src := object.NewStaticObjectInfo("file.txt", t1, int64(len(contents)), true, map[hash.Type]string{hash.SHA1: sha1sum}, nil)
+	in = strings.NewReader(contents)
+	err = o.Update(ctx, in, src)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "should read the metadata then copy")
+	assert.Equal(t, len(contents), in.Len(), "input should not have been read")
+	mu.Lock()
+	assert.Equal(t, "id", copyReq.SourceID)
+	assert.Equal(t, "REPLACE", copyReq.MetadataDirective)
+	assert.Equal(t, timeString(t1), copyReq.Info[timeKey])
+	mu.Unlock()
+	assert.Equal(t, "id2", o.id)
+	assert.Equal(t, t1, o.modTime)
+	requests.Store(0)
+
+	// Anything else should be uploaded
+	for _, test := range []struct {
+		name string
+		o    *Object
+		src  fs.ObjectInfo
+	}{
+		{"Size", o, newSrc(int64(len(contents))+1, sha1sum)},
+		{"SHA1", o, newSrc(int64(len(contents)), "0000000000000000000000000000000000000000")},
+		{"NoSHA1", o, newSrc(int64(len(contents)), "")},
+		{"UnknownSize", o, newSrc(-1, sha1sum)},
+		{"NewObject", &Object{fs: f, remote: "file.txt"}, newSrc(int64(len(contents)), sha1sum)},
+	} {
+		unchanged, err := test.o.updateUnchanged(ctx, test.src)
+		require.NoError(t, err, test.name)
+		assert.False(t, unchanged, test.name)
+	}
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+func TestNewFsAPIStyle(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		apiStyle string
+		wantErr  string
+	}{
+		{apiStyle: "s3", wantErr: errS3NotImplemented.Error()},
+		{apiStyle: "potato", wantErr: `unknown api_style "potato"`},
+	} {
+		_, err := NewFs(ctx, "b2", "bucket", configmap.Simple{
+			"account":   "account",
+			"key":       "key",
+			"api_style": test.apiStyle,
+		})
+		require.Error(t, err, test.apiStyle)
+		assert.Contains(t, err.Error(), test.wantErr, test.apiStyle)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	ctx := context.Background()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/b2api/v1/b2_authorize_account" {
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(&api.AuthorizeAccountResponse{
+			APIURL:             server.URL,
+			AuthorizationToken: "token",
+			DownloadURL:        server.URL,
+		}))
+	}))
+	defer server.Close()
+	fsInfo, err := fs.Find("b2")
+	require.NoError(t, err)
+	m := fs.ConfigMap(fsInfo.Prefix, fsInfo.Options, "", configmap.Simple{
+		"account":  "account",
+		"key":      "key",
+		"endpoint": server.URL,
+	})
+	f, err := NewFs(ctx, "b2", "bucket", m)
+	require.NoError(t, err)
+	features := f.Features()
+
+	// Static features
+	assert.True(t, features.ReadMimeType, "ReadMimeType")
+	assert.True(t, features.WriteMimeType, "WriteMimeType")
+	assert.True(t, features.ReadMetadata, "ReadMetadata")
+	assert.True(t, features.BucketBased, "BucketBased")
+	assert.True(t, features.BucketBasedRootOK, "BucketBasedRootOK")
+	assert.True(t, features.ChunkWriterDoesntSeek, "ChunkWriterDoesntSeek")
+	assert.True(t, features.GetTier, "GetTier")
+	assert.True(t, features.SetTier, "SetTier")
+
+	// Implemented optional interfaces
+	assert.NotNil(t, features.Purge, "Purge")
+	assert.NotNil(t, features.Copy, "Copy")
+	assert.NotNil(t, features.PutStream, "PutStream")
+	assert.NotNil(t, features.CleanUp, "CleanUp")
+	assert.NotNil(t, features.ListR, "ListR")
+	assert.NotNil(t, features.PublicLink, "PublicLink")
+	assert.NotNil(t, features.OpenChunkWriter, "OpenChunkWriter")
+	assert.NotNil(t, features.OpenWriterAt, "OpenWriterAt")
+	assert.NotNil(t, features.Command, "Command")
+	assert.NotNil(t, features.Reconnect, "Reconnect")
+
+	// Not implemented by b2
+	assert.Nil(t, features.Move, "Move")
+	assert.Nil(t, features.DirMove, "DirMove")
+	assert.Nil(t, features.About, "About")
+	assert.False(t, features.CaseInsensitive, "CaseInsensitive")
+}
+
+// B2 has a single storage tier so reports it and refuses to set any other
+func TestTier(t *testing.T) {
+	o := &Object{fs: &Fs{}, remote: "file"}
+	assert.Equal(t, "standard", o.GetTier())
+	require.NoError(t, o.SetTier("standard"))
+	assert.ErrorContains(t, o.SetTier("archive"), `tier "archive" not supported`)
+	assert.Equal(t, "standard", o.GetTier())
+}
+
+// Check the idle timeout and keepalive options are used for the transport
+func TestCustomizeTransport(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{
+		IdleTimeout: fs.Duration(25 * time.Second),
+		KeepAlive:   fs.Duration(10 * time.Second),
+	}
+	assert.Equal(t, 10*time.Second, newDialer(ctx, opt).KeepAlive)
+
+	transport := &http.Transport{}
+	customizeTransport(ctx, opt)(transport)
+	assert.Equal(t, 25*time.Second, transport.IdleConnTimeout)
+
+	// Check API calls can be made with the customized client
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/b2_get_file_info", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"fileId":"id","fileName":"file.txt"}`))
+	})
+	srv, err := newClient(ctx, opt)
+	require.NoError(t, err)
+	f.srv = srv.SetRoot(server.URL)
+	info, err := f.getFileInfo(ctx, "id")
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", info.Name)
+}
+
+func TestAutoCreateBucket(t *testing.T) {
+	ctx := context.Background()
+	const contents = "hello world"
+	for _, autoCreate := range []bool{true, false} {
+		t.Run(fmt.Sprintf("AutoCreateBucket=%v", autoCreate), func(t *testing.T) {
+			var (
+				server     *httptest.Server
+				mu         sync.Mutex
+				created    bool
+				uploaded   []string
+				unexpected []string
+			)
+			f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/b2_list_buckets":
+					if created {
+						_, _ = w.Write([]byte(`{"buckets":[{"bucketId":"bucketID","bucketName":"bucket","bucketType":"allPrivate"}]}`))
+					} else {
+						_, _ = w.Write([]byte(`{"buckets":[]}`))
+					}
+				case "/b2_create_bucket":
+					created = true
+					_, _ = w.Write([]byte(`{"bucketId":"bucketID","bucketName":"bucket","bucketType":"allPrivate"}`))
+				case "/b2_get_upload_url":
+					_, _ = fmt.Fprintf(w, `{"bucketId":"bucketID","uploadUrl":%q,"authorizationToken":"token"}`, server.URL+"/upload")
+				case "/upload":
+					uploaded = append(uploaded, r.Header.Get("X-Bz-File-Name"))
+					_, _ = fmt.Fprintf(w, `{"fileId":"id","fileName":"file.txt","action":"upload","contentLength":%d}`, len(contents))
+				default:
+					unexpected = append(unexpected, r.URL.Path)
+				}
+			})
+			f.setRoot("bucket")
+			f.opt.AutoCreateBucket = autoCreate
+			f.opt.UploadCutoff = defaultUploadCutoff
+			f.opt.ChunkSize = defaultChunkSize
+
+			src := object.NewStaticObjectInfo("file.txt", fstest.Time("2001-02-03T04:05:06.000000000Z"), int64(len(contents)), true, map[hash.Type]string{hash.SHA1: sha1Sum(t, contents)}, nil)
+			o, err := f.Put(ctx, strings.NewReader(contents), src)
+			mu.Lock()
+			defer mu.Unlock()
+			if autoCreate {
+				require.NoError(t, err)
+				assert.True(t, created)
+				assert.Equal(t, []string{"file.txt"}, uploaded)
+				assert.Equal(t, "id", o.(*Object).id)
+			} else {
+				assert.Equal(t, fs.ErrorDirNotFound, err)
+				assert.False(t, created, "bucket should not be created")
+				assert.Empty(t, uploaded)
+			}
+			assert.Empty(t, unexpected)
+		})
+	}
+}
+
+// Check that listing a directory reads the metadata of all the
+// objects in it, so reading it doesn't need an API call per object
+func TestListReadsMetadata(t *testing.T) {
+	ctx := context.Background()
+	const N = 25
+	modTime := fstest.Time("2001-02-03T04:05:06.000000000Z")
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	f, _ := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_list_file_names":
+			var response api.ListFileNamesResponse
+			for i := 0; i < N; i++ {
+				response.Files = append(response.Files, api.File{
+					ID:          fmt.Sprintf("id%d", i),
+					Name:        fmt.Sprintf("dir/file%02d.txt", i),
+					Action:      "upload",
+					Size:        int64(i),
+					SHA1:        "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+					ContentType: "text/plain",
+					Info:        map[string]string{timeKey: timeString(modTime)},
+				})
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.setBucketID("bucket", "bucketID")
+	f.setRoot("bucket/dir")
+
+	entries, err := f.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, entries, N)
+	for i, entry := range entries {
+		o := entry.(*Object)
+		assert.Equal(t, fmt.Sprintf("file%02d.txt", i), o.Remote())
+		assert.Equal(t, int64(i), o.Size())
+		assert.Equal(t, modTime, o.ModTime(ctx))
+		sha1sum, err := o.Hash(ctx, hash.SHA1)
+		require.NoError(t, err)
+		assert.Equal(t, "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed", sha1sum)
+		assert.Equal(t, "text/plain", o.MimeType(ctx))
+		assert.Equal(t, fmt.Sprintf("id%d", i), o.ID())
+	}
+	mu.Lock()
+	assert.Equal(t, map[string]int{"/b2_list_file_names": 1}, requests)
+	mu.Unlock()
+}
+
+// Check that a directory listing asks B2 for the common prefixes with
+// a delimiter rather than listing every file under the directory
+func TestListDelimiter(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu         sync.Mutex
+		delimiters []string
+	)
+	f, _ := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_list_file_names":
+			var request api.ListFileNamesRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "dir/", request.Prefix)
+			mu.Lock()
+			delimiters = append(delimiters, request.Delimiter)
+			mu.Unlock()
+			response := api.ListFileNamesResponse{
+				Files: []api.File{
+					{ID: "id1", Name: "dir/file.txt", Action: "upload", Size: 1},
+					{Name: "dir/sub/", Action: "folder"},
+				},
+			}
+			if request.Delimiter == "" {
+				response.Files = append(response.Files, api.File{ID: "id2", Name: "dir/sub/file.txt", Action: "upload", Size: 2})
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.setBucketID("bucket", "bucketID")
+	f.setRoot("bucket/dir")
+
+	entries, err := f.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "file.txt", entries[0].Remote())
+	assert.IsType(t, &Object{}, entries[0])
+	assert.Equal(t, "sub", entries[1].Remote())
+	assert.Implements(t, (*fs.Directory)(nil), entries[1])
+	mu.Lock()
+	assert.Equal(t, []string{"/"}, delimiters)
+	mu.Unlock()
+}
+
+// Check that Open passes Range and raw HTTP header options to the download
+func TestOpenOptionHeaders(t *testing.T) {
+	ctx := context.Background()
+	const content = "0123456789"
+	var (
+		mu        sync.Mutex
+		gotHeader http.Header
+	)
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/b2api/v1/b2_download_file_by_id", r.URL.Path)
+		assert.Equal(t, "fileID", r.URL.Query().Get("fileId"))
+		mu.Lock()
+		gotHeader = r.Header.Clone()
+		mu.Unlock()
+		w.Header().Set("Content-Range", "bytes 2-4/10")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, content[2:5])
+	})
+	f.info.DownloadURL = server.URL
+	o := &Object{
+		fs:     f,
+		remote: "file.txt",
+		id:     "fileID",
+		size:   int64(len(content)),
+	}
+
+	in, err := o.Open(ctx,
+		&fs.RangeOption{Start: 2, End: 4},
+		&fs.HTTPOption{Key: "If-Modified-Since", Value: "Sat, 03 Feb 2001 04:05:06 GMT"},
+		&fs.HTTPOption{Key: "X-Custom-Header", Value: "potato"},
+	)
+	require.NoError(t, err)
+	data, err := io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, content[2:5], string(data))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotNil(t, gotHeader)
+	assert.Equal(t, "bytes=2-4", gotHeader.Get("Range"))
+	assert.Equal(t, "Sat, 03 Feb 2001 04:05:06 GMT", gotHeader.Get("If-Modified-Since"))
+	assert.Equal(t, "potato", gotHeader.Get("X-Custom-Header"))
+}
+
+// Check the bucketinfo command returns the right bucket
+func TestBucketInfoCommand(t *testing.T) {
+	ctx := context.Background()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/b2_list_buckets", r.URL.Path)
+		var request api.ListBucketsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "accountID", request.AccountID)
+		w.Header().Set("Content-Type", "application/json")
+		response := api.ListBucketsResponse{
+			Buckets: []api.Bucket{{
+				ID:        "otherID",
+				AccountID: "accountID",
+				Name:      "other",
+				Type:      "allPublic",
+			}, {
+				ID:        "bucketID",
+				AccountID: "accountID",
+				Name:      "bucket",
+				Type:      "allPrivate",
+			}},
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(&response))
+	}
+
+	newFs := func(root string) *Fs {
+		f, _ := newTestFs(t, handler)
+		f.info.AccountID = "accountID"
+		f.setRoot(root)
+		return f
+	}
+
+	f := newFs("bucket/path")
+	out, err := f.Command(ctx, "bucketinfo", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &api.Bucket{
+		ID:        "bucketID",
+		AccountID: "accountID",
+		Name:      "bucket",
+		Type:      "allPrivate",
+	}, out)
+	bucketID, err := f.getBucketID(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "bucketID", bucketID)
+
+	_, err = newFs("missing").Command(ctx, "bucketinfo", nil, nil)
+	assert.True(t, errors.Is(err, fs.ErrorDirNotFound), "got %v", err)
+
+	_, err = newFs("").Command(ctx, "bucketinfo", nil, nil)
+	assert.Error(t, err)
+}
+
+// Check that nothing is hidden or deleted with --dry-run
+func TestDryRunDelete(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	ci.DryRun = true
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	f, _ := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_list_file_versions":
+			response := api.ListFileNamesResponse{
+				Files: []api.File{{
+					ID:     "hideID",
+					Name:   "hidden.txt",
+					Action: "hide",
+				}, {
+					ID:     "oldID",
+					Name:   "hidden.txt",
+					Action: "upload",
+				}},
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.ci = ci
+	f.setBucketID("bucket", "bucketID")
+	f.setRoot("bucket")
+	o := &Object{
+		fs:     f,
+		remote: "file.txt",
+		id:     "fileID",
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	require.NoError(t, o.Remove(ctx))
+	f.opt.HardDelete = true
+	require.NoError(t, o.Remove(ctx))
+	require.NoError(t, f.CleanUp(ctx))
+
+	mu.Lock()
+	assert.Equal(t, map[string]int{"/b2_list_file_versions": 1}, requests)
+	mu.Unlock()
+	logs := buf.String()
+	assert.Contains(t, logs, "file.txt: Not hiding as --dry-run is set")
+	assert.Contains(t, logs, `file.txt: Not deleting version (id "fileID") as --dry-run is set`)
+	assert.Contains(t, logs, `hidden.txt: Not deleting version (id "hideID") as --dry-run is set`)
+	assert.Contains(t, logs, `hidden.txt: Not deleting version (id "oldID") as --dry-run is set`)
+}
+
+// Check --b2-keep-versions deletes the oldest versions on upload
+func TestKeepVersions(t *testing.T) {
+	ctx := context.Background()
+	const keep = 2
+	var mu sync.Mutex
+	var versions []api.File // newest first
+	deleted := 0
+	var server *httptest.Server
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_get_upload_url":
+			response := api.GetUploadURLResponse{
+				BucketID:           "bucketID",
+				UploadURL:          server.URL + "/upload",
+				AuthorizationToken: "token",
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		case "/upload":
+			_, err := io.Copy(io.Discard, r.Body)
+			assert.NoError(t, err)
+			file := api.File{
+				ID:              fmt.Sprintf("id%d", len(versions)+deleted),
+				Name:            "file.txt",
+				Action:          "upload",
+				Size:            r.ContentLength,
+				UploadTimestamp: api.Timestamp(time.Unix(int64(len(versions)+deleted), 0)),
+			}
+			versions = append([]api.File{file}, versions...)
+			response := api.FileInfo{
+				ID:              file.ID,
+				Name:            file.Name,
+				Action:          file.Action,
+				Size:            file.Size,
+				UploadTimestamp: file.UploadTimestamp,
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		case "/b2_list_file_versions":
+			var request api.ListFileNamesRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "file.txt", request.Prefix)
+			response := api.ListFileNamesResponse{
+				Files: append([]api.File{}, versions...),
+			}
+			response.Files = append(response.Files, api.File{ID: "otherID", Name: "file.txt.bak", Action: "upload"})
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		case "/b2_delete_file_version":
+			var request api.DeleteFileRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "file.txt", request.Name)
+			for i := range versions {
+				if versions[i].ID == request.ID {
+					versions = append(versions[:i], versions[i+1:]...)
+					deleted++
+					break
+				}
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&api.File{ID: request.ID, Name: request.Name}))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.setBucketID("bucket", "bucketID")
+	f.opt.KeepVersions = keep
+	f.opt.UploadCutoff = defaultUploadCutoff
+	f.setRoot("bucket")
+
+	const contents = "hello world"
+	src := object.NewStaticObjectInfo("file.txt", fstest.Time("2001-02-03T04:05:06.000000000Z"), int64(len(contents)), true, nil, nil)
+	o := &Object{
+		fs:     f,
+		remote: "file.txt",
+	}
+	for i := 0; i < keep+2; i++ {
+		require.NoError(t, o.Update(ctx, strings.NewReader(contents), src))
+		assert.Equal(t, fmt.Sprintf("id%d", i), o.id)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, deleted)
+	var ids []string
+	for _, version := range versions {
+		ids = append(ids, version.ID)
+	}
+	assert.Equal(t, []string{"id3", "id2"}, ids)
+}
+
+// Check --b2-crc32c stores the crc32c with the upload and reads it back
+func TestUpdateCRC32C(t *testing.T) {
+	ctx := context.Background()
+	const contents = "hello world"
+	var server *httptest.Server
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_get_upload_url":
+			response := api.GetUploadURLResponse{
+				BucketID:           "bucketID",
+				UploadURL:          server.URL + "/upload",
+				AuthorizationToken: "token",
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		case "/upload":
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, contents, string(body))
+			assert.Equal(t, "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed", r.Header.Get(sha1Header))
+			crc, err := percent.Decode(r.Header.Get(crc32cHeader))
+			assert.NoError(t, err)
+			response := api.FileInfo{
+				ID:     "id",
+				Name:   "file.txt",
+				Action: "upload",
+				Size:   int64(len(body)),
+				SHA1:   r.Header.Get(sha1Header),
+				Info: map[string]string{
+					timeKey:   r.Header.Get(timeHeader),
+					crc32cKey: crc,
+				},
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.setBucketID("bucket", "bucketID")
+	f.opt.CRC32C = true
+	f.opt.ChunkSize = defaultChunkSize
+	f.opt.UploadCutoff = defaultUploadCutoff
+	f.setRoot("bucket")
+
+	for _, size := range []int64{int64(len(contents)), -1} {
+		src := object.NewStaticObjectInfo("file.txt", fstest.Time("2001-02-03T04:05:06.000000000Z"), size, true, nil, nil)
+		o := &Object{
+			fs:     f,
+			remote: "file.txt",
+		}
+		require.NoError(t, o.Update(ctx, strings.NewReader(contents), src))
+		metadata, err := o.Metadata(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "yZRlqg==", metadata["crc32c"], "size %d", size)
+		assert.Equal(t, "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed", o.sha1)
+	}
+}
+
+// Check the inventory command lists the objects in all the buckets
+func TestInventoryCommand(t *testing.T) {
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.Transfers = 2
+	modTime := fstest.Time("2001-02-03T04:05:06.000000000Z")
+	files := map[string][]api.File{
+		"bucketID1": {{
+			ID:     "id1",
+			Name:   "dir/",
+			Action: "folder",
+		}, {
+			ID:          "id2",
+			Name:        "dir/file1.txt",
+			Action:      "upload",
+			Size:        1,
+			SHA1:        "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+			ContentType: "text/plain",
+			Info:        map[string]string{timeKey: timeString(modTime)},
+		}},
+		"bucketID2": {{
+			ID:          "id3",
+			Name:        "file2.txt",
+			Action:      "upload",
+			Size:        2,
+			ContentType: "text/plain",
+			Info:        map[string]string{timeKey: timeString(modTime)},
+		}},
+	}
+	var mu sync.Mutex
+	requests := map[string]int{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_list_buckets":
+			var request api.ListBucketsRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			response := api.ListBucketsResponse{
+				Buckets: []api.Bucket{{
+					ID:   "bucketID1",
+					Name: "bucket1",
+				}, {
+					ID:   "bucketID2",
+					Name: "bucket2",
+				}},
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		case "/b2_list_file_names":
+			var request api.ListFileNamesRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "", request.Delimiter)
+			response := api.ListFileNamesResponse{
+				Files: files[request.BucketID],
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(&response))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	}
+
+	newFs := func(root string) *Fs {
+		f, _ := newTestFs(t, handler)
+		f.ci = ci
+		f.setRoot(root)
+		return f
+	}
+
+	item1 := inventoryItem{
+		Path:     "bucket1/dir/file1.txt",
+		Size:     1,
+		MimeType: "text/plain",
+		ModTime:  modTime,
+		ID:       "id2",
+		SHA1:     "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+	}
+	item2 := inventoryItem{
+		Path:     "bucket2/file2.txt",
+		Size:     2,
+		MimeType: "text/plain",
+		ModTime:  modTime,
+		ID:       "id3",
+	}
+
+	out, err := newFs("").Command(ctx, "inventory", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []inventoryItem{item1, item2}, out)
+	mu.Lock()
+	assert.Equal(t, map[string]int{"/b2_list_buckets": 1, "/b2_list_file_names": 2}, requests)
+	mu.Unlock()
+
+	// Only the bucket in the remote is listed
+	out, err = newFs("bucket2").Command(ctx, "inventory", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []inventoryItem{item2}, out)
+}
+
+// Check the selftest command runs through all the steps
+func TestSelfTestCommand(t *testing.T) {
+	for _, corrupt := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Corrupt=%v", corrupt), func(t *testing.T) {
+			ctx := context.Background()
+			var (
+				mu       sync.Mutex
+				requests = map[string]int{}
+				stored   []byte
+				sha1sum  string
+				name     string
+				server   *httptest.Server
+			)
+			f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests[r.URL.Path]++
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/b2api/v1/b2_authorize_account":
+					_, _ = fmt.Fprintf(w, `{"accountId":"accountID","apiUrl":%q,"downloadUrl":%q,"authorizationToken":"token"}`, server.URL, server.URL)
+				case "/b2api/v1/b2_list_buckets":
+					response := api.ListBucketsResponse{
+						Buckets: []api.Bucket{{ID: "bucketID", Name: "bucket"}},
+					}
+					assert.NoError(t, json.NewEncoder(w).Encode(&response))
+				case "/b2api/v1/b2_get_upload_url":
+					response := api.GetUploadURLResponse{
+						BucketID:           "bucketID",
+						UploadURL:          server.URL + "/upload",
+						AuthorizationToken: "token",
+					}
+					assert.NoError(t, json.NewEncoder(w).Encode(&response))
+				case "/upload":
+					var err error
+					stored, err = io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					sha1sum = r.Header.Get(sha1Header)
+					name = r.Header.Get(nameHeader)
+					assert.Equal(t, fmt.Sprintf("%x", sha1.Sum(stored)), sha1sum)
+					response := api.FileInfo{
+						ID:     "fileID",
+						Name:   name,
+						Action: "upload",
+						Size:   int64(len(stored)),
+						SHA1:   sha1sum,
+					}
+					assert.NoError(t, json.NewEncoder(w).Encode(&response))
+				case "/b2api/v1/b2_download_file_by_id":
+					assert.Equal(t, "fileID", r.URL.Query().Get("fileId"))
+					data := stored
+					if corrupt {
+						data = bytes.ToUpper(stored)
+					}
+					w.Header().Set("Content-Type", "text/plain")
+					w.Header().Set(idHeader, "fileID")
+					w.Header().Set(nameHeader, name)
+					w.Header().Set(sha1Header, sha1sum)
+					_, _ = w.Write(data)
+				case "/b2api/v1/b2_delete_file_version":
+					var request api.DeleteFileRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+					assert.Equal(t, "fileID", request.ID)
+					assert.Equal(t, name, request.Name)
+					assert.NoError(t, json.NewEncoder(w).Encode(&api.File{ID: request.ID, Name: request.Name}))
+				default:
+					t.Errorf("unexpected request %q", r.URL.Path)
+					http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+				}
+			})
+			f.opt.Endpoint = server.URL
+			f.opt.Account = "account"
+			f.opt.Key = "key"
+			f.opt.UploadCutoff = defaultUploadCutoff
+			f.setRoot("bucket/dir")
+
+			out, err := f.Command(ctx, "selftest", nil, nil)
+			require.NoError(t, err)
+			result := out.(*selfTestResult)
+			var steps []string
+			for _, step := range result.Steps {
+				steps = append(steps, step.Step)
+				if corrupt && step.Step == "download" {
+					assert.False(t, step.OK)
+					assert.Contains(t, step.Error, "SHA1")
+				} else {
+					assert.True(t, step.OK, step.Step)
+					assert.Equal(t, "", step.Error, step.Step)
+				}
+			}
+			assert.Equal(t, []string{"authorize", "bucket", "upload", "download", "delete"}, steps)
+			assert.Equal(t, !corrupt, result.OK)
+			mu.Lock()
+			defer mu.Unlock()
+			assert.True(t, strings.HasPrefix(name, "dir/.rclone-selftest-"), name)
+			assert.Equal(t, 1, requests["/b2api/v1/b2_delete_file_version"])
+		})
+	}
+
+	// Needs a bucket
+	f := &Fs{}
+	f.setRoot("")
+	_, err := f.Command(context.Background(), "selftest", nil, nil)
+	assert.EqualError(t, err, "need a bucket")
+}
+
+// Check a download can be resumed from an offset
+func TestOpenResume(t *testing.T) {
+	ctx := context.Background()
+	content := []byte(random.String(1000))
+	contentSHA1 := fmt.Sprintf("%x", sha1.Sum(content))
+	var mu sync.Mutex
+	serveSHA1 := contentSHA1
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/b2api/v1/b2_download_file_by_id", r.URL.Path)
+		assert.Equal(t, "fileID", r.URL.Query().Get("fileId"))
+		w.Header().Set(idHeader, "fileID")
+		w.Header().Set(nameHeader, "file")
+		mu.Lock()
+		w.Header().Set(sha1Header, serveSHA1)
+		mu.Unlock()
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	})
+	f.info.DownloadURL = server.URL
+	f.setRoot("bucket")
+	o := &Object{
+		fs:     f,
+		remote: "file",
+		id:     "fileID",
+		size:   int64(len(content)),
+		sha1:   contentSHA1,
+	}
+
+	// Read the first part then stop as if interrupted
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	partial := make([]byte, 300)
+	_, err = io.ReadFull(in, partial)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+
+	// Resume from where we got to
+	in, err = o.Open(ctx, &fs.SeekOption{Offset: int64(len(partial))})
+	require.NoError(t, err)
+	tail, err := io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, content, append(partial, tail...))
+
+	// The SHA1 isn't checked on partial reads
+	mu.Lock()
+	serveSHA1 = "0000000000000000000000000000000000000000"
+	mu.Unlock()
+	in, err = o.Open(ctx, &fs.RangeOption{Start: 900, End: -1})
+	require.NoError(t, err)
+	tail, err = io.ReadAll(in)
+	require.NoError(t, err)
+	assert.NoError(t, in.Close())
+	assert.Equal(t, content[900:], tail)
+
+	// But it is on full reads
+	in, err = o.Open(ctx)
+	require.NoError(t, err)
+	_, err = io.ReadAll(in)
+	require.NoError(t, err)
+	assert.ErrorContains(t, in.Close(), "SHA1 hashes differ")
+}
+
+// Check old versions are downloaded by ID even with --b2-download-url
+func TestOpenVersionByID(t *testing.T) {
+	ctx := context.Background()
+	versions := map[string][]byte{
+		"oldID": []byte("old version"),
+		"newID": []byte("new version"),
+	}
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		var content []byte
+		var id string
+		switch r.URL.Path {
+		case "/b2api/v1/b2_download_file_by_id":
+			id = r.URL.Query().Get("fileId")
+			content = versions[id]
+		case "/file/bucket/file.txt":
+			id = "newID"
+			content = versions[id]
+		}
+		if content == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set(idHeader, id)
+		w.Header().Set(nameHeader, "file.txt")
+		w.Header().Set(sha1Header, fmt.Sprintf("%x", sha1.Sum(content)))
+		_, _ = w.Write(content)
+	})
+	f.info.DownloadURL = server.URL
+	f.opt.DownloadURL = server.URL
+	f.opt.Versions = true
+	f.setRoot("bucket")
+	content := versions["oldID"]
+	contentSHA1 := fmt.Sprintf("%x", sha1.Sum(content))
+	o := &Object{
+		fs:     f,
+		remote: "file-v2024-01-02-030405-000.txt",
+		id:     "oldID",
+		size:   int64(len(content)),
+		sha1:   contentSHA1,
+	}
+
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	got, err := io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, content, got)
+	assert.Equal(t, "oldID", o.id)
+	assert.Equal(t, contentSHA1, o.sha1)
+}
+
+// Check the x-bz-info values returned on download are decoded
+func TestGetOrHeadDecodesInfo(t *testing.T) {
+	ctx := context.Background()
+	f, server := newTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerPrefix+"note", "hello%20world%2F%E8%87%AA%E7%94%B1")
+		w.Header().Set(headerPrefix+"bad", "100%")
+		w.Header().Set(headerPrefix+"plain", "a+b")
+	})
+	f.info.DownloadURL = server.URL
+	f.setRoot("bucket")
+	o := &Object{
+		fs:     f,
+		remote: "file",
+		id:     "fileID",
+	}
+	_, info, err := o.getOrHead(ctx, "HEAD", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world/自由", info.Info["note"])
+	assert.Equal(t, "100%", info.Info["bad"], "malformed values are passed through")
+	assert.Equal(t, "a+b", info.Info["plain"])
+}
+
+// Purge can't filter so should fall back to deleting files one by one
+func TestPurgeWithFilters(t *testing.T) {
+	ctx := context.Background()
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	require.NoError(t, fi.AddRule("+ *.jpg"))
+	require.NoError(t, fi.AddRule("- *"))
+	f := &Fs{}
+	assert.Equal(t, fs.ErrorCantPurge, f.Purge(filter.ReplaceConfig(ctx, fi), "bucket"))
+}
+
+// fakeChunkWriter records the chunks written to it
+type fakeChunkWriter struct {
+	mu      sync.Mutex
+	chunks  map[int]string
+	closed  bool
+	aborted bool
+}
+
+func (w *fakeChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chunks[chunkNumber] = string(data)
+	return int64(len(data)), nil
+}
+
+func (w *fakeChunkWriter) Close(ctx context.Context) error {
+	w.closed = true
+	return nil
+}
+
+func (w *fakeChunkWriter) Abort(ctx context.Context) error {
+	w.aborted = true
+	return nil
+}
+
+func TestChunkWriterAt(t *testing.T) {
+	ctx := context.Background()
+
+	cw := &fakeChunkWriter{chunks: map[int]string{}}
+	w := newChunkWriterAt(ctx, cw, "file", 4, 10)
+
+	// Writes may be out of order, unaligned and span chunks
+	n, err := w.WriteAt([]byte("ij"), 8)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	_, err = w.WriteAt([]byte("XX"), 4)
+	require.NoError(t, err)
+	n, err = w.WriteAt([]byte("cd"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[int]string{2: "ij"}, cw.chunks, "partial chunks aren't uploaded")
+
+	_, err = w.WriteAt([]byte("mnop"), 8)
+	assert.ErrorContains(t, err, "beyond end")
+	_, err = w.WriteAt([]byte("x"), -1)
+	assert.ErrorContains(t, err, "beyond end")
+	_, err = w.WriteAt([]byte("j"), 9)
+	assert.ErrorContains(t, err, "already written")
+
+	// Overlapping writes replace what was there
+	_, err = w.WriteAt([]byte("abcdefgh"), 0)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.True(t, cw.closed)
+	assert.False(t, cw.aborted)
+	assert.Equal(t, map[int]string{0: "abcd", 1: "efgh", 2: "ij"}, cw.chunks)
+	assert.Error(t, w.Close(), "double close")
+
+	// Missing data aborts the upload
+	cw = &fakeChunkWriter{chunks: map[int]string{}}
+	w = newChunkWriterAt(ctx, cw, "file", 4, 10)
+	_, err = w.WriteAt([]byte("efgh"), 4)
+	require.NoError(t, err)
+	_, err = w.WriteAt([]byte("abc"), 0)
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Close(), "2 of 3 chunks not written")
+	assert.False(t, cw.closed)
+	assert.True(t, cw.aborted)
+	assert.Equal(t, map[int]string{1: "efgh"}, cw.chunks)
+	_, err = w.WriteAt([]byte("abcd"), 0)
+	assert.ErrorContains(t, err, "closed")
+}
+
+func TestSingleChunkWriter(t *testing.T) {
+	ctx := context.Background()
+	w := &singleChunkWriter{o: &Object{remote: "file"}}
+	n, err := w.WriteChunk(ctx, 0, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.Equal(t, "hello", string(w.data))
+	_, err = w.WriteChunk(ctx, 1, strings.NewReader("world"))
+	assert.ErrorContains(t, err, "unexpected chunk 1")
+	require.NoError(t, w.Abort(ctx))
+	assert.Nil(t, w.data)
+}
+
 // Return a map of the headers in the options with keys stripped of the "x-bz-info-" prefix
 func OpenOptionToMetaData(options []fs.OpenOption) map[string]string {
 	var headers = make(map[string]string)
@@ -227,6 +1705,13 @@ func (f *Fs) internalTestMetadata(t *testing.T, size string, uploadCutoff string
 			require.NoError(t, err)
 		}
 
+		// Store an archival date too
+		const archiveInfoKey = "archive_date"
+		f.opt.ArchiveInfoKey = archiveInfoKey
+		defer func() {
+			f.opt.ArchiveInfoKey = ""
+		}()
+
 		item := fstest.NewItem("test-metadata", contents, fstest.Time("2001-05-06T04:05:06.499Z"))
 		btime := time.Now()
 		metadata := fs.Metadata{
@@ -272,6 +1757,19 @@ func (f *Fs) internalTestMetadata(t *testing.T, size string, uploadCutoff string
 		}
 		assert.Equal(t, item.ModTime, time.Time(mtime), "Modification time")
 
+		// Archival date stored alongside it and read back from a listing
+		assert.Equal(t, gotMetadata.Info[timeKey], gotMetadata.Info[archiveInfoKey], "Archive info")
+		entries, err := f.List(ctx, "")
+		require.NoError(t, err)
+		found := false
+		for _, entry := range entries {
+			if listed, ok := entry.(*Object); ok && listed.Remote() == item.Path {
+				found = true
+				assert.Equal(t, item.ModTime.Format(time.RFC3339Nano), listed.meta["archive-time"], "Archive time")
+			}
+		}
+		assert.True(t, found, "object not found in listing")
+
 		// Upload time
 		gotBtime := time.Time(gotMetadata.UploadTimestamp)
 		dt := gotBtime.Sub(btime)
@@ -484,3 +1982,69 @@ func (f *Fs) InternalTest(t *testing.T) {
 }
 
 var _ fstests.InternalTester = (*Fs)(nil)
+
+// BenchmarkUpdate measures uploading a file with Object.Update to a
+// test server, which buffers through the pooled buffers from
+// lib/multipart when the size is unknown or --b2-crc32c is set.
+func BenchmarkUpdate(b *testing.B) {
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("x"), 4*1024*1024)
+	var server *httptest.Server
+	f, server := newTestFs(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/b2_get_upload_url":
+			response := api.GetUploadURLResponse{
+				BucketID:           "bucketID",
+				UploadURL:          server.URL + "/upload",
+				AuthorizationToken: "token",
+			}
+			_ = json.NewEncoder(w).Encode(&response)
+		case "/upload":
+			n, _ := io.Copy(io.Discard, r.Body)
+			response := api.FileInfo{
+				ID:     "id",
+				Name:   "file.txt",
+				Action: "upload",
+				Size:   n,
+				SHA1:   r.Header.Get(sha1Header),
+				Info: map[string]string{
+					timeKey: r.Header.Get(timeHeader),
+				},
+			}
+			_ = json.NewEncoder(w).Encode(&response)
+		default:
+			http.Error(w, `{"status":400,"code":"bad_request","message":"unexpected"}`, http.StatusBadRequest)
+		}
+	})
+	f.setBucketID("bucket", "bucketID")
+	f.opt.ChunkSize = defaultChunkSize
+	f.opt.UploadCutoff = defaultUploadCutoff
+	f.setRoot("bucket")
+
+	for _, test := range []struct {
+		name   string
+		size   int64
+		crc32c bool
+	}{
+		{name: "Known", size: int64(len(data))},
+		{name: "Streamed", size: -1},
+		{name: "CRC32C", size: int64(len(data)), crc32c: true},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			f.opt.CRC32C = test.crc32c
+			src := object.NewStaticObjectInfo("file.txt", fstest.Time("2001-02-03T04:05:06.000000000Z"), test.size, true, nil, nil)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				o := &Object{
+					fs:     f,
+					remote: "file.txt",
+				}
+				if err := o.Update(ctx, bytes.NewReader(data), src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -11,8 +11,9 @@ import (
 // TestIntegration runs integration tests against the remote
 func TestIntegration(t *testing.T) {
 	fstests.Run(t, &fstests.Opt{
-		RemoteName: "TestB2:",
-		NilObject:  (*Object)(nil),
+		RemoteName:  "TestB2:",
+		NilObject:   (*Object)(nil),
+		TiersToTest: []string{standardTier},
 		ChunkedUpload: fstests.ChunkedUploadConfig{
 			MinChunkSize:       minChunkSize,
 			NeedMultipleChunks: true,
@@ -5,11 +5,14 @@
 package b2
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	gohash "hash"
+	"hash/crc32"
 	"io"
 	"strings"
 	"sync"
@@ -20,7 +23,9 @@ import (
 	"github.com/rclone/rclone/fs/chunksize"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/lib/multipart"
 	"github.com/rclone/rclone/lib/pool"
+	"github.com/rclone/rclone/lib/ranges"
 	"github.com/rclone/rclone/lib/rest"
 	"golang.org/x/sync/errgroup"
 )
@@ -68,6 +73,28 @@ func newHashAppendingReader(in io.Reader, h gohash.Hash) *hashAppendingReader {
 	return &hashAppendingReader{h: h, in: withHash}
 }
 
+// crc32cTable is the Castagnoli table used for --b2-crc32c
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// bufferAndHash reads all of in into a memory buffer, computing the
+// SHA1 and the CRC32C of the data in the same pass.
+//
+// It returns the buffer ready for reading, the SHA1 as hex and the
+// CRC32C as base64. The caller must Close the buffer.
+func bufferAndHash(in io.Reader) (rw *pool.RW, sha1Sum, crc32cSum string, err error) {
+	rw = multipart.NewRW()
+	sha1Hash := sha1.New()
+	crc32cHash := crc32.New(crc32cTable)
+	_, err = io.Copy(io.MultiWriter(rw, sha1Hash, crc32cHash), in)
+	if err != nil {
+		_ = rw.Close()
+		return nil, "", "", fmt.Errorf("failed to read data to checksum: %w", err)
+	}
+	sha1Sum = hex.EncodeToString(sha1Hash.Sum(nil))
+	crc32cSum = base64.StdEncoding.EncodeToString(crc32cHash.Sum(nil))
+	return rw, sha1Sum, crc32cSum, nil
+}
+
 // largeUpload is used to control the upload of large files which need chunking
 type largeUpload struct {
 	f         *Fs                             // parent Fs
@@ -105,7 +132,7 @@ func (f *Fs) newLargeUpload(ctx context.Context, o *Object, in io.Reader, src fs
 		}
 	}
 	bucket, bucketPath := o.split()
-	bucketID, err := f.getBucketID(ctx, bucket)
+	bucketID, err := f.getUploadBucketID(ctx, bucket)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +151,9 @@ func (f *Fs) newLargeUpload(ctx context.Context, o *Object, in io.Reader, src fs
 		request.Info = map[string]string{
 			timeKey: timeString(modTime),
 		}
+		if key := o.fs.opt.ArchiveInfoKey; key != "" {
+			request.Info[key] = timeString(modTime)
+		}
 		// Custom upload headers - remove header prefix since they are sent in the body
 		for _, option := range options {
 			k, v := option.Header()
@@ -502,3 +532,171 @@ func (up *largeUpload) Copy(ctx context.Context) (err error) {
 	}
 	return up.Close(ctx)
 }
+
+// chunkWriterAt maps writes at offsets onto the parts of a large
+// upload so the parts can be written in any order.
+//
+// Writes may be of any size at any offset. They are buffered until a
+// chunk has been completely written then the chunk is uploaded and
+// its buffer released, so the memory used depends on how many chunks
+// are partially written at once.
+type chunkWriterAt struct {
+	ctx       context.Context
+	cw        fs.ChunkWriter
+	remote    string
+	chunkSize int64
+	size      int64
+	mu        sync.Mutex
+	pending   map[int]*pendingChunk // chunks which have been partially written
+	written   []bool                // set for chunks which have been uploaded or are being uploaded
+	err       error                 // first error writing a chunk
+	closed    bool
+}
+
+// pendingChunk is a chunk being filled in by WriteAt
+type pendingChunk struct {
+	buf     []byte
+	present ranges.Ranges
+}
+
+// newChunkWriterAt makes a chunkWriterAt writing size bytes to cw in
+// chunks of chunkSize.
+func newChunkWriterAt(ctx context.Context, cw fs.ChunkWriter, remote string, chunkSize, size int64) *chunkWriterAt {
+	chunks := (size + chunkSize - 1) / chunkSize
+	return &chunkWriterAt{
+		ctx:       ctx,
+		cw:        cw,
+		remote:    remote,
+		chunkSize: chunkSize,
+		size:      size,
+		pending:   make(map[int]*pendingChunk),
+		written:   make([]bool, chunks),
+	}
+}
+
+// WriteAt writes p at off. It may be called concurrently.
+func (w *chunkWriterAt) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > w.size {
+		return 0, fmt.Errorf("%s: write of %d bytes at %d beyond end of file size %d", w.remote, len(p), off, w.size)
+	}
+	for len(p) > 0 {
+		chunkNumber := int(off / w.chunkSize)
+		chunkStart := int64(chunkNumber) * w.chunkSize
+		chunkLen := min(w.chunkSize, w.size-chunkStart)
+		pos := off - chunkStart
+		toCopy := min(int64(len(p)), chunkLen-pos)
+		buf, err := w.fill(chunkNumber, chunkLen, pos, p[:toCopy])
+		if err != nil {
+			return n, err
+		}
+		if buf != nil {
+			_, err = w.cw.WriteChunk(w.ctx, chunkNumber, bytes.NewReader(buf))
+			if err != nil {
+				w.mu.Lock()
+				if w.err == nil {
+					w.err = err
+				}
+				w.mu.Unlock()
+				return n, err
+			}
+		}
+		p = p[toCopy:]
+		off += toCopy
+		n += int(toCopy)
+	}
+	return n, nil
+}
+
+// fill copies p into the chunk at pos, returning the chunk's data if
+// it is now complete and should be uploaded.
+func (w *chunkWriterAt) fill(chunkNumber int, chunkLen, pos int64, p []byte) (buf []byte, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil, fmt.Errorf("%s: write to closed file", w.remote)
+	}
+	if w.written[chunkNumber] {
+		return nil, fmt.Errorf("%s: chunk %d already written", w.remote, chunkNumber)
+	}
+	chunk := w.pending[chunkNumber]
+	if chunk == nil {
+		chunk = &pendingChunk{buf: make([]byte, chunkLen)}
+		w.pending[chunkNumber] = chunk
+	}
+	copy(chunk.buf[pos:], p)
+	chunk.present.Insert(ranges.Range{Pos: pos, Size: int64(len(p))})
+	if !chunk.present.Present(ranges.Range{Pos: 0, Size: chunkLen}) {
+		return nil, nil
+	}
+	delete(w.pending, chunkNumber)
+	w.written[chunkNumber] = true
+	return chunk.buf, nil
+}
+
+// Close finishes the upload if all the chunks were written or
+// aborts it if not.
+func (w *chunkWriterAt) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("%s: file already closed", w.remote)
+	}
+	w.closed = true
+	w.pending = nil
+	err := w.err
+	if err == nil {
+		missing := 0
+		for _, written := range w.written {
+			if !written {
+				missing++
+			}
+		}
+		if missing > 0 {
+			err = fmt.Errorf("%s: %d of %d chunks not written", w.remote, missing, len(w.written))
+		}
+	}
+	if err != nil {
+		_ = w.cw.Abort(w.ctx)
+		return err
+	}
+	return w.cw.Close(w.ctx)
+}
+
+// singleChunkWriter is a ChunkWriter for files of at most one chunk
+// which can't be uploaded as large files. The data is uploaded with
+// a simple upload on Close.
+type singleChunkWriter struct {
+	o    *Object
+	src  fs.ObjectInfo
+	data []byte
+}
+
+// WriteChunk stores the data for Close to upload
+func (w *singleChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber != 0 {
+		return 0, fmt.Errorf("%s: unexpected chunk %d", w.o.remote, chunkNumber)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	w.data = data
+	return int64(len(data)), nil
+}
+
+// Close uploads the data
+func (w *singleChunkWriter) Close(ctx context.Context) error {
+	return w.o.Update(ctx, bytes.NewReader(w.data), w.src)
+}
+
+// Abort discards the data
+func (w *singleChunkWriter) Abort(ctx context.Context) error {
+	w.data = nil
+	return nil
+}
+
+// Check interfaces
+var (
+	_ fs.WriterAtCloser = (*chunkWriterAt)(nil)
+	_ fs.ChunkWriter    = (*singleChunkWriter)(nil)
+)
@@ -46,6 +46,7 @@ func TestIntegration(t *testing.T) {
 			"DirCacheFlush",
 			"UserInfo",
 			"Disconnect",
+			"Reconnect",
 		},
 	}
 	if *fstest.RemoteName == "" {
@@ -1092,6 +1092,15 @@ func (f *Fs) Disconnect(ctx context.Context) error {
 	return do(ctx)
 }
 
+// Reconnect forces a fresh authentication with the remote
+func (f *Fs) Reconnect(ctx context.Context) error {
+	do := f.Fs.Features().Reconnect
+	if do == nil {
+		return fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
 // Shutdown the backend, closing any background tasks and any
 // cached connections.
 func (f *Fs) Shutdown(ctx context.Context) error {
@@ -1291,6 +1300,7 @@ var (
 	_ fs.PublicLinker    = (*Fs)(nil)
 	_ fs.UserInfoer      = (*Fs)(nil)
 	_ fs.Disconnecter    = (*Fs)(nil)
+	_ fs.Reconnecter     = (*Fs)(nil)
 	_ fs.Shutdowner      = (*Fs)(nil)
 	_ fs.FullObjectInfo  = (*ObjectInfo)(nil)
 	_ fs.FullObject      = (*Object)(nil)
@@ -337,6 +337,14 @@ func (f *Fs) Disconnect(ctx context.Context) error {
 	return fs.ErrorNotImplemented
 }
 
+// Reconnect forces a fresh authentication with the remote
+func (f *Fs) Reconnect(ctx context.Context) error {
+	if do := f.Fs.Features().Reconnect; do != nil {
+		return do(ctx)
+	}
+	return fs.ErrorNotImplemented
+}
+
 // MergeDirs merges the contents of all the directories passed
 // in into the first one and rmdirs the other directories.
 func (f *Fs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
@@ -569,6 +577,7 @@ var (
 	_ fs.PublicLinker    = (*Fs)(nil)
 	_ fs.UserInfoer      = (*Fs)(nil)
 	_ fs.Disconnecter    = (*Fs)(nil)
+	_ fs.Reconnecter     = (*Fs)(nil)
 	_ fs.Shutdowner      = (*Fs)(nil)
 	_ fs.FullObject      = (*Object)(nil)
 )
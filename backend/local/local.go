@@ -363,6 +363,10 @@ type Object struct {
 	mode    os.FileMode
 	modTime time.Time
 	hashes  map[hash.Type]string // Hashes
+	dev     uint64               // device number
+	ino     uint64               // inode number
+	nlink   uint64               // number of hard links
+	inodeOK bool                 // set if dev, ino and nlink are valid
 	// these are read only and don't need the mutex held
 	translatedLink bool // Is this object a translated link
 }
@@ -1169,6 +1173,14 @@ func (o *Object) ModTime(ctx context.Context) time.Time {
 	return o.modTime
 }
 
+// Inode returns the device and inode numbers of the object and the
+// number of hard links to it
+func (o *Object) Inode() (dev, ino, nlink uint64, ok bool) {
+	o.fs.objectMetaMu.RLock()
+	defer o.fs.objectMetaMu.RUnlock()
+	return o.dev, o.ino, o.nlink, o.inodeOK
+}
+
 // Set the atime and ltime of the object
 func (o *Object) setTimes(atime, mtime time.Time) (err error) {
 	if o.translatedLink {
@@ -1534,6 +1546,7 @@ func (o *Object) setMetadata(info os.FileInfo) {
 	o.size = info.Size()
 	o.modTime = readTime(o.fs.opt.TimeType, info)
 	o.mode = info.Mode()
+	o.dev, o.ino, o.nlink, o.inodeOK = readInode(info)
 	o.fs.objectMetaMu.Unlock()
 	// Read the size of the link.
 	//
@@ -1703,6 +1716,7 @@ var (
 	_ fs.Object          = &Object{}
 	_ fs.Metadataer      = &Object{}
 	_ fs.SetMetadataer   = &Object{}
+	_ fs.Inoder          = &Object{}
 	_ fs.Directory       = &Directory{}
 	_ fs.SetModTimer     = &Directory{}
 	_ fs.SetMetadataer   = &Directory{}
@@ -11,3 +11,9 @@ import "os"
 func readDevice(fi os.FileInfo, oneFileSystem bool) uint64 {
 	return devUnset
 }
+
+// readInode returns the device and inode numbers and the number of
+// hard links from a valid os.FileInfo. ok is false if it fails.
+func readInode(fi os.FileInfo) (dev, ino, nlink uint64, ok bool) {
+	return 0, 0, 0, false
+}
@@ -24,3 +24,13 @@ func readDevice(fi os.FileInfo, oneFileSystem bool) uint64 {
 	}
 	return uint64(statT.Dev) // nolint: unconvert
 }
+
+// readInode returns the device and inode numbers and the number of
+// hard links from a valid os.FileInfo. ok is false if it fails.
+func readInode(fi os.FileInfo) (dev, ino, nlink uint64, ok bool) {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return uint64(statT.Dev), uint64(statT.Ino), uint64(statT.Nlink), true // nolint: unconvert
+}
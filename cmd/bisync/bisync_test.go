@@ -99,6 +99,8 @@ var logReplacements = []string{
 	`^INFO  : .*cache expired.*$`, dropMe,
 	// ignore "Implicitly create directory" messages (TestnStorage:)
 	`^INFO  : .*Implicitly create directory.*$`, dropMe,
+	// ignore the transfer summary as sizes differ between backends
+	`^INFO  : Synced: Path1→Path2 .*$`, dropMe,
 	// ignore differences in backend features
 	`^.*?"HashType1":.*?$`, dropMe,
 	`^.*?"HashType2":.*?$`, dropMe,
@@ -1073,6 +1075,9 @@ func (b *bisyncTest) runBisync(ctx context.Context, args []string) (err error) {
 			opt.CheckSync = bisync.CheckSyncFalse
 		case "check-access":
 			opt.CheckAccess = true
+		case "check-access-side":
+			err = opt.CheckAccessSide.Set(val)
+			require.NoError(b.t, err, "parsing check-access-side=%q", val)
 		case "check-filename":
 			opt.CheckFilename = val
 		case "filters-file":
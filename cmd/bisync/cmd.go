@@ -34,6 +34,7 @@ type Options struct {
 	Resync                bool   // whether or not this is a resync
 	ResyncMode            Prefer // which mode to use for resync
 	CheckAccess           bool
+	CheckAccessSide       CheckAccessSideMode
 	CheckFilename         string
 	CheckSync             CheckSyncMode
 	CreateEmptySrcDirs    bool
@@ -47,6 +48,7 @@ type Options struct {
 	BackupDir2            string
 	DryRun                bool
 	NoCleanup             bool
+	KeepListings          bool
 	SaveQueues            bool // save extra debugging files (test only flag)
 	IgnoreListingChecksum bool
 	Resilient             bool
@@ -114,6 +116,48 @@ func (x *CheckSyncMode) Type() string {
 	return "string"
 }
 
+// CheckAccessSideMode controls which paths must have the check files
+type CheckAccessSideMode int
+
+// CheckAccessSide modes
+const (
+	CheckAccessBoth  CheckAccessSideMode = iota // Check files must match on both paths (default)
+	CheckAccessPath1                            // Only Path1 must have all the check files
+	CheckAccessPath2                            // Only Path2 must have all the check files
+)
+
+func (x CheckAccessSideMode) String() string {
+	switch x {
+	case CheckAccessBoth:
+		return "both"
+	case CheckAccessPath1:
+		return "path1"
+	case CheckAccessPath2:
+		return "path2"
+	}
+	return "unknown"
+}
+
+// Set a CheckAccessSide mode from a string
+func (x *CheckAccessSideMode) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "both":
+		*x = CheckAccessBoth
+	case "path1":
+		*x = CheckAccessPath1
+	case "path2":
+		*x = CheckAccessPath2
+	default:
+		return fmt.Errorf("unknown check-access-side mode for bisync: %q", s)
+	}
+	return nil
+}
+
+// Type of the CheckAccessSide value
+func (x *CheckAccessSideMode) Type() string {
+	return "string"
+}
+
 // Opt keeps command line options
 var Opt Options
 
@@ -127,6 +171,7 @@ func init() {
 	flags.BoolVarP(cmdFlags, &Opt.Resync, "resync", "1", Opt.Resync, "Performs the resync run. Equivalent to --resync-mode path1. Consider using --verbose or --dry-run first.", "")
 	flags.FVarP(cmdFlags, &Opt.ResyncMode, "resync-mode", "", "During resync, prefer the version that is: path1, path2, newer, older, larger, smaller (default: path1 if --resync, otherwise none for no resync.)", "")
 	flags.BoolVarP(cmdFlags, &Opt.CheckAccess, "check-access", "", Opt.CheckAccess, makeHelp("Ensure expected {CHECKFILE} files are found on both Path1 and Path2 filesystems, else abort."), "")
+	flags.FVarP(cmdFlags, &Opt.CheckAccessSide, "check-access-side", "", "Which paths --check-access requires the check files on: both|path1|path2 (default: both)", "")
 	flags.StringVarP(cmdFlags, &Opt.CheckFilename, "check-filename", "", Opt.CheckFilename, makeHelp("Filename for --check-access (default: {CHECKFILE})"), "")
 	flags.BoolVarP(cmdFlags, &Opt.Force, "force", "", Opt.Force, "Bypass --max-delete safety check and run the sync. Consider using with --verbose", "")
 	flags.FVarP(cmdFlags, &Opt.CheckSync, "check-sync", "", "Controls comparison of final listings: true|false|only (default: true)", "")
@@ -139,6 +184,7 @@ func init() {
 	flags.StringVarP(cmdFlags, &Opt.DebugName, "debugname", "", Opt.DebugName, "Debug by tracking one file at various points throughout a bisync run (when -v or -vv)", "")
 	flags.BoolVarP(cmdFlags, &tzLocal, "localtime", "", tzLocal, "Use local time in listings (default: UTC)", "")
 	flags.BoolVarP(cmdFlags, &Opt.NoCleanup, "no-cleanup", "", Opt.NoCleanup, "Retain working files (useful for troubleshooting and testing).", "")
+	flags.BoolVarP(cmdFlags, &Opt.KeepListings, "keep-listings", "", Opt.KeepListings, "Keep timestamped copies of the listings from every run (useful for troubleshooting).", "")
 	flags.BoolVarP(cmdFlags, &Opt.IgnoreListingChecksum, "ignore-listing-checksum", "", Opt.IgnoreListingChecksum, "Do not use checksums for listings (add --ignore-checksum to additionally skip post-copy checksum checks)", "")
 	flags.BoolVarP(cmdFlags, &Opt.Resilient, "resilient", "", Opt.Resilient, "Allow future runs to retry after certain less-serious errors, instead of requiring --resync. Use at your own risk!", "")
 	flags.BoolVarP(cmdFlags, &Opt.Recover, "recover", "", Opt.Recover, "Automatically recover from interruptions without requiring --resync.", "")
@@ -69,7 +69,7 @@ func (b *bisyncRun) setCompareDefaults(ctx context.Context) error {
 	} else if b.opt.Compare.Checksum && !ci.CheckSum {
 		fs.Logf(nil, Color(terminal.YellowFg, "WARNING: Checksums will be compared for deltas but not during sync as --checksum is not set.")) //nolint:govet
 	}
-	if b.opt.Compare.Modtime && (b.fs1.Precision() == fs.ModTimeNotSupported || b.fs2.Precision() == fs.ModTimeNotSupported) {
+	if b.opt.Compare.Modtime && (fs.EffectivePrecision(b.fs1) == fs.ModTimeNotSupported || fs.EffectivePrecision(b.fs2) == fs.ModTimeNotSupported) {
 		fs.Logf(nil, Color(terminal.YellowFg, "WARNING: Modtime compare was requested but at least one remote does not support it. It is recommended to use --checksum or --size-only instead.")) //nolint:govet
 	}
 	if (ci.CheckSum || b.opt.Compare.Checksum) && b.opt.IgnoreListingChecksum {
@@ -24,6 +24,8 @@ var rcHelp = makeHelp(`This takes the following parameters
 - dryRun - dry-run mode
 - resync - performs the resync run
 - checkAccess - abort if {CHECKFILE} files are not found on both filesystems
+- checkAccessSide - which paths checkAccess requires the {CHECKFILE} files on:
+              |both| (default), |path1| or |path2|
 - checkFilename - file name for checkAccess (default: {CHECKFILE})
 - maxDelete - abort sync if percentage of deleted files is above
   this threshold (default: {MAXDELETE})
@@ -41,6 +43,7 @@ var rcHelp = makeHelp(`This takes the following parameters
 - backupdir1 - --backup-dir for Path1. Must be a non-overlapping path on the same remote.
 - backupdir2 - --backup-dir for Path2. Must be a non-overlapping path on the same remote.
 - noCleanup - retain working files
+- keepListings - keep timestamped copies of the listings from every run
 
 See [bisync command help](https://rclone.org/commands/rclone_bisync/)
 and [full bisync description](https://rclone.org/bisync/)
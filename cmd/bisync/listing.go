@@ -375,6 +375,30 @@ func (b *bisyncRun) replaceCurrentListings() {
 	b.handleErr(b.newListing2, "error replacing Path2 listing", bilib.CopyFileIfExists(b.newListing2, b.listing2), true, true)
 }
 
+// keepListingsFormat is the time format of the suffix of the listing
+// copies made by --keep-listings
+const keepListingsFormat = "2006-01-02T150405.000000000Z"
+
+// keepListings saves timestamped copies of all the working listings
+// if --keep-listings is set, so they aren't lost when the next run
+// overwrites them.
+//
+// Unless --no-cleanup is set too the ".lst-new" listings are removed
+// once copied.
+func (b *bisyncRun) keepListings(t time.Time) {
+	if !b.opt.KeepListings {
+		return
+	}
+	suffix := "." + t.UTC().Format(keepListingsFormat)
+	for _, listing := range []string{b.listing1, b.listing2, b.listing1 + "-old", b.listing2 + "-old", b.newListing1, b.newListing2} {
+		b.handleErr(listing, "error keeping listing", bilib.CopyFileIfExists(listing, listing+suffix), false, false)
+	}
+	if !b.opt.NoCleanup {
+		_ = os.Remove(b.newListing1)
+		_ = os.Remove(b.newListing2)
+	}
+}
+
 // revertToOldListings reverts to the most recent successful listing
 func (b *bisyncRun) revertToOldListings() {
 	b.handleErr(b.listing1, "error reverting to old Path1 listing", bilib.CopyFileIfExists(b.listing1+"-old", b.listing1), true, true)
@@ -0,0 +1,62 @@
+package bisync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepListings(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 6, time.UTC)
+	const suffix = ".2024-01-02T150405.000000006Z"
+
+	for _, noCleanup := range []bool{false, true} {
+		dir := t.TempDir()
+		basePath := filepath.Join(dir, "path1_path2")
+		b := &bisyncRun{
+			opt:         &Options{KeepListings: true, NoCleanup: noCleanup},
+			listing1:    basePath + ".path1.lst",
+			listing2:    basePath + ".path2.lst",
+			newListing1: basePath + ".path1.lst-new",
+			newListing2: basePath + ".path2.lst-new",
+		}
+		// The -old listings are missing as after a first run
+		listings := []string{b.listing1, b.listing2, b.newListing1, b.newListing2}
+		for _, listing := range listings {
+			require.NoError(t, os.WriteFile(listing, []byte(filepath.Base(listing)), 0666))
+		}
+
+		b.keepListings(now)
+		assert.False(t, b.critical)
+
+		for _, listing := range listings {
+			data, err := os.ReadFile(listing + suffix)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Base(listing), string(data))
+		}
+		assert.NoFileExists(t, b.listing1+"-old"+suffix)
+		assert.FileExists(t, b.listing1)
+		assert.FileExists(t, b.listing2)
+		if noCleanup {
+			assert.FileExists(t, b.newListing1)
+			assert.FileExists(t, b.newListing2)
+		} else {
+			assert.NoFileExists(t, b.newListing1)
+			assert.NoFileExists(t, b.newListing2)
+		}
+	}
+
+	// Nothing is kept without --keep-listings
+	dir := t.TempDir()
+	b := &bisyncRun{
+		opt:      &Options{},
+		listing1: filepath.Join(dir, "path1_path2.path1.lst"),
+	}
+	require.NoError(t, os.WriteFile(b.listing1, nil, 0666))
+	b.keepListings(now)
+	assert.NoFileExists(t, b.listing1+suffix)
+}
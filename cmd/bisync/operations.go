@@ -171,6 +171,7 @@ func Bisync(ctx context.Context, fs1, fs2 fs.Fs, optArg *Options) (err error) {
 	// run bisync
 	err = b.runLocked(ctx)
 
+	b.keepListings(time.Now())
 	b.removeLockFile()
 
 	b.CleanupCompleted = true
@@ -425,8 +426,11 @@ func (b *bisyncRun) runLocked(octx context.Context) (err error) {
 		b.retryable = true
 		return err
 	}
+	if !noChanges {
+		logSummary(results1to2, results2to1)
+	}
 
-	if !opt.NoCleanup {
+	if !opt.NoCleanup && !opt.KeepListings {
 		_ = os.Remove(b.newListing1)
 		_ = os.Remove(b.newListing2)
 	}
@@ -494,14 +498,27 @@ func (b *bisyncRun) checkSync(listing1, listing2 string) error {
 }
 
 // checkAccess validates access health
+//
+// With --check-access-side set to path1 or path2 only that path is
+// required to have all the check files, so check files which are
+// missing from the other path are not treated as an error.
 func (b *bisyncRun) checkAccess(checkFiles1, checkFiles2 bilib.Names) error {
 	ok := true
 	opt := b.opt
 	prefix := "Access test failed:"
+	check1 := opt.CheckAccessSide != CheckAccessPath2
+	check2 := opt.CheckAccessSide != CheckAccessPath1
 
 	numChecks1 := len(checkFiles1)
 	numChecks2 := len(checkFiles2)
-	if numChecks1 == 0 || numChecks1 != numChecks2 {
+	countFailed := numChecks1 == 0 || numChecks1 != numChecks2
+	switch opt.CheckAccessSide {
+	case CheckAccessPath1:
+		countFailed = numChecks1 == 0
+	case CheckAccessPath2:
+		countFailed = numChecks2 == 0
+	}
+	if countFailed {
 		if numChecks1 == 0 && numChecks2 == 0 {
 			fs.Logf("--check-access", Color(terminal.RedFg, "Failed to find any files named %s\n More info: %s"), Color(terminal.CyanFg, opt.CheckFilename), Color(terminal.BlueFg, "https://rclone.org/bisync/#check-access"))
 		}
@@ -509,24 +526,35 @@ func (b *bisyncRun) checkAccess(checkFiles1, checkFiles2 bilib.Names) error {
 		ok = false
 	}
 
-	for file := range checkFiles1 {
-		if !checkFiles2.Has(file) {
-			b.indentf("ERROR", file, "%s Path1 file not found in Path2", prefix)
-			ok = false
+	if check2 {
+		for file := range checkFiles1 {
+			if !checkFiles2.Has(file) {
+				b.indentf("ERROR", file, "%s Path1 file not found in Path2", prefix)
+				ok = false
+			}
 		}
 	}
 
-	for file := range checkFiles2 {
-		if !checkFiles1.Has(file) {
-			b.indentf("ERROR", file, "%s Path2 file not found in Path1", prefix)
-			ok = false
+	if check1 {
+		for file := range checkFiles2 {
+			if !checkFiles1.Has(file) {
+				b.indentf("ERROR", file, "%s Path2 file not found in Path1", prefix)
+				ok = false
+			}
 		}
 	}
 
 	if !ok {
 		return errors.New("check file check failed")
 	}
-	fs.Infof(nil, "Found %d matching %q files on both paths", numChecks1, opt.CheckFilename)
+	switch opt.CheckAccessSide {
+	case CheckAccessPath1:
+		fs.Infof(nil, "Found %d matching %q files on Path1", numChecks1, opt.CheckFilename)
+	case CheckAccessPath2:
+		fs.Infof(nil, "Found %d matching %q files on Path2", numChecks2, opt.CheckFilename)
+	default:
+		fs.Infof(nil, "Found %d matching %q files on both paths", numChecks1, opt.CheckFilename)
+	}
 	return nil
 }
 
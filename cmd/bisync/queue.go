@@ -51,6 +51,54 @@ func (rs *ResultsSlice) has(name string) bool {
 	return false
 }
 
+// transferSummary totals up the transfers made by a bisync run
+type transferSummary struct {
+	files1to2, files2to1 int   // number of files copied in each direction
+	bytes1to2, bytes2to1 int64 // number of bytes copied in each direction
+	deletes              int   // number of files deleted from either path
+}
+
+// add the results of the sync in one direction to the summary
+func (ts *transferSummary) add(results []Results, is1to2 bool) {
+	for _, result := range results {
+		if result.Err != nil || result.Flags == "d" {
+			continue
+		}
+		switch {
+		case result.IsSrc && (result.Sigil == operations.MissingOnDst || result.Sigil == operations.Differ):
+			size := result.Size
+			if size < 0 {
+				size = 0
+			}
+			if is1to2 {
+				ts.files1to2++
+				ts.bytes1to2 += size
+			} else {
+				ts.files2to1++
+				ts.bytes2to1 += size
+			}
+		case result.IsDst && result.Sigil == operations.MissingOnSrc:
+			ts.deletes++
+		}
+	}
+}
+
+// String returns a one line description of the summary
+func (ts *transferSummary) String() string {
+	return fmt.Sprintf("Synced: Path1→Path2 %d files / %s, Path2→Path1 %d files / %s, %d deletes",
+		ts.files1to2, fs.SizeSuffix(ts.bytes1to2).ByteUnit(),
+		ts.files2to1, fs.SizeSuffix(ts.bytes2to1).ByteUnit(),
+		ts.deletes)
+}
+
+// logSummary logs the totals of the transfers in results1to2 and results2to1
+func logSummary(results1to2, results2to1 []Results) {
+	var ts transferSummary
+	ts.add(results1to2, true)
+	ts.add(results2to1, false)
+	fs.Infof(nil, "%s", ts.String())
+}
+
 var (
 	logger                = operations.NewLoggerOpt()
 	lock                  mutex.Mutex
@@ -119,7 +167,15 @@ func WriteResults(ctx context.Context, sigil operations.Sigil, src, dst fs.DirEn
 		Origin: "sync",
 	}
 
-	result.Winner = operations.WinningSide(ctx, sigil, src, dst, err)
+	// In --dry-run mode predict the winner as if the transfers had
+	// really happened, so the .lst-dry listings preview the result.
+	winnerCtx := ctx
+	if fs.GetConfig(ctx).DryRun {
+		var winnerCI *fs.ConfigInfo
+		winnerCtx, winnerCI = fs.AddConfig(ctx)
+		winnerCI.DryRun = false
+	}
+	result.Winner = operations.WinningSide(winnerCtx, sigil, src, dst, err)
 
 	fss := []fs.DirEntry{src, dst}
 	for i, side := range fss {
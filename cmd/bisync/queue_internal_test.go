@@ -0,0 +1,53 @@
+package bisync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferSummary(t *testing.T) {
+	// Each transfer has a result for the src and the dst side
+	copied := func(name string, size int64) []Results {
+		return []Results{
+			{Name: name, Size: size, Sigil: operations.MissingOnDst, IsSrc: true, Flags: "-"},
+			{Name: name, Size: -1, Sigil: operations.MissingOnDst, IsDst: true, Flags: "-"},
+		}
+	}
+	updated := func(name string, size int64) []Results {
+		return []Results{
+			{Name: name, Size: size, Sigil: operations.Differ, IsSrc: true, Flags: "-"},
+			{Name: name, Size: 1, Sigil: operations.Differ, IsDst: true, Flags: "-"},
+		}
+	}
+	deleted := func(name string) []Results {
+		return []Results{
+			{Name: name, Size: -1, Sigil: operations.MissingOnSrc, IsSrc: true, Flags: "-"},
+			{Name: name, Size: 100, Sigil: operations.MissingOnSrc, IsDst: true, Flags: "-"},
+		}
+	}
+	var results1to2, results2to1 []Results
+	results1to2 = append(results1to2, copied("file1", 1024)...)
+	results1to2 = append(results1to2, updated("file2", 2048)...)
+	results1to2 = append(results1to2, deleted("file3")...)
+	results1to2 = append(results1to2, Results{Name: "dir", Size: -1, Sigil: operations.MissingOnDst, IsSrc: true, Flags: "d"})
+	results2to1 = append(results2to1, copied("file4", 3*1024*1024)...)
+	results2to1 = append(results2to1, deleted("file5")...)
+	results2to1 = append(results2to1, deleted("file6")...)
+	results2to1 = append(results2to1, Results{Name: "file7", Size: 5, Sigil: operations.MissingOnDst, IsSrc: true, Flags: "-", Err: errors.New("failed")})
+	results2to1 = append(results2to1, Results{Name: "file8", Size: 5, Sigil: operations.Match, IsSrc: true, Flags: "-"})
+
+	var ts transferSummary
+	ts.add(results1to2, true)
+	ts.add(results2to1, false)
+	assert.Equal(t, transferSummary{
+		files1to2: 2,
+		bytes1to2: 3072,
+		files2to1: 1,
+		bytes2to1: 3 * 1024 * 1024,
+		deletes:   3,
+	}, ts)
+	assert.Equal(t, "Synced: Path1→Path2 2 files / 3 KiB, Path2→Path1 1 files / 3 MiB, 3 deletes", ts.String())
+}
@@ -58,6 +58,9 @@ func rcBisync(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if opt.NoCleanup, err = in.GetBool("noCleanup"); rc.NotErrParamNotFound(err) {
 		return
 	}
+	if opt.KeepListings, err = in.GetBool("keepListings"); rc.NotErrParamNotFound(err) {
+		return
+	}
 	if opt.IgnoreListingChecksum, err = in.GetBool("ignoreListingChecksum"); rc.NotErrParamNotFound(err) {
 		return
 	}
@@ -92,6 +95,17 @@ func rcBisync(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 		return nil, err
 	}
 
+	checkAccessSide, err := in.GetString("checkAccessSide")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if checkAccessSide == "" {
+		checkAccessSide = "both"
+	}
+	if err := opt.CheckAccessSide.Set(checkAccessSide); err != nil {
+		return nil, err
+	}
+
 	fs1, err := rc.GetFsNamed(octx, in, "path1")
 	if err != nil {
 		return nil, err
@@ -105,7 +105,7 @@ func (b *bisyncRun) setResolveDefaults(ctx context.Context) error {
 	b.opt.ConflictSuffix2 = "." + b.opt.ConflictSuffix2
 
 	// checks and warnings
-	if (b.opt.ConflictResolve == PreferNewer || b.opt.ConflictResolve == PreferOlder) && (b.fs1.Precision() == fs.ModTimeNotSupported || b.fs2.Precision() == fs.ModTimeNotSupported) {
+	if (b.opt.ConflictResolve == PreferNewer || b.opt.ConflictResolve == PreferOlder) && (fs.EffectivePrecision(b.fs1) == fs.ModTimeNotSupported || fs.EffectivePrecision(b.fs2) == fs.ModTimeNotSupported) {
 		fs.Logf(nil, Color(terminal.YellowFg, "WARNING: ignoring --conflict-resolve %s as at least one remote does not support modtimes."), b.opt.ConflictResolve.String())
 		b.opt.ConflictResolve = PreferNone
 	} else if (b.opt.ConflictResolve == PreferNewer || b.opt.ConflictResolve == PreferOlder) && !b.opt.Compare.Modtime {
@@ -24,7 +24,7 @@ func (b *bisyncRun) setResyncDefaults() {
 	}
 
 	// checks and warnings
-	if (b.opt.ResyncMode == PreferNewer || b.opt.ResyncMode == PreferOlder) && (b.fs1.Precision() == fs.ModTimeNotSupported || b.fs2.Precision() == fs.ModTimeNotSupported) {
+	if (b.opt.ResyncMode == PreferNewer || b.opt.ResyncMode == PreferOlder) && (fs.EffectivePrecision(b.fs1) == fs.ModTimeNotSupported || fs.EffectivePrecision(b.fs2) == fs.ModTimeNotSupported) {
 		fs.Logf(nil, Color(terminal.YellowFg, "WARNING: ignoring --resync-mode %s as at least one remote does not support modtimes."), b.opt.ResyncMode.String())
 		b.opt.ResyncMode = PreferPath1
 	} else if (b.opt.ResyncMode == PreferNewer || b.opt.ResyncMode == PreferOlder) && !b.opt.Compare.Modtime {
@@ -158,6 +158,7 @@ func (b *bisyncRun) resync(octx, fctx context.Context) error {
 		b.critical = true
 		return err
 	}
+	logSummary(results1to2, results2to1)
 
 	if b.opt.CheckSync == CheckSyncTrue && !b.opt.DryRun {
 		path1 := bilib.FsPath(b.fs1)
@@ -169,7 +170,7 @@ func (b *bisyncRun) resync(octx, fctx context.Context) error {
 		}
 	}
 
-	if !b.opt.NoCleanup {
+	if !b.opt.NoCleanup && !b.opt.KeepListings {
 		_ = os.Remove(b.newListing1)
 		_ = os.Remove(b.newListing2)
 	}
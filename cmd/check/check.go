@@ -27,6 +27,7 @@ var (
 	match             = ""
 	differ            = ""
 	errFile           = ""
+	summary           = ""
 	checkFileHashType = ""
 )
 
@@ -47,6 +48,7 @@ func AddFlags(cmdFlags *pflag.FlagSet) {
 	flags.StringVarP(cmdFlags, &match, "match", "", match, "Report all matching files to this file", "")
 	flags.StringVarP(cmdFlags, &differ, "differ", "", differ, "Report all non-matching files to this file", "")
 	flags.StringVarP(cmdFlags, &errFile, "error", "", errFile, "Report all files with errors (hashing or reading) to this file", "")
+	flags.StringVarP(cmdFlags, &summary, "summary", "", summary, "Write a JSON summary of the check to this file", "")
 }
 
 // FlagsHelp describes the flags for the help
@@ -73,6 +75,22 @@ you what happened to it. These are reminiscent of diff files.
 - |* path| means path was present in source and destination but different.
 - |! path| means there was an error reading or hashing the source or dest.
 
+The |--summary| flag will write a JSON object to a file (or stdout)
+when the check has finished. This has the number of files in each of
+the categories above, and the number of matching files whose hashes
+couldn't be checked, for example:
+
+|||
+{
+	"matches": 2,
+	"differ": 1,
+	"missingOnSrc": 0,
+	"missingOnDst": 1,
+	"errors": 0,
+	"noHash": 0
+}
+|||
+
 The default number of parallel checks is 8. See the [--checkers=N](/docs/#checkers-n)
 option for more information.
 `, "|", "`")
@@ -122,6 +140,9 @@ func GetCheckOpt(fsrc, fdst fs.Fs) (opt *operations.CheckOpt, close func(), err
 	if err = open(errFile, &opt.Error); err != nil {
 		return nil, nil, err
 	}
+	if err = open(summary, &opt.Summary); err != nil {
+		return nil, nil, err
+	}
 
 	close = func() {
 		for _, closer := range closers {
@@ -192,12 +213,6 @@ the |source:path| must point to a text file in the SUM format.
 			if download {
 				return operations.CheckDownload(context.Background(), opt)
 			}
-			hashType := fsrc.Hashes().Overlap(fdst.Hashes()).GetOne()
-			if hashType == hash.None {
-				fs.Errorf(nil, "No common hash found - not using a hash for checks")
-			} else {
-				fs.Infof(nil, "Using %v for hash comparisons", hashType)
-			}
 			return operations.Check(context.Background(), opt)
 		})
 		return nil
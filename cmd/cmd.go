@@ -240,7 +240,6 @@ func ShowStats() bool {
 func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	ctx := context.Background()
 	ci := fs.GetConfig(ctx)
-	var cmdErr error
 	stopStats := func() {}
 	if !showStats && ShowStats() {
 		showStats = true
@@ -251,46 +250,7 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 		stopStats = StartStats()
 	}
 	SigInfoHandler()
-	for try := 1; try <= ci.Retries; try++ {
-		cmdErr = f()
-		cmdErr = fs.CountError(ctx, cmdErr)
-		lastErr := accounting.GlobalStats().GetLastError()
-		if cmdErr == nil {
-			cmdErr = lastErr
-		}
-		if !Retry || !accounting.GlobalStats().Errored() {
-			if try > 1 {
-				fs.Errorf(nil, "Attempt %d/%d succeeded", try, ci.Retries)
-			}
-			break
-		}
-		if accounting.GlobalStats().HadFatalError() {
-			fs.Errorf(nil, "Fatal error received - not attempting retries")
-			break
-		}
-		if accounting.GlobalStats().Errored() && !accounting.GlobalStats().HadRetryError() {
-			fs.Errorf(nil, "Can't retry any of the errors - not attempting retries")
-			break
-		}
-		if retryAfter := accounting.GlobalStats().RetryAfter(); !retryAfter.IsZero() {
-			d := time.Until(retryAfter)
-			if d > 0 {
-				fs.Logf(nil, "Received retry after error - sleeping until %s (%v)", retryAfter.Format(time.RFC3339Nano), d)
-				time.Sleep(d)
-			}
-		}
-		if lastErr != nil {
-			fs.Errorf(nil, "Attempt %d/%d failed with %d errors and: %v", try, ci.Retries, accounting.GlobalStats().GetErrors(), lastErr)
-		} else {
-			fs.Errorf(nil, "Attempt %d/%d failed with %d errors", try, ci.Retries, accounting.GlobalStats().GetErrors())
-		}
-		if try < ci.Retries {
-			accounting.GlobalStats().ResetErrors()
-		}
-		if ci.RetriesInterval > 0 {
-			time.Sleep(ci.RetriesInterval)
-		}
-	}
+	cmdErr := accounting.Retry(ctx, Retry, f)
 	stopStats()
 	if showStats && (accounting.GlobalStats().Errored() || *statsInterval > 0) {
 		accounting.GlobalStats().Log()
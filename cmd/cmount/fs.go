@@ -504,15 +504,15 @@ func (fsys *FS) Readlink(path string) (errc int, linkPath string) {
 // Chmod changes the permission bits of a file.
 func (fsys *FS) Chmod(path string, mode uint32) (errc int) {
 	defer log.Trace(path, "mode=0%o", mode)("errc=%d", &errc)
-	// This is a no-op for rclone
-	return 0
+	// rclone can't store the mode so this is a no-op or an error
+	return translateError(fsys.opt.ChmodError())
 }
 
 // Chown changes the owner and group of a file.
 func (fsys *FS) Chown(path string, uid uint32, gid uint32) (errc int) {
 	defer log.Trace(path, "uid=%d, gid=%d", uid, gid)("errc=%d", &errc)
-	// This is a no-op for rclone
-	return 0
+	// rclone can't store the owner so this is a no-op or an error
+	return translateError(fsys.opt.ChmodError())
 }
 
 // Access checks file access permissions.
@@ -4,6 +4,7 @@ package hashsum
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/rclone/rclone/cmd"
@@ -21,6 +22,7 @@ var (
 	DownloadFlag   = false
 	HashsumOutfile = ""
 	ChecksumFile   = ""
+	JSONOutput     = false
 )
 
 func init() {
@@ -35,6 +37,16 @@ func AddHashsumFlags(cmdFlags *pflag.FlagSet) {
 	flags.StringVarP(cmdFlags, &HashsumOutfile, "output-file", "", HashsumOutfile, "Output hashsums to a file rather than the terminal", "")
 	flags.StringVarP(cmdFlags, &ChecksumFile, "checkfile", "C", ChecksumFile, "Validate hashes against a given SUM file instead of printing them", "")
 	flags.BoolVarP(cmdFlags, &DownloadFlag, "download", "", DownloadFlag, "Download the file and hash it locally; if this flag is not specified, the hash is requested from the remote", "")
+	flags.BoolVarP(cmdFlags, &JSONOutput, "json", "", JSONOutput, "Format output as JSON", "")
+}
+
+// HashLister lists the hashes of the objects in fsrc to w using the
+// hashsum flags
+func HashLister(ctx context.Context, ht hash.Type, fsrc fs.Fs, w io.Writer) error {
+	if JSONOutput {
+		return operations.HashListerJSON(ctx, ht, OutputBase64, DownloadFlag, fsrc, w)
+	}
+	return operations.HashLister(ctx, ht, OutputBase64, DownloadFlag, fsrc, w)
 }
 
 // GetHashsumOutput opens and closes the output file when using the output-file flag
@@ -110,6 +122,18 @@ Then
     $ rclone hashsum MD5 remote:path
 
 Note that hash names are case insensitive and values are output in lower case.
+
+Objects which don't have the hash available on the remote are skipped
+with a notice rather than causing an error. When checking against a
+SUM file with ` + "`--checkfile`" + ` they are reported as not verified
+rather than as matching.
+
+Use the ` + "`--json`" + ` flag to output a JSON array of objects with
+` + "`Path`" + ` and ` + "`Hash`" + ` fields instead, e.g.
+
+    [
+    {"Path":"file.txt","Hash":"d41d8cd98f00b204e9800998ecf8427e"}
+    ]
 `,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.41",
@@ -137,14 +161,14 @@ Note that hash names are case insensitive and values are output in lower case.
 				return operations.CheckSum(context.Background(), fsrc, fsum, sumFile, ht, nil, DownloadFlag)
 			}
 			if HashsumOutfile == "" {
-				return operations.HashLister(context.Background(), ht, OutputBase64, DownloadFlag, fsrc, nil)
+				return HashLister(context.Background(), ht, fsrc, nil)
 			}
 			output, close, err := GetHashsumOutput(HashsumOutfile)
 			if err != nil {
 				return err
 			}
 			defer close()
-			return operations.HashLister(context.Background(), ht, OutputBase64, DownloadFlag, fsrc, output)
+			return HashLister(context.Background(), ht, fsrc, output)
 		})
 		return nil
 	},
@@ -53,14 +53,14 @@ as a relative path).
 				return operations.CheckSum(context.Background(), fsrc, fsum, sumFile, hash.MD5, nil, hashsum.DownloadFlag)
 			}
 			if hashsum.HashsumOutfile == "" {
-				return operations.HashLister(context.Background(), hash.MD5, hashsum.OutputBase64, hashsum.DownloadFlag, fsrc, nil)
+				return hashsum.HashLister(context.Background(), hash.MD5, fsrc, nil)
 			}
 			output, close, err := hashsum.GetHashsumOutput(hashsum.HashsumOutfile)
 			if err != nil {
 				return err
 			}
 			defer close()
-			return operations.HashLister(context.Background(), hash.MD5, hashsum.OutputBase64, hashsum.DownloadFlag, fsrc, output)
+			return hashsum.HashLister(context.Background(), hash.MD5, fsrc, output)
 		})
 		return nil
 	},
@@ -50,6 +50,11 @@ var _ fusefs.NodeSetattrer = (*Dir)(nil)
 // Setattr handles attribute changes from FUSE. Currently supports ModTime only.
 func (d *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
 	defer log.Trace(d, "stat=%+v", req)("err=%v", &err)
+	if req.Valid.Mode() || req.Valid.Uid() || req.Valid.Gid() {
+		if err = d.fsys.opt.ChmodError(); err != nil {
+			return translateError(err)
+		}
+	}
 	if d.VFS().Opt.NoModTime {
 		return nil
 	}
@@ -47,6 +47,11 @@ var _ fusefs.NodeSetattrer = (*File)(nil)
 // Setattr handles attribute changes from FUSE. Currently supports ModTime and Size only
 func (f *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
 	defer log.Trace(f, "a=%+v", req)("err=%v", &err)
+	if req.Valid.Mode() || req.Valid.Uid() || req.Valid.Gid() {
+		if err = f.fsys.opt.ChmodError(); err != nil {
+			return translateError(err)
+		}
+	}
 	if !f.VFS().Opt.NoModTime {
 		if req.Valid.Mtime() {
 			err = f.File.SetModTime(req.Mtime)
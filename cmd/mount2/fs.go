@@ -104,6 +104,14 @@ func (f *FS) setEntryOut(node vfs.Node, out *fuse.EntryOut) {
 	out.SetAttrTimeout(time.Duration(f.opt.AttrTimeout))
 }
 
+// chmodIn returns true if in is trying to change the mode or owner
+func chmodIn(in *fuse.SetAttrIn) bool {
+	_, mode := in.GetMode()
+	_, uid := in.GetUID()
+	_, gid := in.GetGID()
+	return mode || uid || gid
+}
+
 // Translate errors from mountlib into Syscall error numbers
 func translateError(err error) syscall.Errno {
 	if err == nil {
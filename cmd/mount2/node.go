@@ -120,6 +120,11 @@ var _ = (fusefs.NodeGetattrer)((*Node)(nil))
 func (n *Node) Setattr(ctx context.Context, f fusefs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) (errno syscall.Errno) {
 	defer log.Trace(n, "in=%v", in)("out=%#v, errno=%v", &out, &errno)
 	var err error
+	if chmodIn(in) {
+		if err = n.fsys.opt.ChmodError(); err != nil {
+			return translateError(err)
+		}
+	}
 	n.fsys.setAttrOut(n.node, out)
 	size, ok := in.GetSize()
 	if ok {
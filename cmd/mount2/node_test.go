@@ -0,0 +1,74 @@
+//go:build linux
+
+package mount2
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/cmd/mountlib"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNode makes a Node for a file in a temporary local VFS
+func newTestNode(t *testing.T, opt *mountlib.Options) *Node {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	VFS := vfs.New(f, nil)
+	t.Cleanup(VFS.Shutdown)
+	fh, err := VFS.OpenFile("file", os.O_CREATE|os.O_WRONLY, 0666)
+	require.NoError(t, err)
+	require.NoError(t, fh.Close())
+	node, err := VFS.Stat("file")
+	require.NoError(t, err)
+	return newNode(NewFS(VFS, opt), node)
+}
+
+func TestSetattrChmod(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		refuseChmod bool
+		valid       uint32
+		want        syscall.Errno
+	}{
+		{refuseChmod: false, valid: fuse.FATTR_MODE, want: 0},
+		{refuseChmod: false, valid: fuse.FATTR_UID | fuse.FATTR_GID, want: 0},
+		{refuseChmod: true, valid: fuse.FATTR_MODE, want: syscall.EPERM},
+		{refuseChmod: true, valid: fuse.FATTR_UID, want: syscall.EPERM},
+		{refuseChmod: true, valid: fuse.FATTR_GID, want: syscall.EPERM},
+	} {
+		opt := mountlib.Opt
+		opt.RefuseChmod = test.refuseChmod
+		n := newTestNode(t, &opt)
+		var in fuse.SetAttrIn
+		in.Valid = test.valid
+		in.Mode = 0600
+		var out fuse.AttrOut
+		assert.Equal(t, test.want, n.Setattr(ctx, nil, &in, &out), "refuseChmod=%v valid=%#x", test.refuseChmod, test.valid)
+	}
+}
+
+func TestSetattrModTime(t *testing.T) {
+	ctx := context.Background()
+	opt := mountlib.Opt
+	opt.RefuseChmod = true
+	n := newTestNode(t, &opt)
+
+	// utimes should be mapped onto SetModTime
+	mtime := time.Date(2012, time.November, 18, 17, 32, 31, 0, time.UTC)
+	var in fuse.SetAttrIn
+	in.Valid = fuse.FATTR_MTIME
+	in.Mtime = uint64(mtime.Unix())
+	var out fuse.AttrOut
+	assert.Equal(t, syscall.Errno(0), n.Setattr(ctx, nil, &in, &out))
+	assert.Equal(t, uint64(mtime.Unix()), out.Attr.Mtime)
+	assert.Equal(t, mtime.Unix(), n.node.ModTime().Unix())
+}
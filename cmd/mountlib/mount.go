@@ -118,6 +118,11 @@ var OptionsInfo = fs.Options{{
 	Default: fs.Tristate{},
 	Help:    "Tell the OS the mount is case insensitive (true) or sensitive (false) regardless of the backend (auto)",
 	Groups:  "Mount",
+}, {
+	Name:    "mount_chmod_error",
+	Default: false,
+	Help:    "Return a permission error for chmod and chown requests instead of ignoring them",
+	Groups:  "Mount",
 }, {
 	Name:    "direct_io",
 	Default: false,
@@ -189,6 +194,7 @@ type Options struct {
 	NetworkMode        bool          `config:"network_mode"` // Windows only
 	DirectIO           bool          `config:"direct_io"`    // use Direct IO for file access
 	CaseInsensitive    fs.Tristate   `config:"mount_case_insensitive"`
+	RefuseChmod        bool          `config:"mount_chmod_error"` // return an error for chmod/chown rather than ignoring them
 }
 
 type (
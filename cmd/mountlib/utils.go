@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
 )
 
 // ClipBlocks clips the blocks pointed to the OS max
@@ -34,6 +35,18 @@ func ClipBlocks(b *uint64) {
 	}
 }
 
+// ChmodError returns the error that the mount should return for a
+// chmod or chown request, which rclone has no way of storing.
+//
+// By default the request is ignored and nil is returned so tools like
+// "cp -p" work. With --mount-chmod-error it is refused with EPERM.
+func (opt *Options) ChmodError() error {
+	if opt.RefuseChmod {
+		return vfs.EPERM
+	}
+	return nil
+}
+
 // CheckOverlap checks that root doesn't overlap with a mountpoint
 func CheckOverlap(f fs.Fs, mountpoint string) error {
 	name := f.Name()
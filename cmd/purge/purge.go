@@ -16,10 +16,14 @@ func init() {
 var commandDefinition = &cobra.Command{
 	Use:   "purge remote:path",
 	Short: `Remove the path and all of its contents.`,
-	Long: `Remove the path and all of its contents.  Note that this does not obey
-include/exclude filters - everything will be removed.  Use the
-[delete](/commands/rclone_delete/) command if you want to selectively
-delete files. To delete empty directories only, use command
+	Long: `Remove the path and all of its contents.
+
+If include/exclude filters are in use then only the files matching
+them are removed, along with any directories left empty, rather than
+the whole path. The backend's native purge isn't used in this case as
+it can't filter, so this may be much slower. The
+[delete](/commands/rclone_delete/) command can also be used to
+selectively delete files. To delete empty directories only, use command
 [rmdir](/commands/rclone_rmdir/) or [rmdirs](/commands/rclone_rmdirs/).
 
 **Important**: Since this can cause data loss, test first with the
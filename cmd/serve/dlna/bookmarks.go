@@ -0,0 +1,84 @@
+package dlna
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// bookmarks stores the last playback positions of items keyed by
+// ObjectID for --bookmarks so renderers can resume playback.
+type bookmarks struct {
+	path      string           // the file the positions are persisted in
+	mu        sync.Mutex       // protects positions and writing the file
+	positions map[string]int64 // position in seconds keyed by ObjectID
+}
+
+// loadBookmarks reads the bookmarks stored in path. It is not an
+// error if path doesn't exist yet.
+func loadBookmarks(path string) (*bookmarks, error) {
+	b := &bookmarks{
+		path:      path,
+		positions: make(map[string]int64),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	if err := json.Unmarshal(data, &b.positions); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks %q: %w", path, err)
+	}
+	return b, nil
+}
+
+// get returns the playback position stored for id if any.
+//
+// It is safe to call on a nil bookmarks.
+func (b *bookmarks) get(id string) (pos time.Duration, ok bool) {
+	if b == nil {
+		return 0, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seconds, ok := b.positions[id]
+	return time.Duration(seconds) * time.Second, ok
+}
+
+// set stores the playback position for id, removing it if pos is 0,
+// and writes the bookmarks to the file.
+func (b *bookmarks) set(id string, pos time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if pos <= 0 {
+		delete(b.positions, id)
+	} else {
+		b.positions[id] = int64(pos / time.Second)
+	}
+	data, err := json.MarshalIndent(b.positions, "", "\t")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so the bookmarks aren't
+	// lost if writing fails part way through
+	tmpPath := b.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	if err := os.Rename(tmpPath, b.path); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// formatPlaybackPosition formats pos as H+:MM:SS as used by
+// upnp:lastPlaybackPosition
+func formatPlaybackPosition(pos time.Duration) string {
+	seconds := int64(pos / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
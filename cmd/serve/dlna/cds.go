@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/anacrolix/dms/dlna"
 	"github.com/anacrolix/dms/upnp"
@@ -31,6 +32,36 @@ func (cds *contentDirectoryService) updateIDString() string {
 
 var mediaMimeTypeRegexp = regexp.MustCompile("^(video|audio|image)/")
 
+// parseMediaTypes checks the --media-types and returns them as a set,
+// or nil if all media types should be served.
+func parseMediaTypes(types []string) (map[string]bool, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	mediaTypes := make(map[string]bool, len(types))
+	for _, mediaType := range types {
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		switch mediaType {
+		case "":
+			// ignore empty entries, e.g. from a trailing comma
+		case "audio", "video", "image":
+			mediaTypes[mediaType] = true
+		default:
+			return nil, fmt.Errorf("unknown --media-types %q: must be audio, video or image", mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		return nil, nil
+	}
+	return mediaTypes, nil
+}
+
+// serveMediaType returns true if media of mediaType (audio, video or
+// image) should be served.
+func (s *server) serveMediaType(mediaType string) bool {
+	return s.mediaTypes == nil || s.mediaTypes[mediaType]
+}
+
 // Turns the given entry and DMS host into a UPnP object. A nil object is
 // returned if the entry is not of interest.
 func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fileInfo vfs.Node, resources vfs.Nodes, host string) (ret interface{}, err error) {
@@ -54,22 +85,9 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 		return
 	}
 
-	// Read the mime type from the fs.Object if possible,
-	// otherwise fall back to working out what it is from the file path.
-	var mimeType string
-	if o, ok := fileInfo.DirEntry().(fs.Object); ok {
-		mimeType = fs.MimeType(context.TODO(), o)
-		// If backend doesn't know what the mime type is then
-		// try getting it from the file name
-		if mimeType == "application/octet-stream" {
-			mimeType = fs.MimeTypeFromName(fileInfo.Name())
-		}
-	} else {
-		mimeType = fs.MimeTypeFromName(fileInfo.Name())
-	}
-
+	mimeType := nodeMimeType(context.TODO(), fileInfo)
 	mediaType := mediaMimeTypeRegexp.FindStringSubmatch(mimeType)
-	if mediaType == nil {
+	if mediaType == nil || !cds.serveMediaType(mediaType[1]) {
 		return
 	}
 
@@ -81,8 +99,11 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 		Object: obj,
 		Res:    make([]upnpav.Resource, 0, 1),
 	}
+	if pos, ok := cds.bookmarks.get(obj.ID); ok {
+		item.LastPlaybackPosition = formatPlaybackPosition(pos)
+	}
 
-	item.Res = append(item.Res, upnpav.Resource{
+	res := upnpav.Resource{
 		URL: (&url.URL{
 			Scheme: "http",
 			Host:   host,
@@ -92,13 +113,18 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 			SupportRange: true,
 		}.String()),
 		Size: uint64(fileInfo.Size()),
-	})
+	}
+	// Renderers need the duration of audio for gapless playback
+	if mediaType[1] == "audio" {
+		res.Duration = cds.sidecarDuration(cdsObject.Path)
+	}
+	item.Res = append(item.Res, res)
 
 	for _, resource := range resources {
 		subtitleURL := (&url.URL{
 			Scheme: "http",
 			Host:   host,
-			Path:   path.Join(resPath, resource.Path()),
+			Path:   path.Join(resPath, cds.serverPath(cdsObject.Path, resource)),
 		}).String()
 
 		// Read the mime type from the fs.Object if possible,
@@ -114,6 +140,9 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 		} else {
 			mimeType = fs.MimeTypeFromName(resource.Name())
 		}
+		if cds.VTTToSRT && isVTT(resource.Name()) {
+			mimeType = srtMimeType
+		}
 
 		item.Res = append(item.Res, upnpav.Resource{
 			URL:          subtitleURL,
@@ -127,18 +156,62 @@ func (cds *contentDirectoryService) cdsObjectToUpnpavObject(cdsObject object, fi
 
 // Returns all the upnpav objects in a directory.
 func (cds *contentDirectoryService) readContainer(o object, host string) (ret []interface{}, err error) {
-	node, err := cds.vfs.Stat(o.Path)
+	switch {
+	case cds.Layout == layoutFlat && o.IsRoot():
+		return cds.readFlatContainer(host)
+	case cds.Layout == layoutDate && o.IsRoot():
+		return cds.readDateContainers()
+	case o.virtualID != "":
+		return cds.readDateContainer(o, host)
+	}
+
+	dirEntries, mediaResources, err := cds.listDir(o.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, de := range dirEntries {
+		child := object{
+			Path: path.Join(o.Path, de.Name()),
+		}
+		obj, err := cds.cdsObjectToUpnpavObject(child, de, mediaResources[de], host)
+		if err != nil {
+			fs.Errorf(cds, "error with %s: %s", child.FilePath(), err)
+			continue
+		}
+		if obj == nil {
+			fs.Debugf(cds, "unrecognized file type: %s", de)
+			continue
+		}
+		ret = append(ret, obj)
+	}
+
+	return
+}
+
+// Lists the directory at dirPath returning the potential media nodes
+// and directories in it, and any resources associated with them.
+func (cds *contentDirectoryService) listDir(dirPath string) (dirEntries vfs.Nodes, mediaResources map[vfs.Node]vfs.Nodes, err error) {
+	if len(cds.roots) > 0 && path.Clean(dirPath) == "/" {
+		// The remotes are the top level containers
+		return cds.rootNodes(), nil, nil
+	}
+
+	node, err := cds.stat(dirPath)
 	if err != nil {
 		return
 	}
 
-	if !node.IsDir() {
+	var dir *vfs.Dir
+	switch node := node.(type) {
+	case *vfs.Dir:
+		dir = node
+	case rootNode:
+		dir = node.Dir
+	default:
 		err = errors.New("not a directory")
 		return
 	}
-
-	dir := node.(*vfs.Dir)
-	dirEntries, err := dir.ReadDirAll()
+	dirEntries, err = dir.ReadDirAll()
 	if err != nil {
 		err = errors.New("failed to list directory")
 		return
@@ -152,30 +225,14 @@ func (cds *contentDirectoryService) readContainer(o object, host string) (ret []
 			subtitleEntries, err := subtitleDir.ReadDirAll()
 			if err != nil {
 				err = errors.New("failed to list subtitle directory")
-				return nil, err
+				return nil, nil, err
 			}
 			dirEntries = append(dirEntries, subtitleEntries...)
 		}
 	}
 
-	dirEntries, mediaResources := mediaWithResources(dirEntries)
-	for _, de := range dirEntries {
-		child := object{
-			path.Join(o.Path, de.Name()),
-		}
-		obj, err := cds.cdsObjectToUpnpavObject(child, de, mediaResources[de], host)
-		if err != nil {
-			fs.Errorf(cds, "error with %s: %s", child.FilePath(), err)
-			continue
-		}
-		if obj == nil {
-			fs.Debugf(cds, "unrecognized file type: %s", de)
-			continue
-		}
-		ret = append(ret, obj)
-	}
-
-	return
+	dirEntries, mediaResources = mediaWithResources(dirEntries)
+	return dirEntries, mediaResources, nil
 }
 
 // Given a list of nodes, separate them into potential media items and any associated resources (external subtitles,
@@ -228,6 +285,12 @@ func mediaWithResources(nodes vfs.Nodes) (vfs.Nodes, map[vfs.Node]vfs.Nodes) {
 	return media, mediaResources
 }
 
+// setBookmark is the arguments of the Samsung X_SetBookmark action
+type setBookmark struct {
+	ObjectID  string
+	PosSecond int64
+}
+
 type browse struct {
 	ObjectID       string
 	BrowseFlag     string
@@ -238,6 +301,13 @@ type browse struct {
 
 // ContentDirectory object from ObjectID.
 func (cds *contentDirectoryService) objectFromID(id string) (o object, err error) {
+	if cds.Layout == layoutDate && strings.HasPrefix(id, dateIDPrefix) {
+		if _, err = time.Parse(dateFormat, id[len(dateIDPrefix):]); err != nil {
+			return o, fmt.Errorf("bad ObjectID %v", id)
+		}
+		o.virtualID = id
+		return o, nil
+	}
 	o.Path, err = url.QueryUnescape(id)
 	if err != nil {
 		return
@@ -302,14 +372,23 @@ func (cds *contentDirectoryService) Handle(action string, argsXML []byte, r *htt
 				"UpdateID":       cds.updateIDString(),
 			}, nil
 		case "BrowseMetadata":
-			node, err := cds.vfs.Stat(obj.Path)
-			if err != nil {
-				return nil, err
-			}
-			// TODO: External subtitles won't appear in the metadata here, but probably should.
-			upnpObject, err := cds.cdsObjectToUpnpavObject(obj, node, vfs.Nodes{}, host)
-			if err != nil {
-				return nil, err
+			var upnpObject interface{}
+			if obj.virtualID != "" {
+				upnpObject, err = cds.dateContainer(obj.virtualID)
+				if err != nil {
+					return nil, upnp.Errorf(upnpav.NoSuchObjectErrorCode, "%s", err.Error())
+				}
+			} else {
+				node, err := cds.stat(obj.Path)
+				if err != nil {
+					return nil, err
+				}
+				obj.parentID = cds.layoutParentID(obj, node)
+				// TODO: External subtitles won't appear in the metadata here, but probably should.
+				upnpObject, err = cds.cdsObjectToUpnpavObject(obj, node, vfs.Nodes{}, host)
+				if err != nil {
+					return nil, err
+				}
 			}
 			result, err := xml.Marshal(upnpObject)
 			if err != nil {
@@ -339,7 +418,20 @@ func (cds *contentDirectoryService) Handle(action string, argsXML []byte, r *htt
 	</Feature>
 </Features>`}, nil
 	case "X_SetBookmark":
-		// just ignore
+		if cds.bookmarks == nil {
+			// just ignore
+			return map[string]string{}, nil
+		}
+		var bookmark setBookmark
+		if err := xml.Unmarshal(argsXML, &bookmark); err != nil {
+			return nil, err
+		}
+		if _, err := cds.objectFromID(bookmark.ObjectID); err != nil {
+			return nil, upnp.Errorf(upnpav.NoSuchObjectErrorCode, "%s", err.Error())
+		}
+		if err := cds.bookmarks.set(bookmark.ObjectID, time.Duration(bookmark.PosSecond)*time.Second); err != nil {
+			return nil, err
+		}
 		return map[string]string{}, nil
 	default:
 		return nil, upnp.InvalidActionError
@@ -348,7 +440,9 @@ func (cds *contentDirectoryService) Handle(action string, argsXML []byte, r *htt
 
 // Represents a ContentDirectory object.
 type object struct {
-	Path string // The cleaned, absolute path for the object relative to the server.
+	Path      string // The cleaned, absolute path for the object relative to the server.
+	virtualID string // The ObjectID of a virtual container - Path is unused if set.
+	parentID  string // The parent ObjectID if it can't be deduced from the Path.
 }
 
 // Returns the actual local filesystem path for the object.
@@ -358,6 +452,9 @@ func (o *object) FilePath() string {
 
 // Returns the ObjectID for the object. This is used in various ContentDirectory actions.
 func (o object) ID() string {
+	if o.virtualID != "" {
+		return o.virtualID
+	}
 	if !path.IsAbs(o.Path) {
 		fs.Panicf(nil, "Relative object path: %s", o.Path)
 	}
@@ -377,6 +474,12 @@ func (o object) ParentID() string {
 	if o.IsRoot() {
 		return "-1"
 	}
+	if o.parentID != "" {
+		return o.parentID
+	}
+	if o.virtualID != "" {
+		return "0"
+	}
 	o.Path = path.Dir(o.Path)
 	return o.ID()
 }
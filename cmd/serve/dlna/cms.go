@@ -2,11 +2,54 @@ package dlna
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/anacrolix/dms/upnp"
 )
 
-const defaultProtocolInfo = "http-get:*:video/mpeg:*,http-get:*:video/mp4:*,http-get:*:video/vnd.dlna.mpeg-tts:*,http-get:*:video/avi:*,http-get:*:video/x-matroska:*,http-get:*:video/x-ms-wmv:*,http-get:*:video/wtv:*,http-get:*:audio/mpeg:*,http-get:*:audio/mp3:*,http-get:*:audio/mp4:*,http-get:*:audio/x-ms-wma*,http-get:*:audio/wav:*,http-get:*:audio/L16:*,http-get:*image/jpeg:*,http-get:*image/png:*,http-get:*image/gif:*,http-get:*image/tiff:*"
+// dlnaProfiles lists the media types the server can stream along with
+// the DLNA.ORG_PN profiles which apply to them.
+//
+// Media types with no profiles are advertised with a wildcard so
+// renderers which don't insist on a profile will still accept them.
+var dlnaProfiles = []struct {
+	mimeType string
+	profiles []string
+}{
+	{"video/mpeg", []string{"MPEG1", "MPEG_PS_PAL", "MPEG_PS_NTSC"}},
+	{"video/mp4", []string{"AVC_MP4_BL_CIF15_AAC_520", "AVC_MP4_MP_SD_AAC_MULT5", "AVC_MP4_HP_HD_AAC"}},
+	{"video/vnd.dlna.mpeg-tts", []string{"MPEG_TS_SD_EU_T", "MPEG_TS_SD_NA_T", "MPEG_TS_HD_NA_T", "AVC_TS_MP_HD_AAC_MULT5_T"}},
+	{"video/avi", nil},
+	{"video/x-matroska", nil},
+	{"video/x-ms-wmv", []string{"WMVMED_BASE", "WMVMED_FULL", "WMVHIGH_FULL"}},
+	{"video/wtv", nil},
+	{"audio/mpeg", []string{"MP3", "MP3X"}},
+	{"audio/mp3", []string{"MP3"}},
+	{"audio/mp4", []string{"AAC_ISO", "AAC_ISO_320"}},
+	{"audio/x-ms-wma", []string{"WMABASE", "WMAFULL"}},
+	{"audio/wav", nil},
+	{"audio/L16", []string{"LPCM"}},
+	{"image/jpeg", []string{"JPEG_TN", "JPEG_SM", "JPEG_MED", "JPEG_LRG"}},
+	{"image/png", []string{"PNG_TN", "PNG_LRG"}},
+	{"image/gif", []string{"GIF_LRG"}},
+	{"image/tiff", nil},
+}
+
+// sourceProtocolInfo is the list of protocolInfo the server can act
+// as a source for, as returned by GetProtocolInfo
+var sourceProtocolInfo = makeSourceProtocolInfo()
+
+// makeSourceProtocolInfo builds the protocolInfo list from dlnaProfiles
+func makeSourceProtocolInfo() string {
+	var infos []string
+	for _, media := range dlnaProfiles {
+		infos = append(infos, "http-get:*:"+media.mimeType+":*")
+		for _, profile := range media.profiles {
+			infos = append(infos, "http-get:*:"+media.mimeType+":DLNA.ORG_PN="+profile)
+		}
+	}
+	return strings.Join(infos, ",")
+}
 
 type connectionManagerService struct {
 	*server
@@ -17,7 +60,7 @@ func (cms *connectionManagerService) Handle(action string, argsXML []byte, r *ht
 	switch action {
 	case "GetProtocolInfo":
 		return map[string]string{
-			"Source": defaultProtocolInfo,
+			"Source": sourceProtocolInfo,
 			"Sink":   "",
 		}, nil
 	default:
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -36,7 +38,7 @@ func init() {
 
 // Command definition for cobra.
 var Command = &cobra.Command{
-	Use:   "dlna remote:path",
+	Use:   "dlna remote:path [remote:path]...",
 	Short: `Serve remote:path over DLNA`,
 	Long: `Run a DLNA media server for media stored in an rclone remote. Many
 devices, such as the Xbox and PlayStation, can automatically discover
@@ -53,17 +55,35 @@ Rclone will add external subtitle files (.srt) to videos if they have the same
 filename as the video file itself (except the extension), either in the same
 directory as the video, or in a "Subs" subdirectory.
 
+Rclone will advertise the duration of an audio file, which some
+renderers need for seeking and gapless playback, if there is a
+".duration" file with the same filename next to it. This should
+contain the duration as "h:mm:ss.fff", a number of seconds or a value
+like "3m25.5s", for example "song.duration" for "song.mp3".
+
+If more than one remote:path is given then each is served as a top
+level container named after the last element of its path, or the
+remote name if the path is empty, with "-2", "-3" etc added to make the
+names unique.
+
 ` + dlnaflags.Help + vfs.Help(),
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.46",
 		"groups":            "Filter",
 	},
 	Run: func(command *cobra.Command, args []string) {
-		cmd.CheckArgs(1, 1, command, args)
-		f := cmd.NewFsSrc(args)
+		cmd.CheckArgs(1, 1e6, command, args)
+		var fses []fs.Fs
+		if len(args) == 1 {
+			fses = append(fses, cmd.NewFsSrc(args))
+		} else {
+			for _, arg := range args {
+				fses = append(fses, cmd.NewFsDir([]string{arg}))
+			}
+		}
 
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(f, &dlnaflags.Opt)
+			s, err := newMultiServer(fses, &dlnaflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -101,14 +121,52 @@ type server struct {
 	// For waiting on the listener to close
 	waitChan chan struct{}
 
+	// Cancelled when the server is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// Time interval between SSPD announces
 	AnnounceInterval time.Duration
 
+	// Extra SSDP notification types to announce
+	AnnounceTypes []string
+
+	// Whether to serve WebVTT subtitles as SRT
+	VTTToSRT bool
+
+	// How the containers are laid out - folder, flat or date
+	Layout string
+
 	f   fs.Fs
 	vfs *vfs.VFS
+
+	// The remotes served as top level containers if there is more
+	// than one - f and vfs are the first of them
+	roots []root
+
+	// Adapts media the client can't play - passthrough by default
+	remux remuxFunc
+
+	// Playback positions if --bookmarks is set
+	bookmarks *bookmarks
+
+	// The media types to serve if --media-types is set, or nil for all
+	mediaTypes map[string]bool
+
+	// Change events if --events-interval is set
+	events *events
+
+	// Durations read from the audio sidecars
+	durations *durationCache
 }
 
 func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
+	return newMultiServer([]fs.Fs{f}, opt)
+}
+
+// newMultiServer makes a server for fses, serving each as a top level
+// container if there is more than one.
+func newMultiServer(fses []fs.Fs, opt *dlnaflags.Options) (*server, error) {
 	friendlyName := opt.FriendlyName
 	if friendlyName == "" {
 		friendlyName = makeDefaultFriendlyName()
@@ -128,16 +186,46 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 	if len(interfaces) == 0 {
 		interfaces = listInterfaces()
 	}
+	if err := checkLayout(opt.Layout); err != nil {
+		return nil, err
+	}
+	mediaTypes, err := parseMediaTypes(opt.MediaTypes)
+	if err != nil {
+		return nil, err
+	}
 
 	s := &server{
 		AnnounceInterval: time.Duration(opt.AnnounceInterval),
+		AnnounceTypes:    opt.AnnounceTypes,
+		VTTToSRT:         opt.VTTToSRT,
+		Layout:           opt.Layout,
 		FriendlyName:     friendlyName,
 		RootDeviceUUID:   makeDeviceUUID(friendlyName),
 		Interfaces:       interfaces,
 		waitChan:         make(chan struct{}),
 		httpListenAddr:   opt.ListenAddr,
-		f:                f,
-		vfs:              vfs.New(f, &vfscommon.Opt),
+		remux:            passthroughRemux,
+		mediaTypes:       mediaTypes,
+		durations:        newDurationCache(time.Duration(vfscommon.Opt.DirCacheTime)),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if opt.EventsInterval > 0 {
+		s.events = newEvents(s, time.Duration(opt.EventsInterval))
+	}
+	if opt.Bookmarks != "" {
+		var err error
+		s.bookmarks, err = loadBookmarks(opt.Bookmarks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(fses) == 1 {
+		s.f = fses[0]
+		s.vfs = vfs.New(s.f, &vfscommon.Opt)
+	} else {
+		s.roots = newRoots(fses)
+		s.f = s.roots[0].f
+		s.vfs = s.roots[0].vfs
 	}
 
 	s.services = map[string]UPnPService{
@@ -163,6 +251,9 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 		r.HandleFunc(rootDescPath, s.rootDescHandler)
 		r.HandleFunc(serviceControlURL, s.serviceControlHandler)
 	}
+	if s.events != nil {
+		r.HandleFunc(eventsPath, s.eventsHandler)
+	}
 	r.Handle("/static/", http.StripPrefix("/static/",
 		withHeader("Cache-Control", "public, max-age=86400",
 			http.FileServer(data.Assets))))
@@ -261,23 +352,17 @@ func (s *server) soapActionResponse(sa upnp.SoapAction, actionRequestXML []byte,
 func (s *server) resourceHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	remotePath := r.URL.Path
-	node, err := s.vfs.Stat(r.URL.Path)
+	node, err := s.stat(r.URL.Path)
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
 
-	w.Header().Set("Content-Length", strconv.FormatInt(node.Size(), 10))
-
-	// add some DLNA specific headers
-	if r.Header.Get("getContentFeatures.dlna.org") != "" {
-		w.Header().Set("contentFeatures.dlna.org", dms_dlna.ContentFeatures{
-			SupportRange: true,
-		}.String())
+	file, ok := node.(*vfs.File)
+	if !ok {
+		http.NotFound(w, r)
+		return
 	}
-	w.Header().Set("transferMode.dlna.org", "Streaming")
-
-	file := node.(*vfs.File)
 	in, err := file.Open(os.O_RDONLY)
 	if err != nil {
 		serveError(ctx, node, w, "Could not open resource", err)
@@ -285,9 +370,59 @@ func (s *server) resourceHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer fs.CheckClose(in, &err)
 
+	// Convert WebVTT subtitles for renderers which only understand SRT
+	if s.VTTToSRT && isVTT(remotePath) {
+		srt, err := vttToSRT(in)
+		if err != nil {
+			serveError(ctx, node, w, "Could not convert subtitles", err)
+			return
+		}
+		w.Header().Set("Content-Type", srtMimeType)
+		setDLNAHeaders(w, r, true)
+		http.ServeContent(w, r, remotePath, node.ModTime(), bytes.NewReader(srt))
+		return
+	}
+
+	// Remux the media if the client says it can't play it
+	mimeType := nodeMimeType(ctx, node)
+	if caps := clientCapsFromRequest(r); !caps.canPlay(mimeType) {
+		out, outMimeType, err := s.remux(ctx, in, mimeType, caps)
+		if err != nil {
+			serveError(ctx, node, w, "Could not remux resource", err)
+			return
+		}
+		if out != nil {
+			defer fs.CheckClose(out, &err)
+			// The remuxed size isn't known in advance so ranges can't be supported
+			setDLNAHeaders(w, r, false)
+			w.Header().Set("Content-Type", outMimeType)
+			if r.Method == http.MethodHead {
+				return
+			}
+			_, err = io.Copy(w, out)
+			if err != nil {
+				fs.Errorf(node, "Failed to stream remuxed resource: %v", err)
+			}
+			return
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(node.Size(), 10))
+	setDLNAHeaders(w, r, true)
+
 	http.ServeContent(w, r, remotePath, node.ModTime(), in)
 }
 
+// add some DLNA specific headers
+func setDLNAHeaders(w http.ResponseWriter, r *http.Request, supportRange bool) {
+	if r.Header.Get("getContentFeatures.dlna.org") != "" {
+		w.Header().Set("contentFeatures.dlna.org", dms_dlna.ContentFeatures{
+			SupportRange: supportRange,
+		}.String())
+	}
+	w.Header().Set("transferMode.dlna.org", "Streaming")
+}
+
 // Serve runs the server - returns the error only if
 // the listener was not started; does not block, so
 // use s.Wait() to block on the listener indefinitely.
@@ -328,6 +463,7 @@ func (s *server) Wait() {
 }
 
 func (s *server) Close() {
+	s.cancel()
 	err := s.HTTPConn.Close()
 	if err != nil {
 		fs.Errorf(s.f, "Error closing HTTP server: %v", err)
@@ -355,6 +491,33 @@ func (s *server) startSSDP() {
 	}
 }
 
+// ssdpTypes returns the device and service types to announce with
+// SSDP, including any extra types asked for with --announce-types.
+//
+// Note that the default devices and services should be in agreement
+// with the rootDesc XML descriptor.
+func (s *server) ssdpTypes() (devices, services []string) {
+	devices = []string{
+		"urn:schemas-upnp-org:device:MediaServer:1",
+	}
+	services = []string{
+		"urn:schemas-upnp-org:service:ContentDirectory:1",
+		"urn:schemas-upnp-org:service:ConnectionManager:1",
+		"urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1",
+	}
+	for _, nt := range s.AnnounceTypes {
+		if slices.Contains(devices, nt) || slices.Contains(services, nt) {
+			continue
+		}
+		if strings.Contains(nt, ":device:") {
+			devices = append(devices, nt)
+		} else {
+			services = append(services, nt)
+		}
+	}
+	return devices, services
+}
+
 // Run SSDP server on an interface.
 func (s *server) ssdpInterface(intf net.Interface) {
 	// Figure out whether should an ip be announced
@@ -400,16 +563,11 @@ func (s *server) ssdpInterface(intf net.Interface) {
 	}
 	fs.Logf(s, "Started SSDP on %v", intf.Name)
 
-	// Note that the devices and services advertised here via SSDP should be
-	// in agreement with the rootDesc XML descriptor that is defined above.
+	devices, services := s.ssdpTypes()
 	ssdpServer := ssdp.Server{
-		Interface: intf,
-		Devices: []string{
-			"urn:schemas-upnp-org:device:MediaServer:1"},
-		Services: []string{
-			"urn:schemas-upnp-org:service:ContentDirectory:1",
-			"urn:schemas-upnp-org:service:ConnectionManager:1",
-			"urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1"},
+		Interface:      intf,
+		Devices:        devices,
+		Services:       services,
 		IPFilter:       ipfilterFn,
 		Location:       advertiseLocationFn,
 		Server:         serverField,
@@ -1,15 +1,20 @@
 package dlna
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/anacrolix/dms/soap"
 
@@ -18,6 +23,7 @@ import (
 
 	_ "github.com/rclone/rclone/backend/local"
 	"github.com/rclone/rclone/cmd/serve/dlna/dlnaflags"
+	"github.com/rclone/rclone/cmd/serve/dlna/upnpav"
 	"github.com/rclone/rclone/fs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +59,32 @@ func TestInit(t *testing.T) {
 	startServer(t, f)
 }
 
+// Check the SSDP announce options are used
+func TestSSDPAnnounceOptions(t *testing.T) {
+	opt := dlnaflags.Opt
+	opt.AnnounceInterval = fs.Duration(30 * time.Second)
+	opt.AnnounceTypes = []string{
+		"urn:schemas-upnp-org:device:MediaServer:2",
+		"urn:schemas-upnp-org:service:ContentDirectory:2",
+		"urn:schemas-upnp-org:service:ContentDirectory:1", // duplicate of a default
+	}
+	s, err := newServer(dlnaServer.f, &opt)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, s.AnnounceInterval)
+
+	devices, services := s.ssdpTypes()
+	assert.Equal(t, []string{
+		"urn:schemas-upnp-org:device:MediaServer:1",
+		"urn:schemas-upnp-org:device:MediaServer:2",
+	}, devices)
+	assert.Equal(t, []string{
+		"urn:schemas-upnp-org:service:ContentDirectory:1",
+		"urn:schemas-upnp-org:service:ConnectionManager:1",
+		"urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1",
+		"urn:schemas-upnp-org:service:ContentDirectory:2",
+	}, services)
+}
+
 // Make sure that it serves rootDesc.xml (SCPD in uPnP parlance).
 func TestRootSCPD(t *testing.T) {
 	req, err := http.NewRequest("GET", baseURL+rootDescPath, nil)
@@ -96,6 +128,466 @@ func TestServeContent(t *testing.T) {
 	require.Equal(t, goldenContents, actualContents)
 }
 
+// Make sure that the default passthrough remux serves the original
+// content even if the client says it can't play it.
+func TestServeContentPassthrough(t *testing.T) {
+	req, err := http.NewRequest("GET", baseURL+resPath+"video.mp4", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "video/x-matroska, audio/*;q=0.8")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer fs.CheckClose(resp.Body, &err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	actualContents, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	goldenContents, err := os.ReadFile("testdata/files/video.mp4")
+	require.NoError(t, err)
+	require.Equal(t, goldenContents, actualContents)
+}
+
+// Make sure that a remux function is used when the client can't play
+// the content.
+func TestServeContentRemux(t *testing.T) {
+	s := &server{
+		vfs: dlnaServer.vfs,
+		remux: func(ctx context.Context, in io.Reader, mimeType string, caps clientCaps) (io.ReadCloser, string, error) {
+			assert.Equal(t, "video/mp4", mimeType)
+			return io.NopCloser(strings.NewReader("remuxed")), "video/x-matroska", nil
+		},
+	}
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/video.mp4", nil)
+		req.URL.Path = "video.mp4" // as passed by http.StripPrefix
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		s.resourceHandler(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	w := get("video/x-matroska")
+	assert.Equal(t, "video/x-matroska", w.Header().Get("Content-Type"))
+	assert.Equal(t, "remuxed", w.Body.String())
+
+	// Clients which can play the original don't get it remuxed
+	w = get("video/*")
+	assert.Equal(t, "video/mp4", w.Header().Get("Content-Type"))
+	assert.NotEqual(t, "remuxed", w.Body.String())
+}
+
+func TestClientCaps(t *testing.T) {
+	for _, test := range []struct {
+		accept   string
+		mimeType string
+		want     bool
+	}{
+		{"", "video/mp4", true},
+		{"*/*", "video/mp4", true},
+		{"video/mp4", "video/mp4", true},
+		{"video/*", "video/mp4", true},
+		{"video/x-matroska", "video/mp4", false},
+		{"audio/*, video/x-matroska;q=0.9", "video/mp4", false},
+		{"audio/*, video/mp4;q=0.9", "video/mp4", true},
+		{"audio/mpeg, */*;q=0.1", "video/mp4", true},
+	} {
+		r, err := http.NewRequest("GET", "http://example.com/", nil)
+		require.NoError(t, err)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		got := clientCapsFromRequest(r).canPlay(test.mimeType)
+		assert.Equal(t, test.want, got, fmt.Sprintf("accept=%q mimeType=%q", test.accept, test.mimeType))
+	}
+}
+
+func TestVTTToSRT(t *testing.T) {
+	const vtt = "\ufeffWEBVTT - sample\r\n" +
+		"Kind: captions\r\n" +
+		"\r\n" +
+		"STYLE\r\n" +
+		"::cue { color: yellow }\r\n" +
+		"\r\n" +
+		"NOTE this is a comment\r\n" +
+		"\r\n" +
+		"intro\r\n" +
+		"00:01.000 --> 00:04.500 align:start position:10%\r\n" +
+		"<v Roger>Hello <b>there</b> &amp; welcome\r\n" +
+		"\r\n" +
+		"01:02:03.040 --> 01:02:05.000\r\n" +
+		"<c.loud>Line one</c>\r\n" +
+		"<i>Line two</i>\r\n"
+	const want = "1\n" +
+		"00:00:01,000 --> 00:00:04,500\n" +
+		"Hello <b>there</b> & welcome\n" +
+		"\n" +
+		"2\n" +
+		"01:02:03,040 --> 01:02:05,000\n" +
+		"Line one\n" +
+		"<i>Line two</i>\n" +
+		"\n"
+	got, err := vttToSRT(strings.NewReader(vtt))
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+
+	_, err = vttToSRT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nNot VTT\n"))
+	assert.Error(t, err)
+	_, err = vttToSRT(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+// Check --layout flat and date present all the media in the tree
+func TestLayout(t *testing.T) {
+	opt := dlnaflags.Opt
+	opt.Layout = "bad"
+	_, err := newServer(dlnaServer.f, &opt)
+	assert.Error(t, err)
+
+	wantPaths := []string{
+		"/r/small_jpeg.jpg",
+		"/r/subdir/video.mp4",
+		"/r/subdir2/video.mp4",
+		"/r/subdir3/video.mp4",
+		"/r/video.mp4",
+	}
+	itemPaths := func(objs []interface{}, parentID string) (paths []string) {
+		for _, obj := range objs {
+			item, ok := obj.(upnpav.Item)
+			require.True(t, ok, "expecting only items, got %T", obj)
+			assert.Equal(t, parentID, item.ParentID)
+			paths = append(paths, strings.TrimPrefix(item.Res[0].URL, "http://localhost"))
+		}
+		return paths
+	}
+
+	opt.Layout = "flat"
+	s, err := newServer(dlnaServer.f, &opt)
+	require.NoError(t, err)
+	cds := s.services["ContentDirectory"].(*contentDirectoryService)
+	objs, err := cds.readContainer(object{Path: "/"}, "localhost")
+	require.NoError(t, err)
+	assert.Equal(t, wantPaths, itemPaths(objs, "0"))
+
+	opt.Layout = "date"
+	s, err = newServer(dlnaServer.f, &opt)
+	require.NoError(t, err)
+	cds = s.services["ContentDirectory"].(*contentDirectoryService)
+	months, err := cds.readContainer(object{Path: "/"}, "localhost")
+	require.NoError(t, err)
+	require.NotEmpty(t, months)
+	var paths []string
+	for _, month := range months {
+		container, ok := month.(upnpav.Container)
+		require.True(t, ok, "expecting only containers, got %T", month)
+		assert.Equal(t, "0", container.ParentID)
+		o, err := cds.objectFromID(container.ID)
+		require.NoError(t, err)
+		objs, err := cds.readContainer(o, "localhost")
+		require.NoError(t, err)
+		assert.Equal(t, *container.ChildCount, len(objs))
+		paths = append(paths, itemPaths(objs, container.ID)...)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, wantPaths, paths)
+}
+
+// Check --bookmarks stores playback positions and returns them in the metadata
+func TestBookmarks(t *testing.T) {
+	opt := dlnaflags.Opt
+	opt.Bookmarks = t.TempDir() + "/bookmarks.json"
+	s, err := newServer(dlnaServer.f, &opt)
+	require.NoError(t, err)
+	cds := s.services["ContentDirectory"].(*contentDirectoryService)
+	r := httptest.NewRequest("POST", serviceControlURL, nil)
+
+	browseMetadata := func(cds *contentDirectoryService) string {
+		result, err := cds.Handle("Browse", []byte(`<u:Browse xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">
+	<ObjectID>%2Fvideo.mp4</ObjectID>
+	<BrowseFlag>BrowseMetadata</BrowseFlag>
+</u:Browse>`), r)
+		require.NoError(t, err)
+		return result["Result"]
+	}
+	setBookmark := func(objectID string, pos int) error {
+		_, err := cds.Handle("X_SetBookmark", []byte(fmt.Sprintf(`<u:X_SetBookmark xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">
+	<CategoryType>VIDEO</CategoryType>
+	<RID>0</RID>
+	<ObjectID>%s</ObjectID>
+	<PosSecond>%d</PosSecond>
+</u:X_SetBookmark>`, objectID, pos)), r)
+		return err
+	}
+
+	assert.NotContains(t, browseMetadata(cds), "lastPlaybackPosition")
+	require.NoError(t, setBookmark("%2Fvideo.mp4", 3725))
+	assert.Contains(t, browseMetadata(cds), "<upnp:lastPlaybackPosition>1:02:05</upnp:lastPlaybackPosition>")
+	assert.Error(t, setBookmark("bad", 10))
+
+	// The positions are persisted
+	s, err = newServer(dlnaServer.f, &opt)
+	require.NoError(t, err)
+	cds2 := s.services["ContentDirectory"].(*contentDirectoryService)
+	assert.Contains(t, browseMetadata(cds2), "<upnp:lastPlaybackPosition>1:02:05</upnp:lastPlaybackPosition>")
+
+	// Setting the position to 0 clears it
+	require.NoError(t, setBookmark("%2Fvideo.mp4", 0))
+	assert.NotContains(t, browseMetadata(cds), "lastPlaybackPosition")
+}
+
+// Check --media-types only serves the types of media asked for
+func TestMediaTypes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"song.mp3", "tune.flac", "movie.mp4", "photo.jpg", "notes.txt", "album/track.mp3", "album/cover.jpg"} {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(dir, name)), 0777))
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(name), 0666))
+	}
+	f, err := fs.NewFs(ctx, dir)
+	require.NoError(t, err)
+
+	opt := dlnaflags.Opt
+	opt.MediaTypes = fs.CommaSepList{"bad"}
+	_, err = newServer(f, &opt)
+	assert.Error(t, err)
+
+	list := func(layout string, types ...string) (titles []string) {
+		opt.Layout = layout
+		opt.MediaTypes = types
+		s, err := newServer(f, &opt)
+		require.NoError(t, err)
+		cds := s.services["ContentDirectory"].(*contentDirectoryService)
+		objs, err := cds.readContainer(object{Path: "/"}, "localhost")
+		require.NoError(t, err)
+		for _, obj := range objs {
+			switch obj := obj.(type) {
+			case upnpav.Item:
+				titles = append(titles, obj.Title)
+			case upnpav.Container:
+				titles = append(titles, obj.Title+"/")
+			}
+		}
+		sort.Strings(titles)
+		return titles
+	}
+
+	assert.Equal(t, []string{"album/", "movie.mp4", "photo.jpg", "song.mp3", "tune.flac"}, list("folder"))
+	assert.Equal(t, []string{"album/", "song.mp3", "tune.flac"}, list("folder", "audio"))
+	assert.Equal(t, []string{"album/", "song.mp3", "tune.flac"}, list("folder", " Audio ", ""))
+	assert.Equal(t, []string{"album/", "movie.mp4", "photo.jpg", "song.mp3", "tune.flac"}, list("folder", ""))
+	assert.Equal(t, []string{"album/", "movie.mp4", "photo.jpg"}, list("folder", "video", "image"))
+	assert.Equal(t, []string{"song.mp3", "track.mp3", "tune.flac"}, list("flat", "audio"))
+}
+
+// Check audio items advertise the duration from their sidecar
+func TestAudioDuration(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"song.mp3":      "song",
+		"song.duration": "3:25.5\n",
+		"tune.flac":     "tune",
+		"bad.mp3":       "bad",
+		"bad.duration":  "potato",
+	} {
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(contents), 0666))
+	}
+	f, err := fs.NewFs(ctx, dir)
+	require.NoError(t, err)
+	opt := dlnaflags.Opt
+	s, err := newServer(f, &opt)
+	require.NoError(t, err)
+	cds := s.services["ContentDirectory"].(*contentDirectoryService)
+	r := httptest.NewRequest("POST", serviceControlURL, nil)
+
+	result, err := cds.Handle("Browse", []byte(`<u:Browse xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">
+	<ObjectID>0</ObjectID>
+	<BrowseFlag>BrowseDirectChildren</BrowseFlag>
+</u:Browse>`), r)
+	require.NoError(t, err)
+	assert.Equal(t, "3", result["NumberReturned"])
+	assert.Equal(t, 1, strings.Count(result["Result"], "duration="))
+	assert.Contains(t, result["Result"], `duration="0:03:25.500"`)
+
+	for in, want := range map[string]time.Duration{
+		"205.5":     205500 * time.Millisecond,
+		"3m25.5s":   205500 * time.Millisecond,
+		"1:02:05":   3725 * time.Second,
+		"03:25.500": 205500 * time.Millisecond,
+	} {
+		got, err := parseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"1:-30", "-1:30", "1:60", "1:60:00", "1:00:60.5", "1:NaN", "1:2:3:4"} {
+		_, err := parseDuration(in)
+		assert.Error(t, err, in)
+	}
+	assert.Equal(t, "1:02:05.250", formatDuration(3725250*time.Millisecond))
+}
+
+// Check the sidecar durations are only read again once they expire
+func TestDurationCache(t *testing.T) {
+	reads := 0
+	read := func() string {
+		reads++
+		return fmt.Sprintf("0:00:0%d.000", reads)
+	}
+
+	c := newDurationCache(time.Hour)
+	assert.Equal(t, "0:00:01.000", c.get("song.duration", read))
+	assert.Equal(t, "0:00:01.000", c.get("song.duration", read))
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, "0:00:02.000", c.get("tune.duration", read))
+	assert.Equal(t, 2, reads)
+
+	c = newDurationCache(0)
+	assert.Equal(t, "0:00:03.000", c.get("song.duration", read))
+	assert.Equal(t, "0:00:04.000", c.get("song.duration", read))
+	assert.Equal(t, 4, reads)
+}
+
+// Check that more than one remote can be served as top level containers
+func TestMultiRoot(t *testing.T) {
+	ctx := context.Background()
+	var fses []fs.Fs
+	for _, remote := range []string{"testdata/files/subdir2", "testdata/files/subdir3"} {
+		f, err := fs.NewFs(ctx, remote)
+		require.NoError(t, err)
+		fses = append(fses, f)
+	}
+	opt := dlnaflags.Opt
+	s, err := newMultiServer(fses, &opt)
+	require.NoError(t, err)
+	cds := s.services["ContentDirectory"].(*contentDirectoryService)
+
+	// The remotes are the children of the root
+	objs, err := cds.readContainer(object{Path: "/"}, "localhost")
+	require.NoError(t, err)
+	var ids []string
+	for _, obj := range objs {
+		container, ok := obj.(upnpav.Container)
+		require.True(t, ok, "expecting only containers, got %T", obj)
+		assert.Equal(t, "0", container.ParentID)
+		ids = append(ids, container.ID)
+	}
+	assert.Equal(t, []string{"%2Fsubdir2", "%2Fsubdir3"}, ids)
+
+	// Their contents come from the right remote
+	for _, name := range []string{"subdir2", "subdir3"} {
+		o, err := cds.objectFromID("%2F" + name)
+		require.NoError(t, err)
+		objs, err := cds.readContainer(o, "localhost")
+		require.NoError(t, err)
+		var items []upnpav.Item
+		for _, obj := range objs {
+			if item, ok := obj.(upnpav.Item); ok {
+				items = append(items, item)
+			}
+		}
+		require.Len(t, items, 1)
+		item := items[0]
+		assert.Equal(t, "%2F"+name+"%2Fvideo.mp4", item.ID)
+		assert.Equal(t, "%2F"+name, item.ParentID)
+		assert.Equal(t, "http://localhost/r/"+name+"/video.mp4", item.Res[0].URL)
+		for _, res := range item.Res[1:] {
+			assert.True(t, strings.HasPrefix(res.URL, "http://localhost/r/"+name+"/Subs/"), res.URL)
+		}
+
+		req := httptest.NewRequest("GET", "/"+name+"/video.mp4", nil)
+		req.URL.Path = name + "/video.mp4" // as passed by http.StripPrefix
+		w := httptest.NewRecorder()
+		s.resourceHandler(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		want, err := os.ReadFile("testdata/files/" + name + "/video.mp4")
+		require.NoError(t, err)
+		assert.Equal(t, want, w.Body.Bytes())
+	}
+
+	// Unknown remotes aren't found
+	w := httptest.NewRecorder()
+	s.resourceHandler(w, httptest.NewRequest("GET", "/missing/video.mp4", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Names are made unique
+	roots := newRoots([]fs.Fs{fses[0], fses[0]})
+	assert.Equal(t, "subdir2", roots[0].name)
+	assert.Equal(t, "subdir2-2", roots[1].name)
+}
+
+// Check --events-interval sends events for added and removed media
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "old.mp4"), []byte("old"), 0666))
+	f, err := fs.NewFs(ctx, dir)
+	require.NoError(t, err)
+	opt := dlnaflags.Opt
+	opt.EventsInterval = fs.Duration(10 * time.Millisecond)
+	s, err := newServer(f, &opt)
+	require.NoError(t, err)
+	server := httptest.NewServer(s.handler)
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+eventsPath, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var event string
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return event
+			}
+			event += line
+		}
+	}
+
+	// Files which aren't media are ignored
+	require.NoError(t, os.WriteFile(path.Join(dir, "notes.txt"), []byte("notes"), 0666))
+	require.NoError(t, os.WriteFile(path.Join(dir, "new.mp4"), []byte("new"), 0666))
+	assert.Equal(t, "event: added\ndata: {\"type\":\"added\",\"path\":\"/new.mp4\"}\n", readEvent())
+
+	require.NoError(t, os.Remove(path.Join(dir, "old.mp4")))
+	assert.Equal(t, "event: removed\ndata: {\"type\":\"removed\",\"path\":\"/old.mp4\"}\n", readEvent())
+
+	// The endpoint isn't served without --events-interval
+	w := httptest.NewRecorder()
+	dlnaServer.handler.ServeHTTP(w, httptest.NewRequest("GET", eventsPath, nil))
+	assert.NotEqual(t, http.StatusOK, w.Code)
+}
+
+// Check polling for events stops when the server is closed
+func TestEventsStopOnClose(t *testing.T) {
+	f, err := fs.NewFs(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	opt := dlnaflags.Opt
+	opt.EventsInterval = fs.Duration(10 * time.Millisecond)
+	s, err := newServer(f, &opt)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		s.events.poll(make(chan struct{}), nil)
+		close(done)
+	}()
+	s.cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("polling didn't stop when the server was closed")
+	}
+}
+
 // Check that ContentDirectory#Browse returns appropriate metadata on the root container.
 func TestContentDirectoryBrowseMetadata(t *testing.T) {
 	// Sample from: https://github.com/rclone/rclone/issues/3253#issuecomment-524317469
@@ -150,6 +642,39 @@ func TestMediaReceiverRegistrarService(t *testing.T) {
 	require.Contains(t, string(body), "<RegistrationRespMsg>")
 }
 
+// Check that ConnectionManager#GetProtocolInfo advertises the media
+// in testdata.
+func TestConnectionManagerGetProtocolInfo(t *testing.T) {
+	env := soap.Envelope{
+		Body: soap.Body{
+			Action: []byte("GetProtocolInfo"),
+		},
+	}
+	req, err := http.NewRequest("POST", baseURL+serviceControlURL, bytes.NewReader(mustMarshalXML(env)))
+	require.NoError(t, err)
+	req.Header.Set("SOAPACTION", `"urn:schemas-upnp-org:service:ConnectionManager:1#GetProtocolInfo"`)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	source := string(body)
+	source = source[strings.Index(source, "<Source>")+len("<Source>") : strings.Index(source, "</Source>")]
+	infos := strings.Split(source, ",")
+	for _, want := range []string{
+		"http-get:*:video/mp4:*",
+		"http-get:*:video/mp4:DLNA.ORG_PN=AVC_MP4_BL_CIF15_AAC_520",
+		"http-get:*:image/jpeg:*",
+		"http-get:*:image/jpeg:DLNA.ORG_PN=JPEG_LRG",
+	} {
+		assert.Contains(t, infos, want)
+	}
+	// Every entry should be a well formed protocolInfo
+	for _, info := range infos {
+		assert.Len(t, strings.Split(info, ":"), 4, info)
+	}
+}
+
 // Check that ContentDirectory#Browse returns the expected items.
 func TestContentDirectoryBrowseDirectChildren(t *testing.T) {
 	// First the root...
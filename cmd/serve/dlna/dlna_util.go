@@ -15,6 +15,7 @@ import (
 	"github.com/anacrolix/dms/soap"
 	"github.com/anacrolix/dms/upnp"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
 )
 
 // Return a default "friendly name" for the server.
@@ -119,6 +120,12 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the underlying ResponseWriter so that
+// http.ResponseController can flush it.
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 // HTTP handler that logs requests and any errors or panics.
 func logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -190,6 +197,22 @@ func serveError(ctx context.Context, what interface{}, w http.ResponseWriter, te
 	http.Error(w, text+".", http.StatusInternalServerError)
 }
 
+// Read the mime type from the fs.Object if possible,
+// otherwise fall back to working out what it is from the file path.
+func nodeMimeType(ctx context.Context, node vfs.Node) (mimeType string) {
+	if o, ok := node.DirEntry().(fs.Object); ok {
+		mimeType = fs.MimeType(ctx, o)
+		// If backend doesn't know what the mime type is then
+		// try getting it from the file name
+		if mimeType == "application/octet-stream" {
+			mimeType = fs.MimeTypeFromName(node.Name())
+		}
+	} else {
+		mimeType = fs.MimeTypeFromName(node.Name())
+	}
+	return mimeType
+}
+
 // Splits a path into (root, ext) such that root + ext == path, and ext is empty
 // or begins with a period.  Extended version of path.Ext().
 func splitExt(path string) (string, string) {
@@ -22,6 +22,47 @@ default "rclone (hostname)".
 Use ` + "`--log-trace` in conjunction with `-vv`" + ` to enable additional debug
 logging of all UPNP traffic.
 
+Use ` + "`--announce-interval`" + ` to set how often the server re-announces
+itself with SSDP alive notifications. Some renderers drop servers from
+their list if they don't hear from them often enough.
+
+Use ` + "`--announce-types`" + ` to announce extra SSDP notification types, e.g.
+` + "`--announce-types urn:schemas-upnp-org:device:MediaServer:2`" + `, for
+renderers which only discover particular types. Types containing
+` + "`:device:`" + ` are announced as devices and others as services.
+
+Use ` + "`--vtt-to-srt`" + ` to convert WebVTT (.vtt) subtitles to SRT as they
+are served, for renderers which can only display SRT subtitles. Cue
+settings and styling which SRT doesn't support are dropped.
+
+Use ` + "`--layout`" + ` to choose how media is presented to renderers. The
+default, ` + "`folder`" + `, mirrors the directory tree. ` + "`flat`" + ` lists all the
+media in the tree directly under the root and ` + "`date`" + ` groups it into a
+container per month it was modified in, newest first. Listing the root
+with ` + "`flat` or `date`" + ` walks the whole tree so can be slow on large
+remotes.
+
+Use ` + "`--bookmarks`" + ` to name a file to store playback positions in.
+Renderers which set bookmarks, such as Samsung TVs, can then resume
+playback where it was left off. The positions are returned in the
+` + "`upnp:lastPlaybackPosition`" + ` of each item. This is off by default.
+
+Use ` + "`--media-types`" + ` to only serve some kinds of media, e.g.
+` + "`--media-types audio`" + ` for a music server. It takes a comma separated
+list of ` + "`audio`, `video` and `image`" + `. Files of other types are
+hidden from browsing. The default is to serve all of them.
+
+Use ` + "`--events-interval`" + ` to let other programs, such as home
+automation, know when media is added or removed. The server then
+offers a stream of [server-sent
+events](https://html.spec.whatwg.org/multipage/server-sent-events.html)
+at ` + "`/events`" + `. While a client is connected it lists the remote
+this often and sends an ` + "`added` or `removed`" + ` event for each media
+file which changed, with JSON data like
+` + "`{\"type\":\"added\",\"path\":\"/Films/new.mkv\"}`" + `. Listing
+walks the whole remote so don't set it too short on large remotes.
+This is off by default.
+
 `
 
 // OptionsInfo descripts the Options in use
@@ -45,6 +86,30 @@ var OptionsInfo = fs.Options{{
 	Name:    "announce_interval",
 	Default: fs.Duration(12 * time.Minute),
 	Help:    "The interval between SSDP announcements",
+}, {
+	Name:    "announce_types",
+	Default: []string{},
+	Help:    "Extra SSDP notification types to announce (repeat as necessary)",
+}, {
+	Name:    "vtt_to_srt",
+	Default: false,
+	Help:    "Convert WebVTT subtitles to SRT when serving them",
+}, {
+	Name:    "layout",
+	Default: "folder",
+	Help:    "Container layout to present: folder, flat or date",
+}, {
+	Name:    "bookmarks",
+	Default: "",
+	Help:    "File to store playback positions in so playback can be resumed",
+}, {
+	Name:    "media_types",
+	Default: fs.CommaSepList{},
+	Help:    "Only serve media of these types: audio, video or image (comma separated)",
+}, {
+	Name:    "events_interval",
+	Default: fs.Duration(0),
+	Help:    "Poll for added or removed media this often and report it on /events (0 to disable)",
 }}
 
 func init() {
@@ -53,11 +118,17 @@ func init() {
 
 // Options is the type for DLNA serving options.
 type Options struct {
-	ListenAddr       string      `config:"addr"`
-	FriendlyName     string      `config:"name"`
-	LogTrace         bool        `config:"log_trace"`
-	InterfaceNames   []string    `config:"interface"`
-	AnnounceInterval fs.Duration `config:"announce_interval"`
+	ListenAddr       string          `config:"addr"`
+	FriendlyName     string          `config:"name"`
+	LogTrace         bool            `config:"log_trace"`
+	InterfaceNames   []string        `config:"interface"`
+	AnnounceInterval fs.Duration     `config:"announce_interval"`
+	AnnounceTypes    []string        `config:"announce_types"`
+	VTTToSRT         bool            `config:"vtt_to_srt"`
+	Layout           string          `config:"layout"`
+	Bookmarks        string          `config:"bookmarks"`
+	MediaTypes       fs.CommaSepList `config:"media_types"`
+	EventsInterval   fs.Duration     `config:"events_interval"`
 }
 
 // Opt contains the options for DLNA serving.
@@ -0,0 +1,150 @@
+package dlna
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// durationExt is the extension of the sidecar files giving the
+// duration of an audio file, eg song.duration for song.mp3
+const durationExt = ".duration"
+
+// parseDuration parses the contents of a duration sidecar which may
+// be [h:]mm:ss[.fff], a number of seconds or a Go duration like
+// 3m25.5s.
+//
+// In the [h:]mm:ss form every field after the first must be less
+// than 60 and none may be negative.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("bad duration %q", s)
+		}
+		seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad duration %q: %w", s, err)
+		}
+		if !(seconds >= 0 && seconds < 60) {
+			return 0, fmt.Errorf("bad duration %q: seconds out of range", s)
+		}
+		d := time.Duration(seconds * float64(time.Second))
+		unit := time.Minute
+		for i := len(parts) - 2; i >= 0; i-- {
+			n, err := strconv.ParseUint(parts[i], 10, 32)
+			if err != nil {
+				return 0, fmt.Errorf("bad duration %q: %w", s, err)
+			}
+			if i > 0 && n >= 60 {
+				return 0, fmt.Errorf("bad duration %q: minutes out of range", s)
+			}
+			d += time.Duration(n) * unit
+			unit = time.Hour
+		}
+		return d, nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// formatDuration formats d as a DIDL-Lite duration H:MM:SS.FFF
+func formatDuration(d time.Duration) string {
+	ms := int64(d / time.Millisecond)
+	return fmt.Sprintf("%d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// durationCache remembers the durations read from the sidecars, or
+// that there wasn't a usable one, so Browse doesn't have to look for
+// them every time.
+type durationCache struct {
+	expire  time.Duration            // how long an entry is valid for
+	mu      sync.Mutex               // protects entries
+	entries map[string]durationEntry // keyed by sidecar path
+}
+
+// durationEntry is a duration read from a sidecar
+type durationEntry struct {
+	duration string    // formatted duration or "" if none
+	expires  time.Time // when this entry should be read again
+}
+
+// newDurationCache makes a durationCache whose entries are valid for
+// expire, which is normally --dir-cache-time so changes to the
+// sidecars are noticed as soon as changes to the listings.
+func newDurationCache(expire time.Duration) *durationCache {
+	return &durationCache{
+		expire:  expire,
+		entries: make(map[string]durationEntry),
+	}
+}
+
+// get returns the cached duration for the sidecar at p, calling read
+// to find it if it isn't cached or has expired.
+func (c *durationCache) get(p string, read func() string) string {
+	c.mu.Lock()
+	entry, ok := c.entries[p]
+	c.mu.Unlock()
+	now := time.Now()
+	if ok && now.Before(entry.expires) {
+		return entry.duration
+	}
+	entry = durationEntry{
+		duration: read(),
+		expires:  now.Add(c.expire),
+	}
+	c.mu.Lock()
+	c.entries[p] = entry
+	c.mu.Unlock()
+	return entry.duration
+}
+
+// sidecarDuration returns the duration for the <res> of the audio
+// file at p read from its duration sidecar or "" if there isn't one.
+func (cds *contentDirectoryService) sidecarDuration(p string) string {
+	base, _ := splitExt(p)
+	sidecar := base + durationExt
+	return cds.durations.get(sidecar, func() string {
+		return cds.readSidecarDuration(sidecar)
+	})
+}
+
+// readSidecarDuration reads the duration sidecar at p returning the
+// formatted duration or "" if it is missing or invalid.
+func (cds *contentDirectoryService) readSidecarDuration(p string) string {
+	node, err := cds.stat(p)
+	if err != nil {
+		return ""
+	}
+	file, ok := node.(*vfs.File)
+	if !ok {
+		return ""
+	}
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		fs.Errorf(node, "Failed to open duration: %v", err)
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(in, 64))
+	_ = in.Close()
+	if err != nil {
+		fs.Errorf(node, "Failed to read duration: %v", err)
+		return ""
+	}
+	d, err := parseDuration(string(data))
+	if err != nil || d <= 0 {
+		fs.Debugf(node, "Ignoring invalid duration %q", strings.TrimSpace(string(data)))
+		return ""
+	}
+	return formatDuration(d)
+}
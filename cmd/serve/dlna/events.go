@@ -0,0 +1,209 @@
+package dlna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/walk"
+)
+
+// eventsPath is where the server sends --events-interval change
+// events as server-sent events.
+const eventsPath = "/events"
+
+// changeEvent is a change to the served media
+type changeEvent struct {
+	Type string `json:"type"` // added or removed
+	Path string `json:"path"` // path of the media on the server
+}
+
+// events polls the served remotes for --events-interval while there
+// are subscribers and sends them the media which was added or
+// removed.
+type events struct {
+	s        *server
+	interval time.Duration
+	mu       sync.Mutex                    // protects the below
+	subs     map[chan changeEvent]struct{} // current subscribers
+	stop     chan struct{}                 // close to stop polling, nil if not polling
+	ready    chan struct{}                 // closed once polling has read its first listing
+}
+
+func newEvents(s *server, interval time.Duration) *events {
+	return &events{
+		s:        s,
+		interval: interval,
+		subs:     make(map[chan changeEvent]struct{}),
+	}
+}
+
+// subscribe returns a channel which receives the change events,
+// starting polling if this is the first subscriber.
+//
+// It returns once polling has read its first listing so changes made
+// after subscribing are seen.
+func (e *events) subscribe(ctx context.Context) chan changeEvent {
+	ch := make(chan changeEvent, 64)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	start := e.stop == nil
+	if start {
+		e.stop = make(chan struct{})
+		e.ready = make(chan struct{})
+	}
+	stop, ready := e.stop, e.ready
+	e.mu.Unlock()
+	if start {
+		// List without the lock so other subscribers and events
+		// aren't held up by a slow remote
+		media, err := e.s.listMedia(ctx)
+		if err != nil {
+			fs.Errorf(e.s.f, "Failed to list media for events: %v", err)
+		}
+		close(ready)
+		go e.poll(stop, media)
+	}
+	select {
+	case <-ready:
+	case <-ctx.Done():
+	}
+	return ch
+}
+
+// unsubscribe removes ch, stopping polling if it was the last
+// subscriber.
+func (e *events) unsubscribe(ch chan changeEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.subs, ch)
+	if len(e.subs) == 0 && e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+}
+
+// poll lists the media every interval and sends the differences from
+// the previous listing to the subscribers until stop is closed or the
+// server is closed.
+func (e *events) poll(stop chan struct{}, media map[string]struct{}) {
+	ctx := e.s.ctx
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		newMedia, err := e.s.listMedia(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fs.Errorf(e.s.f, "Failed to list media for events: %v", err)
+			continue
+		}
+		// media is nil if the first listing failed
+		if media != nil {
+			for p := range newMedia {
+				if _, found := media[p]; !found {
+					e.send(changeEvent{Type: "added", Path: p})
+				}
+			}
+			for p := range media {
+				if _, found := newMedia[p]; !found {
+					e.send(changeEvent{Type: "removed", Path: p})
+				}
+			}
+		}
+		media = newMedia
+	}
+}
+
+// send the event to all the subscribers, dropping it for any which
+// aren't keeping up.
+func (e *events) send(event changeEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- event:
+		default:
+			fs.Debugf(e.s.f, "Dropping %s event for %q for slow subscriber", event.Type, event.Path)
+		}
+	}
+}
+
+// listMedia returns the paths on the server of all the media served.
+func (s *server) listMedia(ctx context.Context) (map[string]struct{}, error) {
+	media := make(map[string]struct{})
+	list := func(f fs.Fs, prefix string) error {
+		return walk.ListR(ctx, f, "", false, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+			for _, entry := range entries {
+				o, ok := entry.(fs.Object)
+				if !ok {
+					continue
+				}
+				mediaType := mediaMimeTypeRegexp.FindStringSubmatch(fs.MimeType(ctx, o))
+				if mediaType != nil && s.serveMediaType(mediaType[1]) {
+					media[path.Join(prefix, o.Remote())] = struct{}{}
+				}
+			}
+			return nil
+		})
+	}
+	if len(s.roots) == 0 {
+		if err := list(s.f, "/"); err != nil {
+			return nil, err
+		}
+		return media, nil
+	}
+	for _, root := range s.roots {
+		if err := list(root.f, "/"+root.name); err != nil {
+			return nil, err
+		}
+	}
+	return media, nil
+}
+
+// eventsHandler streams the change events to the client as
+// server-sent events.
+func (s *server) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	ch := s.events.subscribe(r.Context())
+	defer s.events.unsubscribe(ch)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		fs.Errorf(s.f, "Failed to stream events: %v", err)
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-ch:
+			data, err := json.Marshal(event)
+			if err != nil {
+				fs.Errorf(s.f, "Failed to encode event: %v", err)
+				continue
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
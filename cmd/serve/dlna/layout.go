@@ -0,0 +1,209 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/cmd/serve/dlna/upnpav"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// Container layouts which can be selected with --layout
+const (
+	layoutFolder = "folder" // browse the directory tree as it is
+	layoutFlat   = "flat"   // all media directly under the root
+	layoutDate   = "date"   // media grouped into a container per month
+)
+
+const (
+	// dateIDPrefix starts the ObjectID of a --layout date container
+	dateIDPrefix = "date:"
+	// dateFormat is the format of the month in a date container
+	dateFormat = "2006-01"
+)
+
+// checkLayout returns an error if layout isn't a known container layout
+func checkLayout(layout string) error {
+	switch layout {
+	case layoutFolder, layoutFlat, layoutDate:
+		return nil
+	}
+	return fmt.Errorf("unknown --layout %q: must be %s, %s or %s", layout, layoutFolder, layoutFlat, layoutDate)
+}
+
+// mediaEntry is a media file found when walking the tree
+type mediaEntry struct {
+	obj       object
+	node      vfs.Node
+	resources vfs.Nodes
+}
+
+// isMedia returns true if node is a file which would be served as a
+// media item
+func (s *server) isMedia(node vfs.Node) bool {
+	if node.IsDir() || !node.Mode().IsRegular() {
+		return false
+	}
+	mediaType := mediaMimeTypeRegexp.FindStringSubmatch(nodeMimeType(context.TODO(), node))
+	return mediaType != nil && s.serveMediaType(mediaType[1])
+}
+
+// walkMedia returns all the media files under dirPath, sorted by path
+func (cds *contentDirectoryService) walkMedia(dirPath string) (entries []mediaEntry, err error) {
+	dirEntries, mediaResources, err := cds.listDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, de := range dirEntries {
+		childPath := path.Join(dirPath, de.Name())
+		if de.IsDir() {
+			if strings.EqualFold(de.Name(), "Subs") {
+				// Already attached to the media as resources
+				continue
+			}
+			children, err := cds.walkMedia(childPath)
+			if err != nil {
+				fs.Errorf(cds, "error with %s: %s", childPath, err)
+				continue
+			}
+			entries = append(entries, children...)
+			continue
+		}
+		if !cds.isMedia(de) {
+			continue
+		}
+		entries = append(entries, mediaEntry{
+			obj:       object{Path: childPath},
+			node:      de,
+			resources: mediaResources[de],
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].obj.Path < entries[j].obj.Path
+	})
+	return entries, nil
+}
+
+// dateID returns the ObjectID of the date container for t
+func dateID(t time.Time) string {
+	return dateIDPrefix + t.Format(dateFormat)
+}
+
+// toUpnpavObjects converts the entries to upnpav objects with the
+// given parent ObjectID
+func (cds *contentDirectoryService) toUpnpavObjects(entries []mediaEntry, parentID string, host string) (ret []interface{}) {
+	for _, entry := range entries {
+		entry.obj.parentID = parentID
+		obj, err := cds.cdsObjectToUpnpavObject(entry.obj, entry.node, entry.resources, host)
+		if err != nil {
+			fs.Errorf(cds, "error with %s: %s", entry.obj.FilePath(), err)
+			continue
+		}
+		if obj == nil {
+			continue
+		}
+		ret = append(ret, obj)
+	}
+	return ret
+}
+
+// readFlatContainer returns all the media in the tree as the children
+// of the root for --layout flat.
+func (cds *contentDirectoryService) readFlatContainer(host string) ([]interface{}, error) {
+	entries, err := cds.walkMedia("/")
+	if err != nil {
+		return nil, err
+	}
+	return cds.toUpnpavObjects(entries, "0", host), nil
+}
+
+// mediaByMonth walks the tree and groups the media by the month they
+// were modified in.
+func (cds *contentDirectoryService) mediaByMonth() (map[string][]mediaEntry, error) {
+	entries, err := cds.walkMedia("/")
+	if err != nil {
+		return nil, err
+	}
+	months := make(map[string][]mediaEntry)
+	for _, entry := range entries {
+		id := dateID(entry.node.ModTime())
+		months[id] = append(months[id], entry)
+	}
+	return months, nil
+}
+
+// newDateContainer makes the container for the month with ObjectID id
+func newDateContainer(id string, childCount int) upnpav.Container {
+	return upnpav.Container{
+		Object: upnpav.Object{
+			ID:         id,
+			ParentID:   "0",
+			Restricted: 1,
+			Class:      "object.container.storageFolder",
+			Title:      strings.TrimPrefix(id, dateIDPrefix),
+		},
+		ChildCount: &childCount,
+	}
+}
+
+// readDateContainers returns a container per month, newest first, as
+// the children of the root for --layout date.
+func (cds *contentDirectoryService) readDateContainers() (ret []interface{}, err error) {
+	months, err := cds.mediaByMonth()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(months))
+	for id := range months {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	for _, id := range ids {
+		ret = append(ret, newDateContainer(id, len(months[id])))
+	}
+	return ret, nil
+}
+
+// readDateContainer returns the media modified in the month of the
+// date container o.
+func (cds *contentDirectoryService) readDateContainer(o object, host string) ([]interface{}, error) {
+	months, err := cds.mediaByMonth()
+	if err != nil {
+		return nil, err
+	}
+	return cds.toUpnpavObjects(months[o.virtualID], o.virtualID, host), nil
+}
+
+// dateContainer returns the metadata for the date container with
+// ObjectID id.
+func (cds *contentDirectoryService) dateContainer(id string) (interface{}, error) {
+	months, err := cds.mediaByMonth()
+	if err != nil {
+		return nil, err
+	}
+	entries, found := months[id]
+	if !found {
+		return nil, fmt.Errorf("no media in %s", strings.TrimPrefix(id, dateIDPrefix))
+	}
+	return newDateContainer(id, len(entries)), nil
+}
+
+// layoutParentID returns the ObjectID of the container node appears
+// in for the current layout, or "" if that is its directory.
+func (cds *contentDirectoryService) layoutParentID(o object, node vfs.Node) string {
+	if o.IsRoot() || !cds.isMedia(node) {
+		return ""
+	}
+	switch cds.Layout {
+	case layoutFlat:
+		return "0"
+	case layoutDate:
+		return dateID(node.ModTime())
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package dlna
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// clientCaps describes the media types a client has said it can play
+type clientCaps struct {
+	mimeTypes []string // acceptable mime types, may be "type/*" - empty means anything
+}
+
+// clientCapsFromRequest works out what the client can play from the
+// Accept headers of its request.
+//
+// Clients which don't send an Accept header or which accept */* are
+// assumed to be able to play anything.
+func clientCapsFromRequest(r *http.Request) (caps clientCaps) {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mimeType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if mimeType == "*/*" {
+				return clientCaps{}
+			}
+			caps.mimeTypes = append(caps.mimeTypes, mimeType)
+		}
+	}
+	return caps
+}
+
+// canPlay returns true if the client can play media of mimeType
+func (caps clientCaps) canPlay(mimeType string) bool {
+	if len(caps.mimeTypes) == 0 {
+		return true
+	}
+	major, _, _ := strings.Cut(mimeType, "/")
+	for _, accept := range caps.mimeTypes {
+		if accept == mimeType || accept == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// remuxFunc adapts the media read from in, which is of mimeType, into
+// a container that the client described by caps can play.
+//
+// If out is nil then the original media is served unchanged,
+// otherwise out is streamed to the client with outMimeType and closed
+// afterwards.
+type remuxFunc func(ctx context.Context, in io.Reader, mimeType string, caps clientCaps) (out io.ReadCloser, outMimeType string, err error)
+
+// passthroughRemux is the default remuxFunc - it never remuxes so the
+// client is always sent the original media.
+func passthroughRemux(ctx context.Context, in io.Reader, mimeType string, caps clientCaps) (out io.ReadCloser, outMimeType string, err error) {
+	return nil, "", nil
+}
@@ -0,0 +1,138 @@
+package dlna
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// root is a remote served as a top level container when serving more
+// than one remote.
+type root struct {
+	name string
+	f    fs.Fs
+	vfs  *vfs.VFS
+}
+
+// rootName returns the default container name for f - the last
+// element of its root, or the remote name if the root is empty.
+func rootName(f fs.Fs) string {
+	name := path.Base(strings.Trim(filepath.ToSlash(f.Root()), "/"))
+	if name == "." || name == "/" {
+		name = f.Name()
+	}
+	return name
+}
+
+// newRoots makes a root for each of fses, naming them after the
+// remotes and making the names unique.
+func newRoots(fses []fs.Fs) []root {
+	roots := make([]root, 0, len(fses))
+	seen := make(map[string]struct{}, len(fses))
+	for _, f := range fses {
+		base := rootName(f)
+		name := base
+		for i := 2; ; i++ {
+			if _, found := seen[name]; !found {
+				break
+			}
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		seen[name] = struct{}{}
+		roots = append(roots, root{
+			name: name,
+			f:    f,
+			vfs:  vfs.New(f, &vfscommon.Opt),
+		})
+	}
+	return roots
+}
+
+// rootNode is the root directory of a remote, named after its top
+// level container.
+type rootNode struct {
+	*vfs.Dir
+	name string
+}
+
+// Name returns the name of the container
+func (n rootNode) Name() string {
+	return n.name
+}
+
+// splitRoot splits the server path p into the name of the top level
+// container and the path within that remote.
+func splitRoot(p string) (name, remotePath string) {
+	p = strings.Trim(path.Clean(p), "/")
+	name, remotePath, _ = strings.Cut(p, "/")
+	return name, "/" + remotePath
+}
+
+// findRoot returns the root called name or nil if not found
+func (s *server) findRoot(name string) *root {
+	for i := range s.roots {
+		if s.roots[i].name == name {
+			return &s.roots[i]
+		}
+	}
+	return nil
+}
+
+// rootNodes returns the root directories of the remotes as the
+// contents of the top level.
+func (s *server) rootNodes() (nodes vfs.Nodes) {
+	for _, r := range s.roots {
+		dir, err := r.vfs.Root()
+		if err != nil {
+			fs.Errorf(r.f, "failed to read root: %v", err)
+			continue
+		}
+		nodes = append(nodes, rootNode{Dir: dir, name: r.name})
+	}
+	return nodes
+}
+
+// stat returns the node at the server path p.
+//
+// When serving more than one remote, the first element of p selects
+// the remote and "/" is a virtual directory containing them.
+func (s *server) stat(p string) (vfs.Node, error) {
+	if len(s.roots) == 0 {
+		return s.vfs.Stat(p)
+	}
+	name, remotePath := splitRoot(p)
+	if name == "" {
+		dir, err := s.roots[0].vfs.Root()
+		if err != nil {
+			return nil, err
+		}
+		return rootNode{Dir: dir, name: "/"}, nil
+	}
+	r := s.findRoot(name)
+	if r == nil {
+		return nil, vfs.ENOENT
+	}
+	node, err := r.vfs.Stat(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if dir, ok := node.(*vfs.Dir); ok && remotePath == "/" {
+		return rootNode{Dir: dir, name: r.name}, nil
+	}
+	return node, nil
+}
+
+// serverPath returns the server path of node, which is in the same
+// remote as the server path p.
+func (s *server) serverPath(p string, node vfs.Node) string {
+	if len(s.roots) == 0 {
+		return node.Path()
+	}
+	name, _ := splitRoot(p)
+	return path.Join("/", name, node.Path())
+}
@@ -0,0 +1,109 @@
+package dlna
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// srtMimeType is the mime type SRT subtitles are served with
+const srtMimeType = "application/x-subrip"
+
+// isVTT returns true if name is a WebVTT subtitle file
+func isVTT(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".vtt")
+}
+
+var (
+	// matches a WebVTT cue timing line, hours are optional
+	vttTimingRe = regexp.MustCompile(`^\s*((?:\d+:)?\d{2}:\d{2}\.\d{3})\s+-->\s+((?:\d+:)?\d{2}:\d{2}\.\d{3})`)
+	// matches WebVTT cue tags apart from the ones SRT understands
+	vttTagRe = regexp.MustCompile(`</?(?:[^biu/>][^>]*|[biu][^>\s]+[^>]*)>`)
+)
+
+// vttTimestampToSRT converts a WebVTT timestamp ([hh:]mm:ss.ttt) to
+// an SRT one (hh:mm:ss,ttt)
+func vttTimestampToSRT(timestamp string) string {
+	if strings.Count(timestamp, ":") == 1 {
+		timestamp = "00:" + timestamp
+	}
+	if len(timestamp) < len("00:00:00.000") {
+		timestamp = "0" + timestamp
+	}
+	return strings.Replace(timestamp, ".", ",", 1)
+}
+
+// vttToSRT reads WebVTT subtitles from in and returns them converted
+// to SRT.
+//
+// Cue settings, styling and tags SRT doesn't understand are dropped
+// and NOTE, STYLE and REGION blocks are skipped.
+func vttToSRT(in io.Reader) ([]byte, error) {
+	var (
+		out     bytes.Buffer
+		scanner = bufio.NewScanner(in)
+		block   []string
+		cues    = 0
+		first   = true
+	)
+	flush := func() {
+		defer func() { block = block[:0] }()
+		if len(block) == 0 {
+			return
+		}
+		if first {
+			// The first block is the WEBVTT header
+			first = false
+			return
+		}
+		// Find the timing line, which may follow a cue identifier
+		timing := -1
+		for i, line := range block[:min(2, len(block))] {
+			if vttTimingRe.MatchString(line) {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			// NOTE, STYLE, REGION or something we don't understand
+			return
+		}
+		match := vttTimingRe.FindStringSubmatch(block[timing])
+		cues++
+		fmt.Fprintf(&out, "%d\n%s --> %s\n", cues, vttTimestampToSRT(match[1]), vttTimestampToSRT(match[2]))
+		for _, line := range block[timing+1:] {
+			line = html.UnescapeString(vttTagRe.ReplaceAllString(line, ""))
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+		out.WriteByte('\n')
+	}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first && len(block) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+			if !strings.HasPrefix(line, "WEBVTT") {
+				return nil, fmt.Errorf("not a WebVTT file: bad header %q", line)
+			}
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	if first {
+		return nil, errors.New("not a WebVTT file: empty")
+	}
+	return out.Bytes(), nil
+}
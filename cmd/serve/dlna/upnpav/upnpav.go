@@ -32,9 +32,10 @@ type Container struct {
 // Item description
 type Item struct {
 	Object
-	XMLName  xml.Name `xml:"item"`
-	Res      []Resource
-	InnerXML string `xml:",innerxml"`
+	XMLName              xml.Name `xml:"item"`
+	LastPlaybackPosition string   `xml:"upnp:lastPlaybackPosition,omitempty"`
+	Res                  []Resource
+	InnerXML             string `xml:",innerxml"`
 }
 
 // Object description
@@ -56,14 +56,14 @@ a remote:path.
 				return operations.CheckSum(context.Background(), fsrc, fsum, sumFile, hash.SHA1, nil, hashsum.DownloadFlag)
 			}
 			if hashsum.HashsumOutfile == "" {
-				return operations.HashLister(context.Background(), hash.SHA1, hashsum.OutputBase64, hashsum.DownloadFlag, fsrc, nil)
+				return hashsum.HashLister(context.Background(), hash.SHA1, fsrc, nil)
 			}
 			output, close, err := hashsum.GetHashsumOutput(hashsum.HashsumOutfile)
 			if err != nil {
 				return err
 			}
 			defer close()
-			return operations.HashLister(context.Background(), hash.SHA1, hashsum.OutputBase64, hashsum.DownloadFlag, fsrc, output)
+			return hashsum.HashLister(context.Background(), hash.SHA1, fsrc, output)
 		})
 		return nil
 	},
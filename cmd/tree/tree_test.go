@@ -33,3 +33,23 @@ func TestTree(t *testing.T) {
 1 directories, 5 files
 `, buf.String())
 }
+
+// Check --level limits the depth of the tree
+func TestTreeLevel(t *testing.T) {
+	fstest.Initialise()
+
+	buf := new(bytes.Buffer)
+
+	f, err := fs.NewFs(context.Background(), "testfiles")
+	require.NoError(t, err)
+	err = Tree(f, buf, &tree.Options{DeepLevel: 1})
+	require.NoError(t, err)
+	assert.Equal(t, `/
+├── file1
+├── file2
+├── file3
+└── subdir
+
+1 directories, 3 files
+`, buf.String())
+}
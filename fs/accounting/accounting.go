@@ -68,6 +68,7 @@ type Account struct {
 	closed   bool          // set if the file is closed
 	exit     chan struct{} // channel that will be closed when transfer is finished
 	withBuf  bool          // is using a buffered in
+	bufSize  int64         // size of the buffer if withBuf is set
 	checking bool          // set if attached transfer is checking
 
 	tokenBucket buckets // per file bandwidth limiter (may be nil)
@@ -122,15 +123,23 @@ func newAccountSizeName(ctx context.Context, stats *StatsInfo, in io.ReadCloser,
 }
 
 // WithBuffer - If the file is above a certain size it adds an Async reader
+// using --buffer-size worth of buffers
 func (acc *Account) WithBuffer() *Account {
+	return acc.WithBufferSize(int64(acc.ci.BufferSize))
+}
+
+// WithBufferSize - If the file is above a certain size it adds an
+// Async reader using up to bufSize bytes of buffers
+func (acc *Account) WithBufferSize(bufSize int64) *Account {
 	// if already have a buffer then just return
 	if acc.withBuf {
 		return acc
 	}
 	acc.withBuf = true
+	acc.bufSize = bufSize
 	var buffers int
-	if acc.size >= int64(acc.ci.BufferSize) || acc.size == -1 {
-		buffers = int(int64(acc.ci.BufferSize) / asyncreader.BufferSize)
+	if acc.size >= bufSize || acc.size == -1 {
+		buffers = int(bufSize / asyncreader.BufferSize)
 	} else {
 		buffers = int(acc.size / asyncreader.BufferSize)
 	}
@@ -201,7 +210,7 @@ func (acc *Account) UpdateReader(ctx context.Context, in io.ReadCloser) {
 	acc.origIn = in
 	acc.closed = false
 	if withBuf {
-		acc.WithBuffer()
+		acc.WithBufferSize(acc.bufSize)
 	}
 	acc.mu.Unlock()
 
@@ -484,6 +493,15 @@ func (acc *Account) speed() (bps, current float64) {
 	return
 }
 
+// Speed returns the average speed of the transfer since the first
+// read and the current speed as an exponentially weighted moving
+// average, both in bytes per second.
+//
+// If nothing has been read yet then both values are 0.
+func (acc *Account) Speed() (average, current float64) {
+	return acc.speed()
+}
+
 // eta returns the ETA of the current operation,
 // rounded to full seconds.
 // If the ETA cannot be determined 'ok' returns false.
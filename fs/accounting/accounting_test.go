@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
 	"github.com/rclone/rclone/fs"
@@ -63,6 +64,30 @@ func TestAccountWithBuffer(t *testing.T) {
 	assert.NoError(t, acc.Close())
 }
 
+func TestAccountWithBufferSize(t *testing.T) {
+	ctx := context.Background()
+	in := io.NopCloser(bytes.NewBuffer([]byte{1}))
+	stats := NewStats(ctx)
+
+	// should not have a buffer if the size is too small for one
+	acc := newAccountSizeName(ctx, stats, in, -1, "test")
+	acc.WithBufferSize(asyncreader.BufferSize - 1)
+	assert.False(t, acc.HasBuffer())
+	assert.NoError(t, acc.Close())
+
+	acc = newAccountSizeName(ctx, stats, in, -1, "test")
+	acc.WithBufferSize(2 * asyncreader.BufferSize)
+	assert.True(t, acc.HasBuffer())
+	assert.Equal(t, int64(2*asyncreader.BufferSize), acc.bufSize)
+
+	// should keep the buffer size when the reader is updated
+	in2 := io.NopCloser(bytes.NewBuffer([]byte{1}))
+	acc.UpdateReader(ctx, in2)
+	assert.True(t, acc.HasBuffer())
+	assert.Equal(t, int64(2*asyncreader.BufferSize), acc.bufSize)
+	assert.NoError(t, acc.Close())
+}
+
 func TestAccountGetUpdateReader(t *testing.T) {
 	ctx := context.Background()
 	test := func(doClose bool) func(t *testing.T) {
@@ -130,6 +155,36 @@ func TestAccountRead(t *testing.T) {
 	assert.NoError(t, acc.Close())
 }
 
+func TestAccountSpeed(t *testing.T) {
+	ctx := context.Background()
+	const size = 10000
+	in := io.NopCloser(bytes.NewBuffer(make([]byte, size)))
+	stats := NewStats(ctx)
+	acc := newAccountSizeName(ctx, stats, in, size, "test")
+
+	// No speed until something has been read
+	average, current := acc.Speed()
+	assert.Equal(t, 0.0, average)
+	assert.Equal(t, 0.0, current)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, acc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), n)
+	const interval = 200 * time.Millisecond
+	time.Sleep(interval)
+
+	// The average should be the bytes read over the time since
+	// the first read
+	average, current = acc.Speed()
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, average, size/elapsed.Seconds())
+	assert.LessOrEqual(t, average, size/interval.Seconds())
+	assert.GreaterOrEqual(t, current, 0.0)
+
+	assert.NoError(t, acc.Close())
+}
+
 func testAccountWriteTo(t *testing.T, withBuffer bool) {
 	ctx := context.Background()
 	buf := make([]byte, 2*asyncreader.BufferSize+1)
@@ -0,0 +1,82 @@
+package accounting
+
+import (
+	"context"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// Retry calls f and, if retry is set, calls it again up to --retries
+// times in total while it fails with errors which can be retried,
+// sleeping for --retries-sleep between tries.
+//
+// The errors are counted in the stats for ctx which are reset between
+// tries. It returns the error from the last try, or the last error
+// counted if f didn't return one.
+//
+// This is used by the command line and the rc so both retry the same
+// way.
+func Retry(ctx context.Context, retry bool, f func() error) (err error) {
+	ci := fs.GetConfig(ctx)
+	stats := Stats(ctx)
+	for try := 1; try <= ci.Retries; try++ {
+		err = stats.Error(f())
+		lastErr := stats.GetLastError()
+		if err == nil {
+			err = lastErr
+		}
+		if !retry || !stats.Errored() {
+			if try > 1 {
+				fs.Errorf(nil, "Attempt %d/%d succeeded", try, ci.Retries)
+			}
+			return err
+		}
+		if stats.HadFatalError() {
+			fs.Errorf(nil, "Fatal error received - not attempting retries")
+			return err
+		}
+		if !stats.HadRetryError() {
+			fs.Errorf(nil, "Can't retry any of the errors - not attempting retries")
+			return err
+		}
+		if lastErr != nil {
+			fs.Errorf(nil, "Attempt %d/%d failed with %d errors and: %v", try, ci.Retries, stats.GetErrors(), lastErr)
+		} else {
+			fs.Errorf(nil, "Attempt %d/%d failed with %d errors", try, ci.Retries, stats.GetErrors())
+		}
+		if try == ci.Retries {
+			break
+		}
+		if retryAfter := stats.RetryAfter(); !retryAfter.IsZero() {
+			d := time.Until(retryAfter)
+			if d > 0 {
+				fs.Logf(nil, "Received retry after error - sleeping until %s (%v)", retryAfter.Format(time.RFC3339Nano), d)
+				if sleep(ctx, d) != nil {
+					return err
+				}
+			}
+		}
+		stats.ResetErrors()
+		if sleep(ctx, ci.RetriesInterval) != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// sleep waits for d or until ctx is cancelled, returning the
+// context's error if it was.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,82 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.Retries = 3
+	ci.RetriesInterval = 0
+
+	runs := 0
+	run := func(retry bool, errs ...error) (tries int, err error) {
+		runs++
+		ctx := WithStatsGroup(ctx, fmt.Sprintf("%s-%d", t.Name(), runs))
+		err = Retry(ctx, retry, func() error {
+			tries++
+			if tries <= len(errs) {
+				return errs[tries-1]
+			}
+			return nil
+		})
+		return tries, err
+	}
+
+	// retryable error then success
+	tries, err := run(true, fserrors.RetryError(errors.New("retry me")))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tries)
+
+	// retryable errors every time
+	retryErr := fserrors.RetryError(errors.New("retry me"))
+	tries, err = run(true, retryErr, retryErr, retryErr, retryErr)
+	assert.Error(t, err)
+	assert.Equal(t, 3, tries)
+
+	// nothing is retried if retry isn't set
+	tries, err = run(false, retryErr)
+	assert.Error(t, err)
+	assert.Equal(t, 1, tries)
+
+	// fatal errors aren't retried
+	tries, err = run(true, fserrors.FatalError(errors.New("fatal")))
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Equal(t, 1, tries)
+
+	// nor are ones which can't be retried
+	tries, err = run(true, fserrors.NoRetryError(errors.New("no retry")))
+	assert.Error(t, err)
+	assert.Equal(t, 1, tries)
+
+	// errors counted in the stats are returned if f doesn't return one
+	countedErr := fserrors.NoRetryError(errors.New("counted"))
+	countedCtx := WithStatsGroup(ctx, t.Name()+"-counted")
+	err = Retry(countedCtx, true, func() error {
+		_ = Stats(countedCtx).Error(countedErr)
+		return nil
+	})
+	assert.Equal(t, countedErr, err)
+
+	// cancelling the context stops the sleep between retries
+	ci.RetriesInterval = time.Hour
+	cancelCtx, cancel := context.WithCancel(WithStatsGroup(ctx, t.Name()+"-cancel"))
+	tries = 0
+	start := time.Now()
+	err = Retry(cancelCtx, true, func() error {
+		tries++
+		cancel()
+		return retryErr
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, tries)
+	assert.Less(t, time.Since(start), time.Minute)
+}
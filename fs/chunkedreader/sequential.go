@@ -13,6 +13,9 @@ import (
 // of reading the source in chunks of given size
 //
 // An initialChunkSize of <= 0 will disable chunked reading.
+//
+// If reading a chunk fails it is reopened from where it failed, up to
+// --low-level-retries times for each chunk.
 type sequential struct {
 	ctx              context.Context
 	mu               sync.Mutex    // protects following fields
@@ -25,6 +28,7 @@ type sequential struct {
 	maxChunkSize     int64         // consecutive read chunks will double in size until reached. -1 means no limit
 	customChunkSize  bool          // is the current chunkSize set by RangeSeek?
 	closed           bool          // has Close been called?
+	tries            int           // number of retries reading the current chunk
 }
 
 // Make a new sequential chunked reader
@@ -70,10 +74,16 @@ func (cr *sequential) Read(p []byte) (n int, err error) {
 			chunkEnd = cr.chunkOffset + cr.chunkSize
 			fallthrough
 		case cr.offset == -1: // first Read or Read after RangeSeek
+			cr.tries = 0
 			err = cr.openRange()
 			if err != nil {
 				return
 			}
+		case cr.rc == nil: // a retry failed to reopen the chunk
+			err = cr.reopen(chunkEnd)
+			if err != nil {
+				return
+			}
 		}
 
 		var buf []byte
@@ -85,9 +95,15 @@ func (cr *sequential) Read(p []byte) (n int, err error) {
 			buf, p = p, nil
 		}
 		var rn int
-		rn, err = io.ReadFull(cr.rc, buf)
-		n += rn
-		cr.offset += int64(rn)
+		for {
+			rn, err = io.ReadFull(cr.rc, buf)
+			n += rn
+			cr.offset += int64(rn)
+			buf = buf[rn:]
+			if err == nil || !cr.retry(err, chunkEnd) {
+				break
+			}
+		}
 		if err != nil {
 			if err == io.ErrUnexpectedEOF {
 				err = io.EOF
@@ -167,6 +183,10 @@ func (cr *sequential) Open() (ChunkedReader, error) {
 	if cr.rc != nil && cr.offset != -1 {
 		return cr, nil
 	}
+	if cr.offset != -1 {
+		// a retry failed to reopen the chunk
+		return cr, cr.reopen(cr.chunkOffset + cr.chunkSize)
+	}
 	return cr, cr.openRange()
 }
 
@@ -177,7 +197,12 @@ func (cr *sequential) Open() (ChunkedReader, error) {
 //
 // A length <= 0 will request till the end of the file
 func (cr *sequential) openRange() error {
-	offset, length := cr.chunkOffset, cr.chunkSize
+	return cr.openRangeAt(cr.chunkOffset, cr.chunkSize)
+}
+
+// openRangeAt will open the source Object from offset for length
+// bytes - see openRange
+func (cr *sequential) openRangeAt(offset, length int64) error {
 	fs.Debugf(cr.o, "ChunkedReader.openRange at %d length %d", offset, length)
 
 	if cr.closed {
@@ -214,6 +239,38 @@ func (cr *sequential) openRange() error {
 	return cr.resetReader(rc, offset)
 }
 
+// reopen opens the rest of the current chunk, which ends at chunkEnd,
+// from the current offset
+func (cr *sequential) reopen(chunkEnd int64) error {
+	length := int64(-1)
+	if cr.chunkSize > 0 {
+		length = chunkEnd - cr.offset
+	}
+	return cr.openRangeAt(cr.offset, length)
+}
+
+// retry reopens the current chunk where the read stopped after it
+// failed with err.
+//
+// It returns false if err can't be retried or the chunk has been
+// retried --low-level-retries times already.
+func (cr *sequential) retry(err error, chunkEnd int64) bool {
+	maxTries := fs.GetConfig(cr.ctx).LowLevelRetries
+	if err == io.EOF || err == io.ErrUnexpectedEOF || cr.ctx.Err() != nil || cr.tries >= maxTries {
+		return false
+	}
+	cr.tries++
+	fs.Debugf(cr.o, "ChunkedReader.Read failed at %d: low level retry %d/%d: %v", cr.offset, cr.tries, maxTries, err)
+	// The old reader has failed so ignore any error closing it
+	_ = cr.rc.Close()
+	cr.rc = nil
+	if err := cr.reopen(chunkEnd); err != nil {
+		fs.Debugf(cr.o, "ChunkedReader.Read failed to reopen: %v", err)
+		return false
+	}
+	return true
+}
+
 // resetReader switches the current reader to the given reader.
 // The old reader will be Close'd before setting the new reader.
 func (cr *sequential) resetReader(rc io.ReadCloser, offset int64) error {
@@ -1,10 +1,17 @@
 package chunkedreader
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"testing"
 
 	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSequential(t *testing.T) {
@@ -18,3 +25,82 @@ func TestSequential(t *testing.T) {
 func TestSequentialErrorAfterClose(t *testing.T) {
 	testErrorAfterClose(t, 0)
 }
+
+var errConnectionReset = errors.New("connection reset")
+
+// flakyObject records the ranges opened and makes the first reader
+// opened at failAt fail after failAfter bytes
+type flakyObject struct {
+	*mockobject.ContentMockObject
+	opens     []string
+	failAt    int64
+	failAfter int64
+	failed    bool
+}
+
+func (o *flakyObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	var start, end int64 = 0, -1
+	for _, option := range options {
+		if x, ok := option.(*fs.RangeOption); ok {
+			start, end = x.Start, x.End
+		}
+	}
+	o.opens = append(o.opens, fmt.Sprintf("%d-%d", start, end))
+	rc, err := o.ContentMockObject.Open(ctx, options...)
+	if err != nil || o.failed || start != o.failAt {
+		return rc, err
+	}
+	o.failed = true
+	return &failingReader{ReadCloser: rc, n: o.failAfter}, nil
+}
+
+// failingReader returns errConnectionReset after reading n bytes
+type failingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, errConnectionReset
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.n -= int64(n)
+	return n, err
+}
+
+func TestSequentialChunksAndRetries(t *testing.T) {
+	ctx := context.Background()
+	content := makeContent(t, 1024)
+	newObject := func() *flakyObject {
+		return &flakyObject{
+			ContentMockObject: mockobject.New("test.bin").WithContent(content, mockobject.SeekModeNone),
+			failAt:            48,
+			failAfter:         10,
+		}
+	}
+
+	// The chunks double in size up to the limit and the failed
+	// chunk is reopened where it failed
+	o := newObject()
+	cr := newSequential(ctx, o, 16, 64)
+	got, err := io.ReadAll(cr)
+	require.NoError(t, err)
+	require.NoError(t, cr.Close())
+	assert.Equal(t, content, got)
+	assert.Equal(t, []string{"0-15", "16-47", "48-111", "58-111", "112-175", "176-239"}, o.opens[:6])
+
+	// The error is returned if there are no retries left
+	ctx, ci := fs.AddConfig(ctx)
+	ci.LowLevelRetries = 0
+	o = newObject()
+	cr = newSequential(ctx, o, 16, 64)
+	got, err = io.ReadAll(cr)
+	assert.Equal(t, errConnectionReset, err)
+	assert.Equal(t, content[:58], got)
+	assert.Equal(t, []string{"0-15", "16-47", "48-111"}, o.opens)
+	require.NoError(t, cr.Close())
+}
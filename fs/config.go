@@ -66,12 +66,42 @@ var ConfigOptionsInfo = Options{{
 	Default: 4,
 	Help:    "Number of file transfers to run in parallel",
 	Groups:  "Performance",
+}, {
+	Name:    "max_concurrent_api",
+	Default: 0,
+	Help:    "Max number of backend operations checkers, transfers and listings do at once (0 for no limit)",
+	Groups:  "Performance",
+}, {
+	Name:    "max_open_files",
+	Default: 0,
+	Help:    "Max number of source files held open for transfer at once, counting each multi-thread stream (0 for no limit)",
+	Groups:  "Performance",
+}, {
+	Name:    "deleters",
+	Default: 0,
+	Help:    "Number of deletes to run in parallel (0 to use --checkers)",
+	Groups:  "Performance",
 }, {
 	Name:     "checksum",
 	ShortOpt: "c",
 	Default:  false,
 	Help:     "Check for changes with size & checksum (if available, or fallback to size only)",
 	Groups:   "Copy",
+}, {
+	Name:    "quick_compare",
+	Default: false,
+	Help:    "Compare large files with a hash of their first and last bytes instead of a full hash (heuristic)",
+	Groups:  "Copy",
+}, {
+	Name:    "quick_compare_cutoff",
+	Default: SizeSuffix(256 * Mebi),
+	Help:    "Use --quick-compare for files of at least this size",
+	Groups:  "Copy",
+}, {
+	Name:    "quick_compare_bytes",
+	Default: SizeSuffix(Mebi),
+	Help:    "Number of bytes at each end of the file to hash for --quick-compare",
+	Groups:  "Copy",
 }, {
 	Name:    "size_only",
 	Default: false,
@@ -88,11 +118,26 @@ var ConfigOptionsInfo = Options{{
 	Default: false,
 	Help:    "Skip all files that exist on destination",
 	Groups:  "Copy",
+}, {
+	Name:    "no_overwrite",
+	Default: false,
+	Help:    "Only copy files missing on the destination, leaving existing files untouched",
+	Groups:  "Copy",
+}, {
+	Name:    "skip_empty_overwrite",
+	Default: false,
+	Help:    "Don't overwrite non-empty files on the destination with empty source files",
+	Groups:  "Copy",
 }, {
 	Name:    "ignore_errors",
 	Default: false,
 	Help:    "Delete even if there are I/O errors",
 	Groups:  "Sync",
+}, {
+	Name:    "suppress_fatal_abort",
+	Default: false,
+	Help:    "Carry on syncing other files after a fatal error on a single file",
+	Groups:  "Sync",
 }, {
 	Name:     "dry_run",
 	ShortOpt: "n",
@@ -207,6 +252,16 @@ var ConfigOptionsInfo = Options{{
 	Default: false,
 	Help:    "Skip post copy check of checksums",
 	Groups:  "Copy",
+}, {
+	Name:    "verify_after_copy",
+	Default: false,
+	Help:    "Look up each file on the destination again after copying and check its size and hash",
+	Groups:  "Copy",
+}, {
+	Name:    "tier",
+	Default: "",
+	Help:    "Set the storage tier of copied files if the destination supports it",
+	Groups:  "Copy",
 }, {
 	Name:    "ignore_case_sync",
 	Default: false,
@@ -227,6 +282,41 @@ var ConfigOptionsInfo = Options{{
 	Default: false,
 	Help:    "Do all the checks before starting transfers",
 	Groups:  "Copy",
+}, {
+	Name:    "check_free_space",
+	Default: false,
+	Help:    "Check the destination has enough free space before starting transfers",
+	Groups:  "Copy",
+}, {
+	Name:    "sync_manifest",
+	Default: "",
+	Help:    "Only transfer the files listed in this file, failing if any are missing from the source",
+	Groups:  "Copy",
+}, {
+	Name:    "dst_listing",
+	Default: "",
+	Help:    "Read the destination listing from this rclone lsjson file instead of listing the destination",
+	Groups:  "Copy",
+}, {
+	Name:    "checkpoint",
+	Default: "",
+	Help:    "Record completed source directories in this file so an interrupted sync can skip them when restarted",
+	Groups:  "Copy",
+}, {
+	Name:    "pointer_files",
+	Default: "",
+	Help:    "Copy the file named inside source files matching this glob instead of the file itself",
+	Groups:  "Copy",
+}, {
+	Name:    "preserve_hardlinks",
+	Default: false,
+	Help:    "Only upload the content of hard linked source files once",
+	Groups:  "Copy",
+}, {
+	Name:    "skip_in_use",
+	Default: false,
+	Help:    "Skip local files which change between being listed and transferred",
+	Groups:  "Copy",
 }, {
 	Name:    "no_check_dest",
 	Default: false,
@@ -262,6 +352,16 @@ var ConfigOptionsInfo = Options{{
 	Default: "",
 	Help:    "Make backups into hierarchy based in DIR",
 	Groups:  "Sync",
+}, {
+	Name:    "trash_dir",
+	Default: "",
+	Help:    "Move files deleted by sync into a timestamped directory in DIR instead of deleting them",
+	Groups:  "Sync",
+}, {
+	Name:    "trash_retention",
+	Default: Duration(0),
+	Help:    "Purge directories in --trash-dir older than this (0 to keep them forever)",
+	Groups:  "Sync",
 }, {
 	Name:    "suffix",
 	Default: "",
@@ -357,6 +457,11 @@ var ConfigOptionsInfo = Options{{
 	Default: time.Duration(0),
 	Help:    "Maximum duration rclone will transfer data for",
 	Groups:  "Copy",
+}, {
+	Name:    "max_transfer_count",
+	Default: int64(-1),
+	Help:    "Maximum number of files to transfer",
+	Groups:  "Copy",
 }, {
 	Name:    "cutoff_mode",
 	Default: CutoffMode(0),
@@ -458,6 +563,11 @@ var ConfigOptionsInfo = Options{{
 	Default: "",
 	Help:    "Instructions on how to order the transfers, e.g. 'size,descending'",
 	Groups:  "Copy",
+}, {
+	Name:    "shuffle_dirs",
+	Default: false,
+	Help:    "List directories in a random order to spread the load across prefixes",
+	Groups:  "Performance",
 }, {
 	Name:    "refresh_times",
 	Default: false,
@@ -534,6 +644,11 @@ var ConfigOptionsInfo = Options{{
 	Default: ".partial",
 	Help:    "Add partial-suffix to temporary file name when --inplace is not used",
 	Groups:  "Copy",
+}, {
+	Name:    "safe_update",
+	Default: false,
+	Help:    "Update existing files by uploading to a temporary name then moving over the original",
+	Groups:  "Copy",
 }}
 
 // ConfigInfo is filesystem config options
@@ -545,13 +660,22 @@ type ConfigInfo struct {
 	Interactive                bool              `config:"interactive"`
 	Links                      bool              `config:"links"`
 	CheckSum                   bool              `config:"checksum"`
+	QuickCompare               bool              `config:"quick_compare"`
+	QuickCompareCutoff         SizeSuffix        `config:"quick_compare_cutoff"`
+	QuickCompareBytes          SizeSuffix        `config:"quick_compare_bytes"`
 	SizeOnly                   bool              `config:"size_only"`
 	IgnoreTimes                bool              `config:"ignore_times"`
 	IgnoreExisting             bool              `config:"ignore_existing"`
+	NoOverwrite                bool              `config:"no_overwrite"`
+	SkipEmptyOverwrite         bool              `config:"skip_empty_overwrite"`
 	IgnoreErrors               bool              `config:"ignore_errors"`
+	SuppressFatalAbort         bool              `config:"suppress_fatal_abort"`
 	ModifyWindow               time.Duration     `config:"modify_window"`
 	Checkers                   int               `config:"checkers"`
 	Transfers                  int               `config:"transfers"`
+	MaxConcurrentAPI           int               `config:"max_concurrent_api"`
+	MaxOpenFiles               int               `config:"max_open_files"`
+	Deleters                   int               `config:"deleters"`
 	ConnectTimeout             time.Duration     `config:"contimeout"` // Connect timeout
 	Timeout                    time.Duration     `config:"timeout"`    // Data channel timeout
 	ExpectContinueTimeout      time.Duration     `config:"expect_continue_timeout"`
@@ -570,10 +694,19 @@ type ConfigInfo struct {
 	MaxDepth                   int               `config:"max_depth"`
 	IgnoreSize                 bool              `config:"ignore_size"`
 	IgnoreChecksum             bool              `config:"ignore_checksum"`
+	VerifyAfterCopy            bool              `config:"verify_after_copy"`
+	Tier                       string            `config:"tier"`
 	IgnoreCaseSync             bool              `config:"ignore_case_sync"`
 	FixCase                    bool              `config:"fix_case"`
 	NoTraverse                 bool              `config:"no_traverse"`
 	CheckFirst                 bool              `config:"check_first"`
+	CheckFreeSpace             bool              `config:"check_free_space"`
+	SyncManifest               string            `config:"sync_manifest"`
+	DstListing                 string            `config:"dst_listing"`
+	Checkpoint                 string            `config:"checkpoint"`
+	PointerFiles               string            `config:"pointer_files"`
+	PreserveHardlinks          bool              `config:"preserve_hardlinks"`
+	SkipInUse                  bool              `config:"skip_in_use"`
 	NoCheckDest                bool              `config:"no_check_dest"`
 	NoUnicodeNormalization     bool              `config:"no_unicode_normalization"`
 	NoUpdateModTime            bool              `config:"no_update_modtime"`
@@ -582,6 +715,8 @@ type ConfigInfo struct {
 	CompareDest                []string          `config:"compare_dest"`
 	CopyDest                   []string          `config:"copy_dest"`
 	BackupDir                  string            `config:"backup_dir"`
+	TrashDir                   string            `config:"trash_dir"`
+	TrashRetention             Duration          `config:"trash_retention"`
 	Suffix                     string            `config:"suffix"`
 	SuffixKeepExtension        bool              `config:"suffix_keep_extension"`
 	UseListR                   bool              `config:"fast_list"`
@@ -602,6 +737,7 @@ type ConfigInfo struct {
 	UseServerModTime           bool              `config:"use_server_modtime"`
 	MaxTransfer                SizeSuffix        `config:"max_transfer"`
 	MaxDuration                time.Duration     `config:"max_duration"`
+	MaxTransferCount           int64             `config:"max_transfer_count"`
 	CutoffMode                 CutoffMode        `config:"cutoff_mode"`
 	MaxBacklog                 int               `config:"max_backlog"`
 	MaxStatsGroups             int               `config:"max_stats_groups"`
@@ -622,6 +758,7 @@ type ConfigInfo struct {
 	MultiThreadChunkSize       SizeSuffix        `config:"multi_thread_chunk_size"` // Chunk size for multi-thread downloads / uploads, if not set by filesystem
 	MultiThreadWriteBufferSize SizeSuffix        `config:"multi_thread_write_buffer_size"`
 	OrderBy                    string            `config:"order_by"` // instructions on how to order the transfer
+	ShuffleDirs                bool              `config:"shuffle_dirs"`
 	UploadHeaders              []*HTTPOption     `config:"upload_headers"`
 	DownloadHeaders            []*HTTPOption     `config:"download_headers"`
 	Headers                    []*HTTPOption     `config:"headers"`
@@ -641,6 +778,7 @@ type ConfigInfo struct {
 	DefaultTime                Time              `config:"default_time"` // time that directories with no time should display
 	Inplace                    bool              `config:"inplace"`      // Download directly to destination file instead of atomic download to temp/rename
 	PartialSuffix              string            `config:"partial_suffix"`
+	SafeUpdate                 bool              `config:"safe_update"` // Update existing files via a temporary name even if the backend doesn't support partial uploads
 	MetadataMapper             SpaceSepList      `config:"metadata_mapper"`
 }
 
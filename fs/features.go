@@ -181,6 +181,9 @@ type Features struct {
 	// Disconnect the current user
 	Disconnect func(ctx context.Context) error
 
+	// Reconnect forces a fresh authentication with the remote
+	Reconnect func(ctx context.Context) error
+
 	// Command the backend to run a named command
 	//
 	// The command run is name
@@ -341,6 +344,9 @@ func (ft *Features) Fill(ctx context.Context, f Fs) *Features {
 	if do, ok := f.(Disconnecter); ok {
 		ft.Disconnect = do.Disconnect
 	}
+	if do, ok := f.(Reconnecter); ok {
+		ft.Reconnect = do.Reconnect
+	}
 	if do, ok := f.(Commander); ok {
 		ft.Command = do.Command
 	}
@@ -447,6 +453,9 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	if mask.Disconnect == nil {
 		ft.Disconnect = nil
 	}
+	if mask.Reconnect == nil {
+		ft.Reconnect = nil
+	}
 	// Command is always local so we don't mask it
 	if mask.Shutdown == nil {
 		ft.Shutdown = nil
@@ -737,6 +746,12 @@ type Disconnecter interface {
 	Disconnect(ctx context.Context) error
 }
 
+// Reconnecter is an optional interface for Fs
+type Reconnecter interface {
+	// Reconnect forces a fresh authentication with the remote
+	Reconnect(ctx context.Context) error
+}
+
 // CommandHelp describes a single backend Command
 //
 // These are automatically inserted in the docs
@@ -24,6 +24,11 @@ var OptionsInfo = fs.Options{{
 	Default: false,
 	Help:    "Delete files on dest excluded from sync",
 	Groups:  "Filter",
+}, {
+	Name:    "delete_protect",
+	Default: []string{},
+	Help:    "Don't delete files on dest matching pattern",
+	Groups:  "Filter",
 }, {
 	Name:    "exclude_if_present",
 	Default: []string{},
@@ -130,6 +135,7 @@ var OptionsInfo = fs.Options{{
 // Options configures the filter
 type Options struct {
 	DeleteExcluded bool          `config:"delete_excluded"`
+	DeleteProtect  []string      `config:"delete_protect"`
 	RulesOpt                     // embedded so we don't change the JSON API
 	ExcludeFile    []string      `config:"exclude_if_present"`
 	FilesFrom      []string      `config:"files_from"`
@@ -165,7 +171,9 @@ type Filter struct {
 	fileRules   rules
 	dirRules    rules
 	metaRules   rules
+	protect     rules    // rules from --delete-protect
 	files       FilesMap // files if filesFrom
+	fileOrder   []string // files if filesFrom in the order they were added
 	dirs        FilesMap // dirs from filesFrom
 }
 
@@ -204,6 +212,14 @@ func NewFilter(opt *Options) (f *Filter, err error) {
 		return nil, err
 	}
 
+	for _, glob := range f.Opt.DeleteProtect {
+		re, err := GlobPathToRegexp(glob, f.Opt.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		f.protect.add(true, re)
+	}
+
 	inActive := f.InActive()
 
 	for _, rule := range f.Opt.FilesFrom {
@@ -327,6 +343,9 @@ func (f *Filter) initAddFile() {
 func (f *Filter) AddFile(file string) error {
 	f.initAddFile()
 	file = strings.Trim(file, "/")
+	if _, found := f.files[file]; !found {
+		f.fileOrder = append(f.fileOrder, file)
+	}
 	f.files[file] = struct{}{}
 	// Put all the parent directories into f.dirs
 	for {
@@ -349,6 +368,12 @@ func (f *Filter) Files() FilesMap {
 	return f.files
 }
 
+// FilesInOrder returns the files from the `--files-from` list in the
+// order they were first listed, without duplicates
+func (f *Filter) FilesInOrder() []string {
+	return f.fileOrder
+}
+
 // Clear clears all the filter rules
 func (f *Filter) Clear() {
 	f.fileRules.clear()
@@ -537,9 +562,26 @@ func (f *Filter) DumpFilters() string {
 			rules = append(rules, metaRule.String())
 		}
 	}
+	if f.protect.len() > 0 {
+		rules = append(rules, "--- Delete protect rules ---")
+		for _, protectRule := range f.protect.rules {
+			rules = append(rules, protectRule.String())
+		}
+	}
 	return strings.Join(rules, "\n")
 }
 
+// DeleteProtected returns true if remote matches a --delete-protect
+// pattern and so must not be deleted from the destination
+func (f *Filter) DeleteProtected(remote string) bool {
+	for _, rule := range f.protect.rules {
+		if rule.Match(remote) {
+			return true
+		}
+	}
+	return false
+}
+
 // HaveFilesFrom returns true if --files-from has been supplied
 func (f *Filter) HaveFilesFrom() bool {
 	return f.files != nil
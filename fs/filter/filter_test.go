@@ -92,7 +92,7 @@ func TestNewFilterWithFilesFromAlone(t *testing.T) {
 	Opt := Opt
 
 	// Set up the input
-	Opt.FilesFrom = []string{testFile(t, "#comment\nfiles1\nfiles2\n")}
+	Opt.FilesFrom = []string{testFile(t, "#comment\nfiles2\nfiles1\n/files2\n")}
 
 	rm := func(p string) {
 		err := os.Remove(p)
@@ -114,6 +114,7 @@ func TestNewFilterWithFilesFromAlone(t *testing.T) {
 			t.Errorf("Didn't find file %q in f.files", name)
 		}
 	}
+	assert.Equal(t, []string{"files2", "files1"}, f.FilesInOrder())
 }
 
 func TestNewFilterWithFilesFromRaw(t *testing.T) {
@@ -587,6 +588,34 @@ func TestNewFilterMetadataExclude(t *testing.T) {
 	assert.False(t, f.InActive())
 }
 
+func TestNewFilterDeleteProtect(t *testing.T) {
+	Opt := Opt
+	Opt.DeleteProtect = []string{"/.htaccess", "*.keep"}
+	f, err := NewFilter(&Opt)
+	require.NoError(t, err)
+	for _, test := range []struct {
+		remote string
+		want   bool
+	}{
+		{".htaccess", true},
+		{"dir/.htaccess", false},
+		{"file.keep", true},
+		{"dir/file.keep", true},
+		{"file.keeper", false},
+		{"file.txt", false},
+	} {
+		assert.Equal(t, test.want, f.DeleteProtected(test.remote), test.remote)
+	}
+	// protect rules don't filter anything
+	assert.True(t, f.InActive())
+	assert.True(t, f.IncludeRemote("file.txt"))
+	assert.Contains(t, f.DumpFilters(), "--- Delete protect rules ---")
+
+	f, err = NewFilter(nil)
+	require.NoError(t, err)
+	assert.False(t, f.DeleteProtected(".htaccess"))
+}
+
 func TestFilterAddDirRuleOrFileRule(t *testing.T) {
 	for _, test := range []struct {
 		included bool
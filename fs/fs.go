@@ -50,6 +50,7 @@ var (
 	ErrorNotImplemented              = errors.New("optional feature not implemented")
 	ErrorCommandNotFound             = errors.New("command not found")
 	ErrorFileNameTooLong             = errors.New("file name too long")
+	ErrorFileNameInvalid             = errors.New("file name invalid")
 )
 
 // CheckClose is a utility function used to check the return from
@@ -74,13 +75,38 @@ func FileExists(ctx context.Context, fs Fs, remote string) (bool, error) {
 	return true, nil
 }
 
+// EffectivePrecision returns the coarsest precision of f and all the
+// Fses it wraps, e.g. when f is a crypt or chunker remote.
+func EffectivePrecision(f Fs) time.Duration {
+	precision := f.Precision()
+	for {
+		unwrap := f.Features().UnWrap
+		if unwrap == nil {
+			break // not a wrapped Fs
+		}
+		next := unwrap()
+		if next == nil {
+			break // no base Fs found
+		}
+		f = next
+		precision = max(precision, f.Precision())
+	}
+	return precision
+}
+
 // GetModifyWindow calculates the maximum modify window between the given Fses
 // and the Config.ModifyWindow parameter.
+//
+// If any of the Fses wrap other Fses then the coarsest precision of
+// the wrapped Fses is used.
 func GetModifyWindow(ctx context.Context, fss ...Info) time.Duration {
 	window := GetConfig(ctx).ModifyWindow
 	for _, f := range fss {
 		if f != nil {
 			precision := f.Precision()
+			if f, ok := f.(Fs); ok {
+				precision = EffectivePrecision(f)
+			}
 			if precision == ModTimeNotSupported {
 				return ModTimeNotSupported
 			}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -152,6 +153,75 @@ func TestPacerCallNoRetry(t *testing.T) {
 	require.Implements(t, (*fserrors.Retrier)(nil), err)
 }
 
+func TestPacerJitter(t *testing.T) {
+	state := pacer.State{SleepTime: 100 * time.Millisecond, ConsecutiveRetries: 1}
+
+	// The Jitter of the wrapped Calculator is used
+	c := &logCalculator{pacer.NewDefault(pacer.MinSleep(1*time.Millisecond), pacer.MaxSleep(time.Second), pacer.Jitter(0.5))}
+	seen := map[time.Duration]struct{}{}
+	for i := 0; i < 100; i++ {
+		got := c.Jitter(state)
+		assert.GreaterOrEqual(t, got, 50*time.Millisecond)
+		assert.LessOrEqual(t, got, 100*time.Millisecond)
+		seen[got] = struct{}{}
+	}
+	assert.Greater(t, len(seen), 1)
+
+	// Calculators without a Jitter sleep for the SleepTime
+	c = &logCalculator{&pacer.ZeroDelayCalculator{}}
+	assert.Equal(t, state.SleepTime, c.Jitter(state))
+}
+
+func TestPacerRetryWithReauth(t *testing.T) {
+	ctx, ci := AddConfig(context.Background())
+	ci.LowLevelRetries = 3
+	p := NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(1*time.Millisecond), pacer.MaxSleep(2*time.Millisecond)))
+	errUnauthorized := errors.New("unauthorized")
+	var reauths, invalidates int
+	shouldRetry := RetryWithReauth(nil, func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		return false, err
+	}, func(ctx context.Context) error {
+		reauths++
+		return nil
+	}, func() {
+		invalidates++
+	})
+
+	// A 401 reauthorizes then retries
+	calls := 0
+	err := p.Call(func() (bool, error) {
+		calls++
+		if calls == 1 {
+			return shouldRetry(ctx, &http.Response{StatusCode: http.StatusUnauthorized}, errUnauthorized)
+		}
+		return shouldRetry(ctx, &http.Response{StatusCode: http.StatusOK}, nil)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, reauths)
+	assert.Equal(t, 1, invalidates)
+
+	// A persistent 401 fails once the retries run out
+	calls, reauths, invalidates = 0, 0, 0
+	err = p.Call(func() (bool, error) {
+		calls++
+		return shouldRetry(ctx, &http.Response{StatusCode: http.StatusUnauthorized}, errUnauthorized)
+	})
+	require.ErrorIs(t, err, errUnauthorized)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, reauths)
+	assert.Equal(t, 3, invalidates)
+
+	// A failed reauth is returned as the error
+	errAuth := errors.New("bad credentials")
+	shouldRetry = RetryWithReauth(nil, nil, func(ctx context.Context) error {
+		return errAuth
+	}, nil)
+	retry, err := shouldRetry(ctx, &http.Response{StatusCode: http.StatusUnauthorized}, errUnauthorized)
+	assert.True(t, retry)
+	assert.Equal(t, errAuth, err)
+}
+
 // Test options
 var (
 	nouncOption = Option{
@@ -374,3 +444,44 @@ func TestOptionGetters(t *testing.T) {
 	}
 
 }
+
+// precisionFs is a minimal Fs with a given precision which optionally
+// wraps another Fs
+type precisionFs struct {
+	Fs
+	precision time.Duration
+	wrapped   Fs
+}
+
+func (f *precisionFs) Precision() time.Duration { return f.precision }
+
+func (f *precisionFs) Features() *Features {
+	features := &Features{}
+	if f.wrapped != nil {
+		features.UnWrap = func() Fs { return f.wrapped }
+	}
+	return features
+}
+
+func TestEffectivePrecision(t *testing.T) {
+	ctx := context.Background()
+	base := &precisionFs{precision: time.Second}
+	wrapper := &precisionFs{precision: time.Nanosecond, wrapped: base}
+	outer := &precisionFs{precision: time.Millisecond, wrapped: wrapper}
+
+	assert.Equal(t, time.Second, EffectivePrecision(base))
+	assert.Equal(t, time.Second, EffectivePrecision(wrapper))
+	assert.Equal(t, time.Second, EffectivePrecision(outer))
+
+	// The wrapper's own precision is used if it is coarser
+	coarse := &precisionFs{precision: time.Minute, wrapped: base}
+	assert.Equal(t, time.Minute, EffectivePrecision(coarse))
+
+	// Unsupported modtimes anywhere in the chain win
+	unsupported := &precisionFs{precision: time.Nanosecond, wrapped: &precisionFs{precision: ModTimeNotSupported}}
+	assert.Equal(t, ModTimeNotSupported, EffectivePrecision(unsupported))
+
+	// GetModifyWindow uses the effective precision
+	assert.Equal(t, time.Second, GetModifyWindow(ctx, wrapper, &precisionFs{precision: time.Nanosecond}))
+	assert.Equal(t, ModTimeNotSupported, GetModifyWindow(ctx, unsupported))
+}
@@ -4,6 +4,7 @@ package march
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"path"
 	"sort"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/list"
 	"github.com/rclone/rclone/fs/walk"
+	"golang.org/x/sync/semaphore"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -31,6 +33,11 @@ type March struct {
 	Callback               Marcher         // object to call with results
 	NoCheckDest            bool            // transfer all objects regardless without checking dst
 	NoUnicodeNormalization bool            // don't normalize unicode characters in filenames
+	// DstListDir if set is used to list the destination instead of Fdst
+	DstListDir func(dir string) (entries fs.DirEntries, err error)
+	// Limiter if set is held while listing each directory so the
+	// listings share a limit on backend operations with its other users
+	Limiter *semaphore.Weighted
 	// internal state
 	srcListDir listDirFn // function to call to list a directory in the src
 	dstListDir listDirFn // function to call to list a directory in the dst
@@ -48,14 +55,34 @@ type Marcher interface {
 	Match(ctx context.Context, dst, src fs.DirEntry) (recurse bool)
 }
 
+// DirMarcher is an optional interface a Marcher can implement to be
+// told about each source directory as it is processed
+type DirMarcher interface {
+	// SkipDirObjects is called with the source listing of dir
+	// before any of its entries are passed on. If it returns true
+	// then the objects in the source listing aren't passed to
+	// SrcOnly or Match. Directories and entries only in the
+	// destination are passed on as usual.
+	SkipDirObjects(dir string, srcList fs.DirEntries) bool
+	// DirDone is called once all the entries in dir have been
+	// passed on
+	DirDone(dir string)
+}
+
 // init sets up a march over opt.Fsrc, and opt.Fdst calling back callback for each match
 // Note: this will flag filter-aware backends on the source side
 func (m *March) init(ctx context.Context) {
 	ci := fs.GetConfig(ctx)
 	m.srcListDir = m.makeListDir(ctx, m.Fsrc, m.SrcIncludeAll)
-	if !m.NoTraverse {
+	if m.DstListDir != nil {
+		m.dstListDir = m.DstListDir
+	} else if !m.NoTraverse {
 		m.dstListDir = m.makeListDir(ctx, m.Fdst, m.DstIncludeAll)
 	}
+	if m.Limiter != nil {
+		m.srcListDir = m.limitListDir(m.srcListDir)
+		m.dstListDir = m.limitListDir(m.dstListDir)
+	}
 	// Now create the matching transform
 	// ..normalise the UTF8 first
 	if !m.NoUnicodeNormalization {
@@ -77,6 +104,20 @@ func (m *March) init(ctx context.Context) {
 // list a directory into entries, err
 type listDirFn func(dir string) (entries fs.DirEntries, err error)
 
+// limitListDir wraps listDir so it holds m.Limiter while listing
+func (m *March) limitListDir(listDir listDirFn) listDirFn {
+	if listDir == nil {
+		return nil
+	}
+	return func(dir string) (entries fs.DirEntries, err error) {
+		if err := m.Limiter.Acquire(m.Ctx, 1); err != nil {
+			return nil, err
+		}
+		defer m.Limiter.Release(1)
+		return listDir(dir)
+	}
+}
+
 // makeListDir makes constructs a listing function for the given fs
 // and includeAll flags for marching through the file system.
 // Note: this will optionally flag filter-aware backends!
@@ -130,6 +171,14 @@ type listDirJob struct {
 	noDst     bool
 }
 
+// shuffleJobs puts jobs into a random order so that listing them
+// doesn't work through one prefix at a time.
+func shuffleJobs(jobs []listDirJob) {
+	rand.Shuffle(len(jobs), func(i, j int) {
+		jobs[i], jobs[j] = jobs[j], jobs[i]
+	})
+}
+
 // Run starts the matching process off
 func (m *March) Run(ctx context.Context) error {
 	ci := fs.GetConfig(ctx)
@@ -153,6 +202,7 @@ func (m *March) Run(ctx context.Context) error {
 	var wg sync.WaitGroup         // sync closing of go routines
 	var traversing sync.WaitGroup // running directory traversals
 	checkers := ci.Checkers
+	shuffle := ci.ShuffleDirs
 	in := make(chan listDirJob, checkers)
 	for i := 0; i < checkers; i++ {
 		wg.Add(1)
@@ -177,6 +227,9 @@ func (m *March) Run(ctx context.Context) error {
 						mu.Unlock()
 					}
 					if len(jobs) > 0 {
+						if shuffle {
+							shuffleJobs(jobs)
+						}
 						traversing.Add(len(jobs))
 						go func() {
 							// Now we have traversed this directory, send these
@@ -454,11 +507,19 @@ func (m *March) processJob(job listDirJob) ([]listDirJob, error) {
 	}
 
 	// Work out what to do and do it
+	dirMarcher, _ := m.Callback.(DirMarcher)
+	skipObjects := false
+	if dirMarcher != nil && !job.noSrc {
+		skipObjects = dirMarcher.SkipDirObjects(job.srcRemote, srcList)
+	}
 	srcOnly, dstOnly, matches := matchListings(srcList, dstList, m.transforms)
 	for _, src := range srcOnly {
 		if m.aborting() {
 			return nil, m.Ctx.Err()
 		}
+		if _, isObject := src.(fs.Object); isObject && skipObjects {
+			continue
+		}
 		recurse := m.Callback.SrcOnly(src)
 		if recurse && job.srcDepth > 0 {
 			jobs = append(jobs, listDirJob{
@@ -488,6 +549,9 @@ func (m *March) processJob(job listDirJob) ([]listDirJob, error) {
 		if m.aborting() {
 			return nil, m.Ctx.Err()
 		}
+		if _, isObject := match.src.(fs.Object); isObject && skipObjects {
+			continue
+		}
 		recurse := m.Callback.Match(m.Ctx, match.dst, match.src)
 		if recurse && job.srcDepth > 0 && job.dstDepth > 0 {
 			jobs = append(jobs, listDirJob{
@@ -498,5 +562,8 @@ func (m *March) processJob(job listDirJob) ([]listDirJob, error) {
 			})
 		}
 	}
+	if dirMarcher != nil && !job.noSrc {
+		dirMarcher.DirDone(job.srcRemote)
+	}
 	return jobs, nil
 }
@@ -529,3 +529,61 @@ func TestMatchListings(t *testing.T) {
 		})
 	}
 }
+
+// Check --shuffle-dirs dispatches the directory jobs out of order
+func TestShuffleJobs(t *testing.T) {
+	var jobs, want []listDirJob
+	for i := 0; i < 100; i++ {
+		remote := fmt.Sprintf("dir%03d", i)
+		jobs = append(jobs, listDirJob{srcRemote: remote, dstRemote: remote})
+	}
+	want = append(want, jobs...)
+	shuffleJobs(jobs)
+	assert.NotEqual(t, want, jobs, "should not be in lexical order")
+	assert.ElementsMatch(t, want, jobs)
+}
+
+// Check --shuffle-dirs still visits every entry
+func TestMarchShuffleDirs(t *testing.T) {
+	r := fstest.NewRun(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, ci := fs.AddConfig(ctx)
+	ci.ShuffleDirs = true
+
+	var srcOnly, dstOnly, match []fstest.Item
+	var dirSrcOnly, dirDstOnly, dirMatch []string
+	for i := 0; i < 10; i++ {
+		dir := fmt.Sprintf("dir%d", i)
+		dirMatch = append(dirMatch, dir, dir+"/sub")
+		match = append(match, r.WriteBoth(ctx, dir+"/match", "hello world", t1))
+		match = append(match, r.WriteBoth(ctx, dir+"/sub/match", "hello world", t1))
+		srcOnly = append(srcOnly, r.WriteFile(dir+"/srcOnly", "hello world", t1))
+		dstOnly = append(dstOnly, r.WriteObject(ctx, dir+"/dstOnly", "hello world", t1))
+		srcDir, dstDir := fmt.Sprintf("srcOnlyDir%d", i), fmt.Sprintf("dstOnlyDir%d", i)
+		dirSrcOnly = append(dirSrcOnly, srcDir)
+		srcOnly = append(srcOnly, r.WriteFile(srcDir+"/file", "hello world", t1))
+		dirDstOnly = append(dirDstOnly, dstDir)
+		dstOnly = append(dstOnly, r.WriteObject(ctx, dstDir+"/file", "hello world", t1))
+	}
+
+	mt := &marchTester{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	m := &March{
+		Ctx:      ctx,
+		Fdst:     r.Fremote,
+		Fsrc:     r.Flocal,
+		Dir:      "",
+		Callback: mt,
+	}
+
+	mt.processError(m.Run(ctx))
+	mt.cancel()
+	require.NoError(t, mt.currentError())
+
+	precision := fs.GetModifyWindow(ctx, r.Fremote, r.Flocal)
+	fstest.CompareItems(t, mt.srcOnly, srcOnly, dirSrcOnly, precision, "srcOnly")
+	fstest.CompareItems(t, mt.dstOnly, dstOnly, dirDstOnly, precision, "dstOnly")
+	fstest.CompareItems(t, mt.match, match, dirMatch, precision, "match")
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -44,6 +45,18 @@ type CheckOpt struct {
 	Match        io.Writer // matching files
 	Differ       io.Writer // differing files
 	Error        io.Writer // files with errors of some kind
+	Summary      io.Writer // a JSON summary of the check when it is done
+}
+
+// CheckSummary is the summary of the check written as JSON to
+// CheckOpt.Summary
+type CheckSummary struct {
+	Matches      int32 `json:"matches"`      // files which were identical
+	Differ       int32 `json:"differ"`       // files which were different
+	MissingOnSrc int32 `json:"missingOnSrc"` // files only in the destination
+	MissingOnDst int32 `json:"missingOnDst"` // files only in the source
+	Errors       int32 `json:"errors"`       // files with errors reading or hashing
+	NoHash       int32 `json:"noHash"`       // matching files whose hashes could not be checked
 }
 
 // checkMarch is used to march over two Fses in the same way as
@@ -58,6 +71,8 @@ type checkMarch struct {
 	srcFilesMissing atomic.Int32
 	dstFilesMissing atomic.Int32
 	matches         atomic.Int32
+	differ          atomic.Int32
+	errors          atomic.Int32
 	opt             CheckOpt
 }
 
@@ -67,6 +82,12 @@ func (c *checkMarch) report(o fs.DirEntry, out io.Writer, sigil rune) {
 }
 
 func (c *checkMarch) reportFilename(filename string, out io.Writer, sigil rune) {
+	switch sigil {
+	case '*':
+		c.differ.Add(1)
+	case '!':
+		c.errors.Add(1)
+	}
 	if out != nil {
 		SyncFprintf(out, "%s\n", filename)
 	}
@@ -259,6 +280,9 @@ func (c *checkMarch) reportResults(ctx context.Context, err error) error {
 	if c.matches.Load() > 0 {
 		fs.Logf(c.opt.Fdst, "%d matching files", c.matches.Load())
 	}
+	if c.opt.Summary != nil {
+		c.writeSummary()
+	}
 	if err != nil {
 		return err
 	}
@@ -271,8 +295,37 @@ func (c *checkMarch) reportResults(ctx context.Context, err error) error {
 	return nil
 }
 
+// writeSummary writes the JSON summary to c.opt.Summary
+func (c *checkMarch) writeSummary() {
+	summary := CheckSummary{
+		Matches:      c.matches.Load(),
+		Differ:       c.differ.Load(),
+		MissingOnSrc: c.srcFilesMissing.Load(),
+		MissingOnDst: c.dstFilesMissing.Load(),
+		Errors:       c.errors.Load(),
+		NoHash:       c.noHashes.Load(),
+	}
+	out, err := json.MarshalIndent(&summary, "", "\t")
+	if err != nil {
+		fs.Errorf(c.opt.Fdst, "Failed to make check summary: %v", err)
+		return
+	}
+	SyncFprintf(c.opt.Summary, "%s\n", out)
+}
+
 // Check the files in fsrc and fdst according to Size and hash
+//
+// If there is no common hash then only the sizes are checked.
 func Check(ctx context.Context, opt *CheckOpt) error {
+	ci := fs.GetConfig(ctx)
+	if !ci.SizeOnly {
+		hashType := opt.Fsrc.Hashes().Overlap(opt.Fdst.Hashes()).GetOne()
+		if hashType == hash.None {
+			fs.Logf(opt.Fdst, "No common hash found - only checking file sizes")
+		} else {
+			fs.Infof(opt.Fdst, "Using %v for hash comparisons", hashType)
+		}
+	}
 	optCopy := *opt
 	optCopy.Check = func(ctx context.Context, dst, src fs.Object) (differ bool, noHash bool, err error) {
 		same, ht, err := CheckHashes(ctx, src, dst)
@@ -530,7 +583,7 @@ func (c *checkMarch) checkSum(ctx context.Context, obj fs.Object, download bool,
 // matchSum sums up the results of hashsum matching for an object
 func (c *checkMarch) matchSum(ctx context.Context, sumHash, objHash string, obj fs.Object, err error, hashType hash.Type) {
 	switch {
-	case err != nil:
+	case err != nil && !errors.Is(err, hash.ErrUnsupported):
 		_ = fs.CountError(ctx, err)
 		fs.Errorf(obj, "Failed to calculate hash: %v", err)
 		c.report(obj, c.opt.Error, '!')
@@ -540,11 +593,10 @@ func (c *checkMarch) matchSum(ctx context.Context, sumHash, objHash string, obj
 		fs.Errorf(obj, "%v", err)
 		c.report(obj, c.opt.Error, '!')
 	case objHash == "":
+		// The hash isn't available so the file can't be verified
 		fs.Debugf(nil, "%v = %s (sum)", hashType, sumHash)
-		fs.Debugf(obj, "%v - could not check hash (%v)", hashType, c.opt.Fdst)
+		fs.Logf(obj, "Not verified as %v hash is not available (%v)", hashType, c.opt.Fdst)
 		c.noHashes.Add(1)
-		c.matches.Add(1)
-		c.report(obj, c.opt.Match, '=')
 	case objHash == sumHash:
 		fs.Debugf(obj, "%v = %s OK", hashType, sumHash)
 		c.matches.Add(1)
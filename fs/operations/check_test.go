@@ -3,6 +3,7 @@ package operations_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +18,8 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/rclone/rclone/lib/readers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -184,6 +187,80 @@ func TestCheck(t *testing.T) {
 	testCheck(t, operations.Check)
 }
 
+func TestCheckSummary(t *testing.T) {
+	ctx := context.Background()
+	newFs := func(name string, hashes hash.Set, files map[string]string) fs.Fs {
+		f, err := mockfs.NewFs(ctx, name, "", nil)
+		require.NoError(t, err)
+		f.(*mockfs.Fs).SetHashes(hashes)
+		for remote, contents := range files {
+			f.(*mockfs.Fs).AddObject(mockobject.New(remote).WithContent([]byte(contents), mockobject.SeekModeNone))
+		}
+		return f
+	}
+	srcFiles := map[string]string{
+		"match":   "same",
+		"size":    "short",
+		"hash":    "abcd",
+		"srconly": "src",
+	}
+	dstFiles := map[string]string{
+		"match":   "same",
+		"size":    "longer",
+		"hash":    "efgh",
+		"dstonly": "dst",
+	}
+	for _, test := range []struct {
+		name         string
+		hashes       hash.Set
+		wantCombined string
+		want         operations.CheckSummary
+		wantLog      string
+	}{
+		{
+			name:         "Hash",
+			hashes:       hash.NewHashSet(hash.MD5),
+			wantCombined: "* hash\n* size\n+ srconly\n- dstonly\n= match\n",
+			want:         operations.CheckSummary{Matches: 1, Differ: 2, MissingOnSrc: 1, MissingOnDst: 1},
+		}, {
+			name:         "NoHash",
+			hashes:       hash.Set(hash.None),
+			wantCombined: "* size\n+ srconly\n- dstonly\n= hash\n= match\n",
+			want:         operations.CheckSummary{Matches: 2, Differ: 1, MissingOnSrc: 1, MissingOnDst: 1, NoHash: 2},
+			wantLog:      "No common hash found - only checking file sizes",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			accounting.GlobalStats().ResetCounters()
+			var logBuf bytes.Buffer
+			log.SetOutput(&logBuf)
+			defer log.SetOutput(os.Stderr)
+
+			var combined, summary bytes.Buffer
+			opt := operations.CheckOpt{
+				Fsrc:     newFs("src", test.hashes, srcFiles),
+				Fdst:     newFs("dst", test.hashes, dstFiles),
+				Combined: &combined,
+				Summary:  &summary,
+			}
+			err := operations.Check(ctx, &opt)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), fmt.Sprintf("%d differences found", test.want.Differ+2))
+
+			lines := strings.SplitAfter(combined.String(), "\n")
+			sort.Strings(lines)
+			assert.Equal(t, test.wantCombined, strings.Join(lines, ""))
+
+			var got operations.CheckSummary
+			require.NoError(t, json.Unmarshal(summary.Bytes(), &got))
+			assert.Equal(t, test.want, got)
+			if test.wantLog != "" {
+				assert.Contains(t, logBuf.String(), test.wantLog)
+			}
+		})
+	}
+}
+
 func TestCheckFsError(t *testing.T) {
 	ctx := context.Background()
 	dstFs, err := fs.NewFs(ctx, "nonexistent")
@@ -546,6 +623,33 @@ func TestCheckSumDownload(t *testing.T) {
 	testCheckSum(t, true)
 }
 
+// Test objects without the hash aren't counted as verified
+func TestCheckSumNoHash(t *testing.T) {
+	ctx := context.Background()
+	f, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+	mf := f.(*mockfs.Fs)
+	mf.SetHashes(hash.NewHashSet(hash.MD5))
+	mf.AddObject(mockobject.New("with hash").WithContent([]byte("hello"), mockobject.SeekModeNone))
+	mf.AddObject(noHashObject{mockobject.New("without hash").WithContent([]byte("world"), mockobject.SeekModeNone)})
+	fsum, err := mockfs.NewFs(ctx, "sums", "/", nil)
+	require.NoError(t, err)
+	sums := "5d41402abc4b2a76b9719d911017c592  with hash\n7d793037a0760186574b0282f2f435e7  without hash\n"
+	fsum.(*mockfs.Fs).AddObject(mockobject.New("MD5SUMS").WithContent([]byte(sums), mockobject.SeekModeNone))
+
+	accounting.GlobalStats().ResetCounters()
+	var combined, match bytes.Buffer
+	opt := operations.CheckOpt{
+		Combined: &combined,
+		Match:    &match,
+	}
+	err = operations.CheckSum(ctx, f, fsum, "MD5SUMS", hash.MD5, &opt, false)
+	require.NoError(t, err)
+	assert.Equal(t, "= with hash\n", combined.String())
+	assert.Equal(t, "with hash\n", match.String())
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+}
+
 func TestApplyTransforms(t *testing.T) {
 	var (
 		hashType        = hash.MD5
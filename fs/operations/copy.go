@@ -89,7 +89,20 @@ func TruncateString(s string, n int) string {
 // Check to see if we should be using a partial name and return the name for the copy and the inplace flag
 func (c *copy) checkPartial(ctx context.Context) (remoteForCopy string, inplace bool, err error) {
 	remoteForCopy = c.remote
-	if c.ci.Inplace || c.dstFeatures.Move == nil || !c.dstFeatures.PartialUploads || strings.HasSuffix(c.remote, ".rclonelink") {
+	// With --safe-update existing files are always updated via a
+	// partial name so they are never left half written. Backends
+	// which allow duplicate names don't overwrite the original on
+	// Move so those are updated in place.
+	safeUpdate := c.ci.SafeUpdate && c.doUpdate && !c.dstFeatures.DuplicateFiles
+	partialUploads := c.dstFeatures.PartialUploads || safeUpdate
+	if c.ci.Inplace || c.dstFeatures.Move == nil || !partialUploads || strings.HasSuffix(c.remote, ".rclonelink") {
+		if c.ci.SafeUpdate && c.doUpdate && !c.ci.Inplace {
+			if c.dstFeatures.Move == nil {
+				fs.Debugf(c.dst, "Can't use --safe-update as the destination doesn't support Move - updating in place")
+			} else if c.dstFeatures.DuplicateFiles {
+				fs.Debugf(c.dst, "Can't use --safe-update as the destination allows duplicate file names - updating in place")
+			}
+		}
 		return remoteForCopy, true, nil
 	}
 	if len(c.ci.PartialSuffix) > 16 {
@@ -258,7 +271,7 @@ func (c *copy) manualCopy(ctx context.Context) (actionTaken string, newDst fs.Ob
 	}
 
 	var in io.ReadCloser
-	in, err = Open(ctx, c.src, downloadOptions...)
+	in, err = openLimited(ctx, c.src, downloadOptions...)
 	if err != nil {
 		return actionTaken, nil, fmt.Errorf("failed to open source object: %w", err)
 	}
@@ -278,6 +291,8 @@ func (c *copy) verify(ctx context.Context, newDst fs.Object) (err error) {
 	}
 	// Verify hashes are the same after transfer - ignoring blank hashes
 	if c.hashType != hash.None {
+		// Read the hashes directly as newDst may just have been updated
+		ctx := WithHashFn(ctx, nil)
 		// checkHashes has logs and counts errors
 		equal, _, srcSum, dstSum, _ := checkHashes(ctx, c.src, newDst, c.hashType)
 		if !equal {
@@ -287,6 +302,24 @@ func (c *copy) verify(ctx context.Context, newDst fs.Object) (err error) {
 	return nil
 }
 
+// readBack looks up the copy on the destination again and verifies it
+// for --verify-after-copy, returning a retryable error if it doesn't
+// match the source.
+func (c *copy) readBack(ctx context.Context, newDst fs.Object) error {
+	if newDst == nil {
+		return nil
+	}
+	readDst, err := c.f.NewObject(ctx, newDst.Remote())
+	if err != nil {
+		return fserrors.RetryError(fmt.Errorf("failed to read back copy: %w", err))
+	}
+	err = c.verify(ctx, readDst)
+	if err != nil {
+		return fserrors.RetryError(err)
+	}
+	return nil
+}
+
 // copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
@@ -295,6 +328,7 @@ func (c *copy) verify(ctx context.Context, newDst fs.Object) (err error) {
 func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	var actionTaken string
 	retry := true
+	readBackFailed := false
 	for tries := 0; retry && tries < c.maxTries; tries++ {
 		// Check we haven't hit any accounting limits
 		err = c.checkLimits(ctx)
@@ -315,6 +349,13 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 			break
 		}
 
+		// Look the copy up again to check it if required
+		readBackFailed = false
+		if err == nil && c.ci.VerifyAfterCopy {
+			err = c.readBack(ctx, newDst)
+			readBackFailed = err != nil
+		}
+
 		// Retry if err returned a retry error
 		retry = false
 		if fserrors.IsRetryError(err) || fserrors.ShouldRetry(err) {
@@ -333,14 +374,20 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	if err != nil {
 		err = fs.CountError(ctx, err)
 		fs.Errorf(c.src, "Failed to copy: %v", err)
+		if readBackFailed {
+			c.removeFailedCopy(ctx, newDst)
+			return nil, err
+		}
 		if !c.inplace {
 			c.removeFailedPartialCopy(ctx, c.f, c.remoteForCopy)
 		}
 		return newDst, err
 	}
 
-	// Verify the copy
-	err = c.verify(ctx, newDst)
+	// Verify the copy unless it has been looked up and verified already
+	if !c.ci.VerifyAfterCopy {
+		err = c.verify(ctx, newDst)
+	}
 	if err != nil {
 		fs.Errorf(newDst, "%v", err)
 		err = fs.CountError(ctx, err)
@@ -361,6 +408,14 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 		newDst = movedNewDst
 	}
 
+	// Set the storage tier if required
+	if c.ci.Tier != "" && newDst != nil {
+		err = c.setTier(ctx, newDst)
+		if err != nil {
+			return newDst, err
+		}
+	}
+
 	// Log what we have done
 	if newDst != nil && c.src.String() != newDst.String() {
 		actionTaken = fmt.Sprintf("%s to: %s", actionTaken, newDst.String())
@@ -370,6 +425,30 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	return newDst, nil
 }
 
+// setTier sets the storage tier of newDst to --tier if the destination
+// supports it and it isn't in that tier already.
+func (c *copy) setTier(ctx context.Context, newDst fs.Object) error {
+	if !c.dstFeatures.SetTier {
+		fs.Debugf(newDst, "Not setting tier as the destination doesn't support it")
+		return nil
+	}
+	if do, ok := newDst.(fs.GetTierer); ok && c.dstFeatures.GetTier && do.GetTier() == c.ci.Tier {
+		return nil
+	}
+	do, ok := newDst.(fs.SetTierer)
+	if !ok {
+		fs.Debugf(newDst, "Not setting tier as the object doesn't support it")
+		return nil
+	}
+	err := do.SetTier(c.ci.Tier)
+	if err != nil {
+		fs.Errorf(newDst, "Failed to set tier to %q: %v", c.ci.Tier, err)
+		return fs.CountError(ctx, err)
+	}
+	fs.Debugf(newDst, "Set tier to %q", c.ci.Tier)
+	return nil
+}
+
 // Copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
@@ -409,7 +488,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		return nil, err
 	}
 	// Do the copy now everything is set up
-	return c.copy(ctx)
+	return c.copy(withConfigOpenLimit(ctx))
 }
 
 // CopyFile moves a single file possibly to a new name
@@ -5,8 +5,10 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"regexp"
 	"runtime"
 	"sort"
 	"strings"
@@ -14,8 +16,12 @@ import (
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/rclone/rclone/lib/readers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -125,6 +131,37 @@ func TestCopyFile(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+// Test CopyFile and MoveFile overwriting a different file and
+// renaming within the same remote
+func TestCopyFileMoveFileOverwrite(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+
+	file1 := r.WriteFile("file1", "new contents", t2)
+	r.CheckLocalItems(t, file1)
+	r.WriteObject(ctx, "dst", "old contents which are longer", t1)
+
+	// Overwrite a different file with a different name
+	dst := file1
+	dst.Path = "dst"
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, dst.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckLocalItems(t, file1)
+	r.CheckRemoteItems(t, dst)
+
+	// Copy to a new name on the same remote
+	copied := file1
+	copied.Path = "sub/copied"
+	err = operations.CopyFile(ctx, r.Fremote, r.Fremote, copied.Path, dst.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, dst, copied)
+
+	// Move over an existing file on the same remote
+	err = operations.MoveFile(ctx, r.Fremote, r.Fremote, dst.Path, copied.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, dst)
+}
+
 // Find the longest file name for writing to local
 func maxLengthFileName(t *testing.T, r *fstest.Run) string {
 	require.NoError(t, r.Flocal.Mkdir(context.Background(), "")) // create the root
@@ -427,6 +464,100 @@ func TestCopyLongFileName(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+// failingObject is an object which fails part way through reading
+type failingObject struct {
+	*mockobject.ContentMockObject
+}
+
+var errFailingObject = errors.New("failing object read error")
+
+func (o failingObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	in, err := o.ContentMockObject.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(io.MultiReader(io.LimitReader(in, 5), readers.ErrorReader{Err: errFailingObject})), nil
+}
+
+// noPartialFs wraps an Fs to turn off the PartialUploads feature and
+// record the server-side moves done on it
+type noPartialFs struct {
+	fs.Fs
+	duplicateFiles bool
+	moves          []string
+}
+
+func (f *noPartialFs) Features() *fs.Features {
+	features := *f.Fs.Features()
+	features.PartialUploads = false
+	features.DuplicateFiles = f.duplicateFiles
+	features.Move = f.move
+	return &features
+}
+
+func (f *noPartialFs) move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	f.moves = append(f.moves, src.Remote()+" -> "+remote)
+	return f.Fs.Features().Move(ctx, src, remote)
+}
+
+func TestCopySafeUpdate(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+
+	if r.Fremote.Features().Move == nil {
+		t.Skip("Move not supported")
+	}
+	fdst := &noPartialFs{Fs: r.Fremote}
+
+	file1 := r.WriteObject(ctx, "file1", "original contents", t1)
+	r.CheckRemoteItems(t, file1)
+
+	// Without --safe-update the file is updated in place
+	file2 := r.WriteFile("file1", "updated contents", t2)
+	err := operations.CopyFile(ctx, fdst, r.Flocal, file2.Path, file2.Path)
+	require.NoError(t, err)
+	assert.Empty(t, fdst.moves)
+	r.CheckRemoteItems(t, file2)
+
+	ci.SafeUpdate = true
+
+	// Check the original survives a failed update and the temporary
+	// file is removed
+	ci.LowLevelRetries = 1
+	dst, err := r.Fremote.NewObject(ctx, file2.Path)
+	require.NoError(t, err)
+	srcFs, err := mockfs.NewFs(ctx, "mock", "", nil)
+	require.NoError(t, err)
+	mo := mockobject.New(file2.Path).WithContent([]byte("new contents which fail"), mockobject.SeekModeNone)
+	mo.SetFs(srcFs)
+	src := failingObject{mo}
+	_, err = operations.Copy(ctx, fdst, dst, file2.Path, src)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errFailingObject), err)
+	assert.Empty(t, fdst.moves)
+	r.CheckRemoteItems(t, file2)
+
+	// Check a successful update is uploaded to a temporary name
+	// and moved over the original
+	file3 := r.WriteFile("file1", "updated again", t3)
+	err = operations.CopyFile(ctx, fdst, r.Flocal, file3.Path, file3.Path)
+	require.NoError(t, err)
+	require.Len(t, fdst.moves, 1)
+	assert.Regexp(t, `^file1\.[0-9a-f]+`+regexp.QuoteMeta(ci.PartialSuffix)+` -> file1$`, fdst.moves[0])
+	r.CheckRemoteItems(t, file3)
+
+	// Backends which allow duplicate names are updated in place
+	// since moving wouldn't replace the original
+	fdst.moves = nil
+	fdst.duplicateFiles = true
+	file4 := r.WriteFile("file1", "updated once more", t1)
+	err = operations.CopyFile(ctx, fdst, r.Flocal, file4.Path, file4.Path)
+	require.NoError(t, err)
+	assert.Empty(t, fdst.moves)
+	r.CheckRemoteItems(t, file4)
+}
+
 func TestCopyFileMaxTransfer(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -505,3 +636,142 @@ func TestCopyFileMaxTransfer(t *testing.T) {
 	r.CheckLocalItems(t, file1, file2, file3, file4)
 	r.CheckRemoteItems(t, file1, file4)
 }
+
+// wrongHashFs wraps an Fs so the first object found with NewObject
+// reports a corrupted hash.
+type wrongHashFs struct {
+	fs.Fs
+	lookups int
+}
+
+// NewObject finds the object, corrupting the hash of the first one
+func (f *wrongHashFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	f.lookups++
+	if f.lookups == 1 {
+		return wrongHashObject{Object: o}, nil
+	}
+	return o, nil
+}
+
+// wrongHashObject is an Object which returns a bad hash
+type wrongHashObject struct {
+	fs.Object
+}
+
+// Hash returns a hash which won't match anything
+func (o wrongHashObject) Hash(ctx context.Context, ht hash.Type) (string, error) {
+	return "badhash", nil
+}
+
+func TestCopyFileVerifyAfterCopy(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer accounting.Stats(ctx).ResetCounters()
+
+	ht, _ := operations.CommonHash(ctx, r.Fremote, r.Flocal)
+	if ht == hash.None {
+		t.Skip("skipping test as no common hash")
+	}
+	ci.VerifyAfterCopy = true
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+
+	fdst := &wrongHashFs{Fs: r.Fremote}
+	err := operations.CopyFile(ctx, fdst, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fdst.lookups, "expecting the copy to be read back twice")
+	assert.Equal(t, int64(0), accounting.Stats(ctx).GetErrors())
+	r.CheckLocalItems(t, file1)
+	r.CheckRemoteItems(t, file1)
+}
+
+// tieredFs wraps an Fs so the objects it uploads have a storage tier
+type tieredFs struct {
+	fs.Fs
+	features fs.Features
+	objects  []*tieredObject
+}
+
+func newTieredFs(f fs.Fs) *tieredFs {
+	tf := &tieredFs{Fs: f, features: *f.Features()}
+	tf.features.SetTier = true
+	tf.features.GetTier = true
+	return tf
+}
+
+// Features returns the optional features of this Fs
+func (f *tieredFs) Features() *fs.Features {
+	return &f.features
+}
+
+// Put uploads the object into the default "hot" tier
+func (f *tieredFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o, err := f.Fs.Put(ctx, in, src, options...)
+	if err != nil {
+		return nil, err
+	}
+	to := &tieredObject{Object: o, tier: "hot"}
+	f.objects = append(f.objects, to)
+	return to, nil
+}
+
+// tieredObject is an Object with a storage tier
+type tieredObject struct {
+	fs.Object
+	tier     string
+	setTiers int
+}
+
+// GetTier returns the storage tier of the object
+func (o *tieredObject) GetTier() string {
+	return o.tier
+}
+
+// SetTier sets the storage tier of the object
+func (o *tieredObject) SetTier(tier string) error {
+	o.setTiers++
+	o.tier = tier
+	return nil
+}
+
+func TestCopyFileTier(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+	// The partial file rename would lose the wrapper
+	ci.Inplace = true
+
+	for _, test := range []struct {
+		tier     string
+		want     string
+		setTiers int
+	}{
+		{tier: "", want: "hot", setTiers: 0},
+		{tier: "hot", want: "hot", setTiers: 0},
+		{tier: "cold", want: "cold", setTiers: 1},
+	} {
+		ci.Tier = test.tier
+		fdst := newTieredFs(r.Fremote)
+		err := operations.CopyFile(ctx, fdst, r.Flocal, file1.Path, file1.Path)
+		require.NoError(t, err)
+		require.Len(t, fdst.objects, 1)
+		assert.Equal(t, test.want, fdst.objects[0].tier, test.tier)
+		assert.Equal(t, test.setTiers, fdst.objects[0].setTiers, test.tier)
+		r.CheckRemoteItems(t, file1)
+		require.NoError(t, operations.DeleteFile(ctx, fstest.NewObject(ctx, t, r.Fremote, file1.Path)))
+	}
+
+	// Destinations which don't support tiers are skipped
+	ci.Tier = "cold"
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1)
+}
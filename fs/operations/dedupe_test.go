@@ -2,6 +2,8 @@ package operations_test
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/rclone/rclone/lib/random"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
@@ -278,3 +282,171 @@ func TestMergeDirs(t *testing.T) {
 	assert.Equal(t, 0, len(objs))
 	assert.Equal(t, "dupe1", dirs[0].Remote())
 }
+
+// dupFs is a mock Fs which allows objects with duplicate names, like
+// remotes which identify objects by ID do
+type dupFs struct {
+	*mockfs.Fs
+	features *fs.Features
+	objs     []*dupObject
+}
+
+// dupObject is an object in a dupFs
+type dupObject struct {
+	*mockobject.ContentMockObject
+	f      *dupFs
+	id     string
+	remote string
+}
+
+func newDupFs(t *testing.T) *dupFs {
+	ctx := context.Background()
+	mf, err := mockfs.NewFs(ctx, "dup", "", nil)
+	require.NoError(t, err)
+	f := &dupFs{Fs: mf.(*mockfs.Fs)}
+	f.SetHashes(hash.NewHashSet(hash.MD5))
+	f.features = (&fs.Features{DuplicateFiles: true}).Fill(ctx, f)
+	return f
+}
+
+func (f *dupFs) add(remote, content string, modTime time.Time) {
+	o := &dupObject{
+		ContentMockObject: mockobject.New(remote).WithContent([]byte(content), mockobject.SeekModeNone),
+		f:                 f,
+		id:                fmt.Sprintf("id%d", len(f.objs)),
+		remote:            remote,
+	}
+	o.SetFs(f)
+	_ = o.SetModTime(context.Background(), modTime)
+	f.objs = append(f.objs, o)
+}
+
+// contents returns "remote=content" for each object sorted
+func (f *dupFs) contents() (out []string) {
+	for _, o := range f.objs {
+		out = append(out, o.remote+"="+string(o.content()))
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (f *dupFs) Features() *fs.Features {
+	return f.features
+}
+
+func (f *dupFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	if dir != "" {
+		return nil, fs.ErrorDirNotFound
+	}
+	for _, o := range f.objs {
+		entries = append(entries, o)
+	}
+	return entries, nil
+}
+
+func (f *dupFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	for _, o := range f.objs {
+		if o.remote == remote {
+			return o, nil
+		}
+	}
+	return nil, fs.ErrorObjectNotFound
+}
+
+func (f *dupFs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	o := src.(*dupObject)
+	o.remote = remote
+	return o, nil
+}
+
+func (o *dupObject) Remote() string {
+	return o.remote
+}
+
+func (o *dupObject) String() string {
+	return o.remote
+}
+
+func (o *dupObject) ID() string {
+	return o.id
+}
+
+func (o *dupObject) content() []byte {
+	in, err := o.Open(context.Background())
+	if err != nil {
+		return nil
+	}
+	buf := make([]byte, o.Size())
+	_, _ = in.Read(buf)
+	return buf
+}
+
+func (o *dupObject) Remove(ctx context.Context) error {
+	i := slices.Index(o.f.objs, o)
+	if i < 0 {
+		return fs.ErrorObjectNotFound
+	}
+	o.f.objs = slices.Delete(o.f.objs, i, i+1)
+	return nil
+}
+
+// Check each mode against a mock remote with duplicate names, so the
+// modes are tested even when the test remote can't have duplicates
+func TestDeduplicateMock(t *testing.T) {
+	ctx := context.Background()
+	all := []string{"one.txt=largest of them all", "one.txt=medium", "one.txt=small", "two.txt=unique"}
+	for _, test := range []struct {
+		mode operations.DeduplicateMode
+		want []string
+	}{
+		{operations.DeduplicateSkip, all},
+		{operations.DeduplicateList, all},
+		{operations.DeduplicateNewest, []string{"one.txt=medium", "two.txt=unique"}},
+		{operations.DeduplicateOldest, []string{"one.txt=largest of them all", "two.txt=unique"}},
+		{operations.DeduplicateLargest, []string{"one.txt=largest of them all", "two.txt=unique"}},
+		{operations.DeduplicateSmallest, []string{"one.txt=small", "two.txt=unique"}},
+	} {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			f := newDupFs(t)
+			f.add("one.txt", "small", t2)
+			f.add("one.txt", "medium", t3)
+			f.add("one.txt", "largest of them all", t1)
+			f.add("one.txt", "small", t2) // identical so always removed unless listing
+			f.add("two.txt", "unique", t1)
+
+			err := operations.Deduplicate(ctx, f, test.mode, false)
+			require.NoError(t, err)
+			want := test.want
+			if test.mode == operations.DeduplicateList {
+				want = slices.Insert(slices.Clone(want), 2, "one.txt=small")
+			}
+			assert.Equal(t, want, f.contents())
+		})
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		f := newDupFs(t)
+		f.add("one.txt", "small", t2)
+		f.add("one.txt", "medium", t3)
+		f.add("one.txt", "medium", t3)
+		f.add("one-1.txt", "existing", t1)
+
+		err := operations.Deduplicate(ctx, f, operations.DeduplicateRename, false)
+		require.NoError(t, err)
+
+		// Which duplicate gets which name depends on the
+		// listing order so just check the names and contents
+		var names, contents []string
+		for _, o := range f.objs {
+			names = append(names, o.remote)
+			contents = append(contents, string(o.content()))
+		}
+		slices.Sort(names)
+		slices.Sort(contents)
+		assert.Equal(t, []string{"one-1.txt", "one-2.txt", "one-3.txt"}, names)
+		assert.Equal(t, []string{"existing", "medium", "small"}, contents)
+		o, err := f.NewObject(ctx, "one-1.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "existing", string(o.(*dupObject).content()))
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/spf13/pflag"
 )
@@ -214,7 +215,7 @@ func WinningSide(ctx context.Context, sigil Sigil, src, dst fs.DirEntry, err err
 
 	switch sigil {
 	case MissingOnSrc:
-		if opt.DeleteModeOff || ci.DryRun { // i.e. it's a copy, not sync (or it's a DryRun)
+		if opt.DeleteModeOff || ci.DryRun || (dstOk && filter.GetConfig(ctx).DeleteProtected(dst.Remote())) { // i.e. it's a copy, not sync (or it's a DryRun or protected by --delete-protect)
 			winner.Obj = dst
 			winner.Side = "dst" // whatever's on dst will remain so after DryRun
 			return winner
@@ -234,10 +235,14 @@ func WinningSide(ctx context.Context, sigil Sigil, src, dst fs.DirEntry, err err
 			winner.Obj = src
 			winner.Side = "src" // copy src to dst unconditionally
 		}
-		if (sigil == Match || sigil == Differ) && (ci.IgnoreExisting || ci.Immutable) {
+		if (sigil == Match || sigil == Differ) && (ci.IgnoreExisting || ci.NoOverwrite || ci.Immutable) {
 			winner.Obj = dst
 			winner.Side = "dst" // dst should remain unchanged if it already exists (and we know it does because it's Match or Differ)
 		}
+		if sigil == Differ && ci.SkipEmptyOverwrite && dstOk && src.Size() == 0 && dst.Size() > 0 {
+			winner.Obj = dst
+			winner.Side = "dst" // dst isn't overwritten by an empty src
+		}
 		if ci.DryRun {
 			winner.Obj = dst
 			winner.Side = "dst" // dst should remain unchanged after DryRun (note that we handled MissingOnDst earlier)
@@ -81,6 +81,12 @@ func (mc *multiThreadCopyState) copyChunk(ctx context.Context, chunk int, writer
 
 	fs.Debugf(mc.src, "multi-thread copy: chunk %d/%d (%d-%d) size %v starting", chunk+1, mc.numChunks, start, end, fs.SizeSuffix(size))
 
+	// Each stream holds the source open so counts towards --max-open-files
+	release, err := acquireOpen(ctx)
+	if err != nil {
+		return fmt.Errorf("multi-thread copy: %w", err)
+	}
+	defer release()
 	rc, err := Open(ctx, mc.src, &fs.RangeOption{Start: start, End: end - 1})
 	if err != nil {
 		return fmt.Errorf("multi-thread copy: failed to open source: %w", err)
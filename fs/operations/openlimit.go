@@ -0,0 +1,82 @@
+package operations
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/sync/semaphore"
+)
+
+// openLimitContextKey is the key for the --max-open-files semaphore
+type openLimitContextKey struct{}
+
+var openLimitKey = openLimitContextKey{}
+
+// WithOpenLimit returns a copy of ctx in which copies may have at
+// most n source objects open for reading at once.
+//
+// If n isn't positive ctx is returned unchanged.
+func WithOpenLimit(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, openLimitKey, semaphore.NewWeighted(int64(n)))
+}
+
+// withConfigOpenLimit returns ctx with an open limit from
+// --max-open-files if ctx doesn't have one already, so copies made
+// outside a sync, like copyto and moveto, are bounded too.
+func withConfigOpenLimit(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(openLimitKey).(*semaphore.Weighted); ok {
+		return ctx
+	}
+	return WithOpenLimit(ctx, fs.GetConfig(ctx).MaxOpenFiles)
+}
+
+// acquireOpen waits for a slot in the open limit in ctx, if any, and
+// returns a function to release it.
+func acquireOpen(ctx context.Context) (release func(), err error) {
+	sem, ok := ctx.Value(openLimitKey).(*semaphore.Weighted)
+	if !ok {
+		return func() {}, nil
+	}
+	if err := sem.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sem.Release(1)
+		})
+	}, nil
+}
+
+// openLimited opens o with Open once the open limit in ctx, if any,
+// allows. The slot is released when the returned reader is closed.
+func openLimited(ctx context.Context, o fs.Object, options ...fs.OpenOption) (io.ReadCloser, error) {
+	release, err := acquireOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in, err := Open(ctx, o, options...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &openLimitReader{ReadCloser: in, release: release}, nil
+}
+
+// openLimitReader releases its slot in the open limit when closed
+type openLimitReader struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close the reader and release the slot
+func (r *openLimitReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenLimit(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+
+	// tryAcquire returns whether a slot could be had straight away
+	tryAcquire := func(ctx context.Context) (func(), bool) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		release, err := acquireOpen(ctx)
+		if err != nil {
+			return nil, false
+		}
+		return release, true
+	}
+
+	// No limit without --max-open-files
+	limitCtx := withConfigOpenLimit(ctx)
+	for i := 0; i < 4; i++ {
+		_, ok := tryAcquire(limitCtx)
+		require.True(t, ok)
+	}
+
+	// --max-open-files is used if the context doesn't have a limit
+	ci.MaxOpenFiles = 1
+	limitCtx = withConfigOpenLimit(ctx)
+	release, ok := tryAcquire(limitCtx)
+	require.True(t, ok)
+	_, ok = tryAcquire(limitCtx)
+	assert.False(t, ok)
+
+	// Releasing more than once only frees one slot
+	release()
+	release()
+	release, ok = tryAcquire(limitCtx)
+	require.True(t, ok)
+	_, ok = tryAcquire(limitCtx)
+	assert.False(t, ok)
+	release()
+
+	// A limit already in the context is kept
+	limitCtx = withConfigOpenLimit(WithOpenLimit(ctx, 2))
+	for i := 0; i < 2; i++ {
+		_, ok = tryAcquire(limitCtx)
+		require.True(t, ok)
+	}
+	_, ok = tryAcquire(limitCtx)
+	assert.False(t, ok)
+}
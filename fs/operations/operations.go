@@ -68,6 +68,30 @@ func CheckHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (equal b
 
 var errNoHash = errors.New("no hash available")
 
+// HashFn allows replacing how checkHashes reads the hash of an
+// object, for example to serve it from a cache
+type (
+	HashFn           func(ctx context.Context, o fs.ObjectInfo, ht hash.Type) (string, error)
+	hashFnContextKey struct{}
+)
+
+var hashFnKey = hashFnContextKey{}
+
+// WithHashFn stores hashFn in ctx and returns a copy of ctx in which hashFnKey = hashFn
+//
+// Pass a nil hashFn to read the hashes from the objects directly.
+func WithHashFn(ctx context.Context, hashFn HashFn) context.Context {
+	return context.WithValue(ctx, hashFnKey, hashFn)
+}
+
+// getHash reads the hash of o using the HashFn in ctx if set
+func getHash(ctx context.Context, o fs.ObjectInfo, ht hash.Type) (string, error) {
+	if hashFn, ok := ctx.Value(hashFnKey).(HashFn); ok && hashFn != nil {
+		return hashFn(ctx, o, ht)
+	}
+	return o.Hash(ctx, ht)
+}
+
 // checkHashes does the work of CheckHashes but takes a hash.Type and
 // returns the effective hash type used.
 func checkHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object, ht hash.Type) (equal bool, htOut hash.Type, srcHash, dstHash string, err error) {
@@ -75,7 +99,7 @@ func checkHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object, ht hash.
 	g, ctx := errgroup.WithContext(ctx)
 	var srcErr, dstErr error
 	g.Go(func() (err error) {
-		srcHash, srcErr = src.Hash(ctx, ht)
+		srcHash, srcErr = getHash(ctx, src, ht)
 		if srcErr != nil {
 			return srcErr
 		}
@@ -86,7 +110,7 @@ func checkHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object, ht hash.
 		return nil
 	})
 	g.Go(func() (err error) {
-		dstHash, dstErr = dst.Hash(ctx, ht)
+		dstHash, dstErr = getHash(ctx, dst, ht)
 		if dstErr != nil {
 			return dstErr
 		}
@@ -136,6 +160,10 @@ func checkHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object, ht hash.
 // considered to be equal.  In this case the mtime on the dst is
 // updated if --checksum is not set.
 //
+// If --quick-compare is set then files of at least
+// --quick-compare-cutoff are compared with a hash of their first and
+// last --quick-compare-bytes instead of the full hash.
+//
 // Otherwise the file is considered to be not equal including if there
 // were errors reading info.
 func Equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object) bool {
@@ -151,7 +179,7 @@ func DirsEqual(ctx context.Context, src, dst fs.Directory, opt DirsEqualOpt) (eq
 		return false
 	}
 	ci := fs.GetConfig(ctx)
-	if ci.SizeOnly || ci.Immutable || ci.IgnoreExisting || opt.ModifyWindow == fs.ModTimeNotSupported {
+	if ci.SizeOnly || ci.Immutable || ci.IgnoreExisting || ci.NoOverwrite || opt.ModifyWindow == fs.ModTimeNotSupported {
 		return true
 	}
 	if ci.IgnoreTimes {
@@ -254,6 +282,16 @@ func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, opt equalOpt)
 
 	// If checking checksum and not modtime
 	if opt.checkSum {
+		// Compare the ends of large files if --quick-compare is set
+		if srcObj, ok := quickCompareObjects(ctx, src, dst); ok {
+			if !quickEqual(ctx, srcObj, dst) {
+				logger(ctx, Differ, src, dst, nil)
+				return false
+			}
+			fs.Debugf(src, "Size and quick compare hash of src and dst objects identical")
+			logger(ctx, Match, src, dst, nil)
+			return true
+		}
 		// Check the hash
 		same, ht, _ := CheckHashes(ctx, src, dst)
 		if !same {
@@ -296,17 +334,25 @@ func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, opt equalOpt)
 		fs.Debugf(src, "Modification times differ by %s: %v, %v", dt, srcModTime, dstModTime)
 	}
 
-	// Check if the hashes are the same
-	same, ht, _ := CheckHashes(ctx, src, dst)
-	if !same {
-		fs.Debugf(src, "%v differ", ht)
-		logger(ctx, Differ, src, dst, nil)
-		return false
-	}
-	if ht == hash.None && !ci.RefreshTimes {
-		// if couldn't check hash, return that they differ
-		logger(ctx, Differ, src, dst, nil)
-		return false
+	if srcObj, ok := quickCompareObjects(ctx, src, dst); ok {
+		// Compare the ends of large files instead of the hashes
+		if !quickEqual(ctx, srcObj, dst) {
+			logger(ctx, Differ, src, dst, nil)
+			return false
+		}
+	} else {
+		// Check if the hashes are the same
+		same, ht, _ := CheckHashes(ctx, src, dst)
+		if !same {
+			fs.Debugf(src, "%v differ", ht)
+			logger(ctx, Differ, src, dst, nil)
+			return false
+		}
+		if ht == hash.None && !ci.RefreshTimes {
+			// if couldn't check hash, return that they differ
+			logger(ctx, Differ, src, dst, nil)
+			return false
+		}
 	}
 
 	// mod time differs but hash is the same to reset mod time if required
@@ -584,16 +630,23 @@ func DeleteFile(ctx context.Context, dst fs.Object) (err error) {
 // DeleteFilesWithBackupDir removes all the files passed in the
 // channel
 //
+// The deletes are run in parallel using --deleters workers, or
+// --checkers workers if that isn't set.
+//
 // If backupDir is set the files will be placed into that directory
 // instead of being deleted.
 func DeleteFilesWithBackupDir(ctx context.Context, toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error {
 	var wg sync.WaitGroup
 	ci := fs.GetConfig(ctx)
-	wg.Add(ci.Checkers)
+	deleters := ci.Deleters
+	if deleters <= 0 {
+		deleters = ci.Checkers
+	}
+	wg.Add(deleters)
 	var errorCount atomic.Int32
 	var fatalErrorCount atomic.Int32
 
-	for i := 0; i < ci.Checkers; i++ {
+	for i := 0; i < deleters; i++ {
 		go func() {
 			defer wg.Done()
 			for dst := range toBeDeleted {
@@ -952,11 +1005,11 @@ func HashSum(ctx context.Context, ht hash.Type, base64Encoded bool, downloadFlag
 	return sum, nil
 }
 
-// HashLister does an md5sum equivalent for the hash type passed in
-// Updated to handle both standard hex encoding and base64
-// Updated to perform multiple hashes concurrently
-func HashLister(ctx context.Context, ht hash.Type, outputBase64 bool, downloadFlag bool, f fs.Fs, w io.Writer) error {
-	width := hash.Width(ht, outputBase64)
+// hashList calls fn with the hash of type ht for each object in f
+//
+// Objects which don't have the hash available are skipped with a
+// warning rather than being counted as errors.
+func hashList(ctx context.Context, ht hash.Type, outputBase64 bool, downloadFlag bool, f fs.Fs, fn func(o fs.Object, sum string)) error {
 	// Use --checkers concurrency unless downloading in which case use --transfers
 	concurrency := fs.GetConfig(ctx).Checkers
 	if downloadFlag {
@@ -973,17 +1026,66 @@ func HashLister(ctx context.Context, ht hash.Type, outputBase64 bool, downloadFl
 				wg.Done()
 			}()
 			sum, err := HashSum(ctx, ht, outputBase64, downloadFlag, o)
+			if errors.Is(err, hash.ErrUnsupported) || (err == nil && sum == "") {
+				fs.Logf(o, "Skipping as %v hash is not available", ht)
+				return
+			}
 			if err != nil {
 				fs.Errorf(o, "%v", fs.CountError(ctx, err))
 				return
 			}
-			SyncFprintf(w, "%*s  %s\n", width, sum, o.Remote())
+			fn(o, sum)
 		}()
 	})
 	wg.Wait()
 	return err
 }
 
+// HashLister does an md5sum equivalent for the hash type passed in
+// Updated to handle both standard hex encoding and base64
+// Updated to perform multiple hashes concurrently
+func HashLister(ctx context.Context, ht hash.Type, outputBase64 bool, downloadFlag bool, f fs.Fs, w io.Writer) error {
+	width := hash.Width(ht, outputBase64)
+	return hashList(ctx, ht, outputBase64, downloadFlag, f, func(o fs.Object, sum string) {
+		SyncFprintf(w, "%*s  %s\n", width, sum, o.Remote())
+	})
+}
+
+// HashSumItem is an entry in the output of HashListerJSON
+type HashSumItem struct {
+	Path string
+	Hash string
+}
+
+// HashListerJSON is like HashLister but outputs a JSON array of
+// HashSumItem instead of md5sum compatible lines
+func HashListerJSON(ctx context.Context, ht hash.Type, outputBase64 bool, downloadFlag bool, f fs.Fs, w io.Writer) error {
+	var (
+		mu    sync.Mutex
+		first = true
+	)
+	SyncFprintf(w, "[\n")
+	err := hashList(ctx, ht, outputBase64, downloadFlag, f, func(o fs.Object, sum string) {
+		out, err := json.Marshal(HashSumItem{Path: o.Remote(), Hash: sum})
+		if err != nil {
+			fs.Errorf(o, "failed to marshal hash to JSON: %v", fs.CountError(ctx, err))
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !first {
+			SyncFprintf(w, ",\n")
+		}
+		first = false
+		SyncFprintf(w, "%s", out)
+	})
+	if !first {
+		SyncFprintf(w, "\n")
+	}
+	SyncFprintf(w, "]\n")
+	return err
+}
+
 // HashSumStream outputs a line compatible with md5sum to w based on the
 // input stream in and the hash type ht passed in. If outputBase64 is
 // set then the hash will be base64 instead of hexadecimal.
@@ -1008,7 +1110,9 @@ func HashSumStream(ht hash.Type, outputBase64 bool, in io.ReadCloser, w io.Write
 
 // Count counts the objects and their sizes in the Fs
 //
-// Obeys includes and excludes
+// Obeys includes and excludes. It uses ListR if the backend supports
+// it. Objects with unknown size are counted in sizelessObjects and
+// not added to size.
 func Count(ctx context.Context, f fs.Fs) (objects int64, size int64, sizelessObjects int64, err error) {
 	err = ListFn(ctx, f, func(o fs.Object) {
 		atomic.AddInt64(&objects, 1)
@@ -1022,6 +1126,66 @@ func Count(ctx context.Context, f fs.Fs) (objects int64, size int64, sizelessObj
 	return
 }
 
+// NormalizeModTimes sets the modification time of each object in f
+// to its value truncated to the precision of f.
+//
+// Backends which store times more precisely than they report them can
+// otherwise return a different time on each read so syncs against
+// them never settle. Objects whose time can't be set are logged and
+// an error wrapping fs.ErrorCantSetModTime is returned with the count
+// of them. Nothing is listed if f stores times to the nanosecond.
+//
+// Obeys includes and excludes.
+func NormalizeModTimes(ctx context.Context, f fs.Fs) (err error) {
+	precision := f.Precision()
+	if precision == fs.ModTimeNotSupported {
+		return fmt.Errorf("%v doesn't support modification times: %w", f, fs.ErrorCantSetModTime)
+	}
+	if precision <= time.Nanosecond {
+		fs.Debugf(f, "Not normalizing modification times as they are stored to the nanosecond")
+		return nil
+	}
+	var (
+		mu            sync.Mutex
+		unsupported   int
+		setModTimeErr error
+	)
+	err = ListFn(ctx, f, func(o fs.Object) {
+		modTime := o.ModTime(ctx)
+		normalized := modTime.Truncate(precision)
+		if modTime.Equal(normalized) {
+			return
+		}
+		if SkipDestructive(ctx, o, "normalize modification time") {
+			return
+		}
+		err := o.SetModTime(ctx, normalized)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err == nil:
+			fs.Debugf(o, "Normalized modification time from %v to %v", modTime, normalized)
+		case errors.Is(err, fs.ErrorCantSetModTime), errors.Is(err, fs.ErrorCantSetModTimeWithoutDelete):
+			fs.Logf(o, "Can't normalize modification time: %v", err)
+			unsupported++
+		default:
+			err = fs.CountError(ctx, err)
+			fs.Errorf(o, "Failed to normalize modification time: %v", err)
+			setModTimeErr = err
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if setModTimeErr != nil {
+		return setModTimeErr
+	}
+	if unsupported > 0 {
+		return fmt.Errorf("couldn't normalize the modification time of %d objects: %w", unsupported, fs.ErrorCantSetModTime)
+	}
+	return nil
+}
+
 // ConfigMaxDepth returns the depth to use for a recursive or non recursive listing.
 func ConfigMaxDepth(ctx context.Context, recursive bool) int {
 	ci := fs.GetConfig(ctx)
@@ -1059,6 +1223,42 @@ func Mkdir(ctx context.Context, f fs.Fs, dir string) error {
 	return nil
 }
 
+// MkdirAll makes a destination directory along with any parent
+// directories which don't exist yet.
+//
+// Most backends create the parents in Mkdir, so this tries to make
+// dir first and only creates the parents, shallowest first, if that
+// fails with fs.ErrorDirNotFound.
+//
+// Bucket-based backends don't have real directories, so for these
+// only the bucket (if any) is made by a single call to Mkdir.
+func MkdirAll(ctx context.Context, f fs.Fs, dir string) error {
+	if SkipDestructive(ctx, fs.LogDirName(f, dir), "make directory") {
+		return nil
+	}
+	fs.Debugf(fs.LogDirName(f, dir), "Making directory")
+	err := f.Mkdir(ctx, dir)
+	if errors.Is(err, fs.ErrorDirNotFound) && dir != "" && !f.Features().BucketBased {
+		var parents []string
+		for parent := path.Dir(dir); parent != "." && parent != "/"; parent = path.Dir(parent) {
+			parents = append(parents, parent)
+		}
+		err = nil
+		for i := len(parents) - 1; i >= 0 && err == nil; i-- {
+			fs.Debugf(fs.LogDirName(f, parents[i]), "Making parent directory")
+			err = f.Mkdir(ctx, parents[i])
+		}
+		if err == nil {
+			err = f.Mkdir(ctx, dir)
+		}
+	}
+	if err != nil {
+		err = fs.CountError(ctx, err)
+		return err
+	}
+	return nil
+}
+
 // MkdirMetadata makes a destination directory or container with metadata
 //
 // If the destination Fs doesn't support this it will fall back to
@@ -1140,7 +1340,14 @@ func Rmdir(ctx context.Context, f fs.Fs, dir string) error {
 }
 
 // Purge removes a directory and all of its contents
+//
+// If filters are in use then only the files matching them are
+// removed, along with any directories left empty apart from dir, as
+// the backend's Purge can't filter.
 func Purge(ctx context.Context, f fs.Fs, dir string) (err error) {
+	if !filter.GetConfig(ctx).InActive() {
+		return purgeFiltered(ctx, f, dir)
+	}
 	doFallbackPurge := true
 	if doPurge := f.Features().Purge; doPurge != nil {
 		doFallbackPurge = false
@@ -1155,7 +1362,7 @@ func Purge(ctx context.Context, f fs.Fs, dir string) (err error) {
 	}
 	if doFallbackPurge {
 		// DeleteFiles and Rmdir observe --dry-run
-		err = DeleteFiles(ctx, listToChan(ctx, f, dir))
+		err = DeleteFiles(ctx, listToChan(ctx, f, dir, true))
 		if err != nil {
 			return err
 		}
@@ -1168,6 +1375,24 @@ func Purge(ctx context.Context, f fs.Fs, dir string) (err error) {
 	return nil
 }
 
+// purgeFiltered removes the files in dir matching the filters and
+// then any directories left empty, leaving dir itself.
+func purgeFiltered(ctx context.Context, f fs.Fs, dir string) error {
+	fs.Infof(fs.LogDirName(f, dir), "Filters are in use so only removing the matching files instead of purging")
+	// DeleteFiles and Rmdirs observe --dry-run
+	err := DeleteFiles(ctx, listToChan(ctx, f, dir, false))
+	if err != nil {
+		return err
+	}
+	// Look for empty directories without the filters so directories
+	// still containing files which didn't match aren't removed.
+	fi, err := filter.NewFilter(nil)
+	if err != nil {
+		return err
+	}
+	return Rmdirs(filter.ReplaceConfig(ctx, fi), f, dir, true)
+}
+
 // Delete removes all the contents of a container.  Unlike Purge, it
 // obeys includes and excludes.
 func Delete(ctx context.Context, f fs.Fs) error {
@@ -1244,13 +1469,15 @@ func RemoveExisting(ctx context.Context, f fs.Fs, remote string, operation strin
 // If an error occurs, the error will be logged, and it will close the
 // channel.
 //
-// If the error was ErrorDirNotFound then it will be ignored
-func listToChan(ctx context.Context, f fs.Fs, dir string) fs.ObjectsChan {
+// If the error was ErrorDirNotFound then it will be ignored.
+//
+// If includeAll is set then the filters are ignored.
+func listToChan(ctx context.Context, f fs.Fs, dir string, includeAll bool) fs.ObjectsChan {
 	ci := fs.GetConfig(ctx)
 	o := make(fs.ObjectsChan, ci.Checkers)
 	go func() {
 		defer close(o)
-		err := walk.ListR(ctx, f, dir, true, ci.MaxDepth, walk.ListObjects, func(entries fs.DirEntries) error {
+		err := walk.ListR(ctx, f, dir, includeAll, ci.MaxDepth, walk.ListObjects, func(entries fs.DirEntries) error {
 			entries.ForObject(func(obj fs.Object) {
 				o <- obj
 			})
@@ -1721,30 +1948,63 @@ func CompareOrCopyDest(ctx context.Context, fdst fs.Fs, dst, src fs.Object, Comp
 	return false, nil
 }
 
+// SkipReason says why NeedTransferReason decided a file didn't need
+// transferring
+type SkipReason string
+
+// The reasons files are skipped
+const (
+	SkipUnchanged      SkipReason = "unchanged"
+	SkipNoOverwrite    SkipReason = "no overwrite"
+	SkipIgnoreExisting SkipReason = "ignore existing"
+	SkipEmptyOverwrite SkipReason = "empty overwrite"
+	SkipDstNewer       SkipReason = "destination newer"
+	SkipCompareDest    SkipReason = "compare dest"
+)
+
 // NeedTransfer checks to see if src needs to be copied to dst using
 // the current config.
 //
 // Returns a flag which indicates whether the file needs to be
 // transferred or not.
 func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
+	needTransfer, _ := NeedTransferReason(ctx, dst, src)
+	return needTransfer
+}
+
+// NeedTransferReason is like NeedTransfer but also returns the reason
+// the file doesn't need transferring if it doesn't.
+func NeedTransferReason(ctx context.Context, dst, src fs.Object) (bool, SkipReason) {
 	ci := fs.GetConfig(ctx)
 	logger, _ := GetLogger(ctx)
 	if dst == nil {
 		fs.Debugf(src, "Need to transfer - File not found at Destination")
 		logger(ctx, MissingOnDst, src, nil, nil)
-		return true
+		return true, ""
+	}
+	// If we should only fill gaps in the destination, don't transfer
+	if ci.NoOverwrite {
+		fs.Debugf(src, "Destination exists and --no-overwrite is set, skipping")
+		logger(ctx, Match, src, dst, nil)
+		return false, SkipNoOverwrite
 	}
 	// If we should ignore existing files, don't transfer
 	if ci.IgnoreExisting {
 		fs.Debugf(src, "Destination exists, skipping")
 		logger(ctx, Match, src, dst, nil)
-		return false
+		return false, SkipIgnoreExisting
+	}
+	// If the source has become empty don't overwrite good data
+	if ci.SkipEmptyOverwrite && src.Size() == 0 && dst.Size() > 0 {
+		fs.Logf(src, "Not overwriting non-empty destination with empty source as --skip-empty-overwrite is set")
+		logger(ctx, Differ, src, dst, nil)
+		return false, SkipEmptyOverwrite
 	}
 	// If we should upload unconditionally
 	if ci.IgnoreTimes {
 		fs.Debugf(src, "Transferring unconditionally as --ignore-times is in use")
 		logger(ctx, Differ, src, dst, nil)
-		return true
+		return true, ""
 	}
 	// If UpdateOlder is in effect, skip if dst is newer than src
 	if ci.UpdateOlder {
@@ -1763,14 +2023,14 @@ func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
 		case dt >= modifyWindow:
 			fs.Debugf(src, "Destination is newer than source, skipping")
 			logger(ctx, Match, src, dst, nil)
-			return false
+			return false, SkipDstNewer
 		case dt <= -modifyWindow:
 			// force --checksum on for the check and do update modtimes by default
 			opt := defaultEqualOpt(ctx)
 			opt.forceModTimeMatch = true
 			if equal(ctx, src, dst, opt) {
 				fs.Debugf(src, "Unchanged skipping")
-				return false
+				return false, SkipUnchanged
 			}
 		default:
 			// Do a size only compare unless --checksum is set
@@ -1778,7 +2038,7 @@ func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
 			opt.sizeOnly = !ci.CheckSum
 			if equal(ctx, src, dst, opt) {
 				fs.Debugf(src, "Destination mod time is within %v of source and files identical, skipping", modifyWindow)
-				return false
+				return false, SkipUnchanged
 			}
 			fs.Debugf(src, "Destination mod time is within %v of source but files differ, transferring", modifyWindow)
 		}
@@ -1786,14 +2046,17 @@ func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
 		// Check to see if changed or not
 		equalFn, ok := ctx.Value(equalFnKey).(EqualFn)
 		if ok {
-			return !equalFn(ctx, src, dst)
+			if equalFn(ctx, src, dst) {
+				return false, SkipUnchanged
+			}
+			return true, ""
 		}
 		if Equal(ctx, src, dst) && !SameObject(src, dst) {
 			fs.Debugf(src, "Unchanged skipping")
-			return false
+			return false, SkipUnchanged
 		}
 	}
-	return true
+	return true, ""
 }
 
 // RcatSize reads data from the Reader until EOF and uploads it to a file on remote.
@@ -1876,6 +2139,10 @@ func copyURLFn(ctx context.Context, dstFileName string, url string, autoFilename
 }
 
 // CopyURL copies the data from the url to (fdst, dstFileName)
+//
+// The size is taken from the Content-Length, falling back to Rcat if
+// it isn't known, and the modification time from the Last-Modified
+// header if present.
 func CopyURL(ctx context.Context, fdst fs.Fs, dstFileName string, url string, autoFilename, dstFileNameFromHeader bool, noClobber bool) (dst fs.Object, err error) {
 	err = copyURLFn(ctx, dstFileName, url, autoFilename, dstFileNameFromHeader, func(ctx context.Context, dstFileName string, in io.ReadCloser, size int64, modTime time.Time) (err error) {
 		if noClobber {
@@ -2094,6 +2361,11 @@ func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName str
 		if ci.IgnoreExisting {
 			fs.Debugf(srcObj, "Not removing source file as destination file exists and --ignore-existing is set")
 			logger(ctx, Match, srcObj, dstObj, nil)
+		} else if ci.NoOverwrite {
+			fs.Debugf(srcObj, "Not removing source file as destination file exists and --no-overwrite is set")
+			logger(ctx, Match, srcObj, dstObj, nil)
+		} else if ci.SkipEmptyOverwrite && srcObj.Size() == 0 && dstObj.Size() > 0 {
+			fs.Debugf(srcObj, "Not removing source file as it is empty and --skip-empty-overwrite is set")
 		} else if !SameObject(srcObj, dstObj) {
 			err = DeleteFile(ctx, srcObj)
 			logger(ctx, Differ, srcObj, dstObj, nil)
@@ -2109,6 +2381,72 @@ func MoveFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, s
 	return moveOrCopyFile(ctx, fdst, fsrc, dstFileName, srcFileName, false)
 }
 
+// MoveVerified moves srcFileName on fsrc to dstFileName on fdst,
+// checking the destination matches the source before deleting it.
+//
+// The file is always copied (server-side if possible) rather than
+// moved. The copy is read back from fdst and compared with the source
+// by size and hash, or by downloading both if there is no common
+// hash. If they don't match the source is kept and an error is
+// returned.
+func MoveVerified(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
+	srcObj, err := fsrc.NewObject(ctx, srcFileName)
+	if err != nil {
+		return err
+	}
+	dstObj, err := fdst.NewObject(ctx, dstFileName)
+	if errors.Is(err, fs.ErrorObjectNotFound) {
+		dstObj = nil
+	} else if err != nil {
+		return err
+	}
+	if dstObj != nil && SameObject(srcObj, dstObj) {
+		return fmt.Errorf("can't move %q onto itself", srcFileName)
+	}
+	if SkipDestructive(ctx, srcObj, "move") {
+		return nil
+	}
+	_, err = Copy(ctx, fdst, dstObj, dstFileName, srcObj)
+	if err != nil {
+		fs.Errorf(srcObj, "Not deleting source as copy failed: %v", err)
+		return err
+	}
+	err = verifyMove(ctx, fdst, dstFileName, srcObj)
+	if err != nil {
+		err = fs.CountError(ctx, err)
+		fs.Errorf(srcObj, "Not deleting source as %v", err)
+		return err
+	}
+	return DeleteFile(ctx, srcObj)
+}
+
+// verifyMove reads dstFileName back from fdst and checks it matches
+// src
+func verifyMove(ctx context.Context, fdst fs.Fs, dstFileName string, src fs.Object) error {
+	dst, err := fdst.NewObject(ctx, dstFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read back copy for verification: %w", err)
+	}
+	if src.Size() >= 0 && dst.Size() >= 0 && src.Size() != dst.Size() {
+		return fmt.Errorf("verification failed: sizes differ %d vs %d", src.Size(), dst.Size())
+	}
+	equal, ht, err := CheckHashes(ctx, src, dst)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if ht == hash.None {
+		differ, err := checkIdenticalDownload(ctx, dst, src)
+		if err != nil {
+			return fmt.Errorf("verification failed: %w", err)
+		}
+		equal = !differ
+	}
+	if !equal {
+		return errors.New("verification failed: contents differ")
+	}
+	return nil
+}
+
 // SetTier changes tier of object in remote
 func SetTier(ctx context.Context, fsrc fs.Fs, tier string) error {
 	return ListFn(ctx, fsrc, func(o fs.Object) {
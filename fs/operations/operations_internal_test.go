@@ -41,3 +41,68 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+// sizedObject is an in memory object which reports a different size
+// to its content, like a compressing backend might
+type sizedObject struct {
+	*object.MemoryObject
+	size int64
+}
+
+func (o *sizedObject) Size() int64 {
+	return o.size
+}
+
+// Check that objects with the same content and hash but different
+// reported sizes are only skipped with --ignore-size
+func TestNeedTransferIgnoreSize(t *testing.T) {
+	when := time.Now()
+	content := []byte("same content")
+	src := object.NewMemoryObject("src", when, content)
+	dst := &sizedObject{MemoryObject: object.NewMemoryObject("dst", when, content), size: 5}
+	for _, test := range []struct {
+		ignoreSize bool
+		checkSum   bool
+		want       bool
+	}{
+		{false, false, true},
+		{false, true, true},
+		{true, false, false},
+		{true, true, false},
+	} {
+		ctx, ci := fs.AddConfig(context.Background())
+		ci.IgnoreSize = test.ignoreSize
+		ci.CheckSum = test.checkSum
+		what := fmt.Sprintf("ignoreSize=%v, checkSum=%v", test.ignoreSize, test.checkSum)
+		assert.Equal(t, !test.want, Equal(ctx, src, dst), what)
+		assert.Equal(t, test.want, NeedTransfer(ctx, dst, src), what)
+	}
+}
+
+// Check --skip-empty-overwrite only stops empty files replacing
+// non-empty ones
+func TestNeedTransferSkipEmptyOverwrite(t *testing.T) {
+	t1 := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	for _, test := range []struct {
+		src     string
+		dst     string
+		missing bool
+		want    bool
+	}{
+		{src: "", dst: "good data", want: false},
+		{src: "", dst: "", want: true},
+		{src: "", missing: true, want: true},
+		{src: "new data", dst: "good data", want: true},
+	} {
+		ctx, ci := fs.AddConfig(context.Background())
+		ci.SkipEmptyOverwrite = true
+		src := object.NewMemoryObject("file", t2, []byte(test.src))
+		var dst fs.Object
+		if !test.missing {
+			dst = object.NewMemoryObject("file", t1, []byte(test.dst))
+		}
+		what := fmt.Sprintf("src=%q, dst=%q, missing=%v", test.src, test.dst, test.missing)
+		assert.Equal(t, test.want, NeedTransfer(ctx, dst, src), what)
+	}
+}
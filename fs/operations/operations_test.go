@@ -22,14 +22,17 @@ package operations_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -42,6 +45,8 @@ import (
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/fstest/fstests"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/rclone/rclone/lib/pacer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -73,6 +78,65 @@ func TestMkdir(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// mkdirFs is a mock Fs which records Mkdir calls
+//
+// Unless bucketBased is set it models directories and Mkdir fails
+// if the parent directory doesn't exist.
+type mkdirFs struct {
+	*mockfs.Fs
+	bucketBased bool
+	dirs        map[string]bool
+	calls       []string
+}
+
+func newMkdirFs(t *testing.T, bucketBased bool) *mkdirFs {
+	ctx := context.Background()
+	mf, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+	f := &mkdirFs{
+		Fs:          mf.(*mockfs.Fs),
+		bucketBased: bucketBased,
+		dirs:        map[string]bool{"": true},
+	}
+	f.Fs.Features().BucketBased = bucketBased
+	return f
+}
+
+func (f *mkdirFs) Mkdir(ctx context.Context, dir string) error {
+	f.calls = append(f.calls, dir)
+	if f.bucketBased {
+		return nil
+	}
+	parent := path.Dir(dir)
+	if parent == "." {
+		parent = ""
+	}
+	if !f.dirs[parent] {
+		return fs.ErrorDirNotFound
+	}
+	f.dirs[dir] = true
+	return nil
+}
+
+func TestMkdirAll(t *testing.T) {
+	ctx := context.Background()
+
+	f := newMkdirFs(t, false)
+	require.NoError(t, operations.MkdirAll(ctx, f, "a/b/c"))
+	assert.Equal(t, []string{"a/b/c", "a", "a/b", "a/b/c"}, f.calls)
+	assert.True(t, f.dirs["a/b/c"])
+
+	// Existing parents mean only one call is needed
+	f.calls = nil
+	require.NoError(t, operations.MkdirAll(ctx, f, "a/b/d"))
+	assert.Equal(t, []string{"a/b/d"}, f.calls)
+
+	// Bucket-based backends only make the bucket
+	f = newMkdirFs(t, true)
+	require.NoError(t, operations.MkdirAll(ctx, f, "bucket/a/b"))
+	assert.Equal(t, []string{"bucket/a/b"}, f.calls)
+}
+
 func TestLsd(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
@@ -310,6 +374,68 @@ func TestHashSumsWithErrors(t *testing.T) {
 	// TODO mock an unreadable file
 }
 
+// noHashObject is an object which doesn't have any hashes available
+type noHashObject struct {
+	fs.Object
+}
+
+// Hash returns an empty hash as the hash isn't available
+func (o noHashObject) Hash(ctx context.Context, ht hash.Type) (string, error) {
+	return "", nil
+}
+
+func TestHashListerUnavailable(t *testing.T) {
+	ctx := context.Background()
+	f, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+	mf := f.(*mockfs.Fs)
+	mf.AddObject(mockobject.New("with hash").WithContent([]byte("hello"), mockobject.SeekModeNone))
+	mf.AddObject(noHashObject{mockobject.New("without hash").WithContent([]byte("world"), mockobject.SeekModeNone)})
+	const want = "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"
+
+	// The object without a hash should be skipped but not be an error
+	accounting.GlobalStats().ResetCounters()
+	var buf bytes.Buffer
+	err = operations.HashLister(ctx, hash.SHA1, false, false, f, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, want+"  with hash\n", buf.String())
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+
+	// Same for the JSON output
+	buf.Reset()
+	err = operations.HashListerJSON(ctx, hash.SHA1, false, false, f, &buf)
+	require.NoError(t, err)
+	var items []operations.HashSumItem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &items))
+	assert.Equal(t, []operations.HashSumItem{{Path: "with hash", Hash: want}}, items)
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+}
+
+func TestHashListerJSON(t *testing.T) {
+	ctx := context.Background()
+	f, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+
+	// Empty remote should give an empty array
+	var buf bytes.Buffer
+	err = operations.HashListerJSON(ctx, hash.MD5, false, false, f, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "[\n]\n", buf.String())
+
+	mf := f.(*mockfs.Fs)
+	mf.AddObject(mockobject.New("a").WithContent([]byte("hello"), mockobject.SeekModeNone))
+	mf.AddObject(mockobject.New("b").WithContent([]byte("world"), mockobject.SeekModeNone))
+	buf.Reset()
+	err = operations.HashListerJSON(ctx, hash.MD5, false, false, f, &buf)
+	require.NoError(t, err)
+	var items []operations.HashSumItem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &items))
+	assert.ElementsMatch(t, []operations.HashSumItem{
+		{Path: "a", Hash: "5d41402abc4b2a76b9719d911017c592"},
+		{Path: "b", Hash: "7d793037a0760186574b0282f2f435e7"},
+	}, items)
+}
+
 func TestHashStream(t *testing.T) {
 	reader := strings.NewReader("")
 	in := io.NopCloser(reader)
@@ -406,6 +532,154 @@ func TestCount(t *testing.T) {
 	assert.Equal(t, int64(0), sizeless)
 }
 
+// Check Count doesn't count objects excluded by the filters
+func TestCountWithFilter(t *testing.T) {
+	ctx := context.Background()
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	require.NoError(t, fi.AddRule("- *.bak"))
+	fi.Opt.MinSize = 2
+	ctx = filter.ReplaceConfig(ctx, fi)
+	r := fstest.NewRun(t)
+	file1 := r.WriteObject(ctx, "keep", "1234567890", t1)                // 10 bytes
+	file2 := r.WriteObject(ctx, "sub dir/keep too", "hello", t1)         // 5 bytes
+	file3 := r.WriteObject(ctx, "excluded.bak", "1234567890", t1)        // excluded by name
+	file4 := r.WriteObject(ctx, "sub dir/tiny", "x", t1)                 // excluded by size
+	file5 := r.WriteObject(ctx, "sub dir/excluded too.bak", "hello", t1) // excluded by name
+	r.CheckRemoteItems(t, file1, file2, file3, file4, file5)
+
+	objects, size, sizeless, err := operations.Count(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), objects)
+	assert.Equal(t, int64(15), size)
+	assert.Equal(t, int64(0), sizeless)
+}
+
+// coarseFs wraps an Fs to report a modification time precision of a
+// second, optionally making the objects refuse SetModTime
+type coarseFs struct {
+	fs.Fs
+	cantSetModTime bool
+}
+
+func (f *coarseFs) Precision() time.Duration {
+	return time.Second
+}
+
+func (f *coarseFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	if f.cantSetModTime {
+		for i, entry := range entries {
+			if o, ok := entry.(fs.Object); ok {
+				entries[i] = cantSetModTimeObject{o}
+			}
+		}
+	}
+	return entries, err
+}
+
+type cantSetModTimeObject struct {
+	fs.Object
+}
+
+func (o cantSetModTimeObject) SetModTime(ctx context.Context, t time.Time) error {
+	return fs.ErrorCantSetModTime
+}
+
+func TestNormalizeModTimes(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	precise := t1.Add(123456789 * time.Nanosecond)
+	file1 := r.WriteObject(ctx, "precise", "precise", precise)
+	file2 := r.WriteObject(ctx, "sub dir/whole", "whole", t1.Truncate(time.Second))
+	r.CheckRemoteItems(t, file1, file2)
+	if r.Fremote.Precision() > time.Millisecond {
+		t.Skip("remote isn't precise enough for this test")
+	}
+
+	// Backends which can't set the time report the objects
+	err := operations.NormalizeModTimes(ctx, &coarseFs{Fs: r.Fremote, cantSetModTime: true})
+	require.ErrorIs(t, err, fs.ErrorCantSetModTime)
+	assert.ErrorContains(t, err, "1 objects")
+	r.CheckRemoteItems(t, file1, file2)
+
+	err = operations.NormalizeModTimes(ctx, &coarseFs{Fs: r.Fremote})
+	require.NoError(t, err)
+	file1.ModTime = precise.Truncate(time.Second)
+	r.CheckRemoteItems(t, file1, file2)
+}
+
+// fastHashFs wraps an Fs to report that reading hashes is cheap
+type fastHashFs struct {
+	fs.Fs
+}
+
+func (f *fastHashFs) Features() *fs.Features {
+	features := *f.Fs.Features()
+	features.SlowHash = false
+	return &features
+}
+
+// fastHashObject is an object whose Fs is a fastHashFs
+type fastHashObject struct {
+	fs.Object
+	f *fastHashFs
+}
+
+func (o fastHashObject) Fs() fs.Info {
+	return o.f
+}
+
+func TestNeedTransferQuickCompare(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	for _, test := range []struct {
+		name     string
+		src      string
+		dst      string
+		checkSum bool
+		quick    bool
+		srcFast  bool
+		dstFast  bool
+		want     bool
+	}{
+		// Without --quick-compare the full hash spots the change
+		{name: "middle", src: "headXXXXtail", dst: "headYYYYtail", want: true},
+		// Differences in the middle are missed - this is the tradeoff
+		{name: "middle quick", src: "headXXXXtail", dst: "headYYYYtail", quick: true, want: false},
+		{name: "middle quick checksum", src: "headXXXXtail", dst: "headYYYYtail", quick: true, checkSum: true, want: false},
+		{name: "head quick", src: "HEADXXXXtail", dst: "headXXXXtail", quick: true, want: true},
+		{name: "tail quick checksum", src: "headXXXXTAIL", dst: "headXXXXtail", quick: true, checkSum: true, want: true},
+		// The full hash is used if both sides can read it cheaply
+		{name: "middle quick fast hash", src: "headXXXXtail", dst: "headYYYYtail", quick: true, srcFast: true, dstFast: true, want: true},
+		{name: "middle quick checksum fast hash", src: "headXXXXtail", dst: "headYYYYtail", quick: true, checkSum: true, srcFast: true, dstFast: true, want: true},
+		// but not if either side has a slow hash
+		{name: "middle quick slow src hash", src: "headXXXXtail", dst: "headYYYYtail", quick: true, dstFast: true, want: false},
+		{name: "middle quick slow dst hash", src: "headXXXXtail", dst: "headYYYYtail", quick: true, srcFast: true, want: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, ci := fs.AddConfig(ctx)
+			ci.CheckSum = test.checkSum
+			ci.QuickCompare = test.quick
+			ci.QuickCompareCutoff = 8
+			ci.QuickCompareBytes = 4
+			r.WriteFile(test.name, test.src, t2)
+			r.WriteObject(ctx, test.name, test.dst, t1)
+			src, err := r.Flocal.NewObject(ctx, test.name)
+			require.NoError(t, err)
+			dst, err := r.Fremote.NewObject(ctx, test.name)
+			require.NoError(t, err)
+			if test.srcFast {
+				src = fastHashObject{Object: src, f: &fastHashFs{Fs: r.Flocal}}
+			}
+			if test.dstFast {
+				dst = fastHashObject{Object: dst, f: &fastHashFs{Fs: r.Fremote}}
+			}
+			assert.Equal(t, test.want, operations.NeedTransfer(ctx, dst, src))
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	ctx := context.Background()
 	fi, err := filter.NewFilter(nil)
@@ -423,6 +697,131 @@ func TestDelete(t *testing.T) {
 	r.CheckRemoteItems(t, file3)
 }
 
+// removeObject is an object with a custom Remove
+type removeObject struct {
+	fs.Object
+	remove func() error
+}
+
+func (o removeObject) Remove(ctx context.Context) error {
+	return o.remove()
+}
+
+func TestDeleteFilesDeleters(t *testing.T) {
+	for _, test := range []struct {
+		deleters int
+		want     int
+	}{
+		{deleters: 0, want: 2},
+		{deleters: 1, want: 1},
+		{deleters: 6, want: 6},
+	} {
+		t.Run(fmt.Sprintf("Deleters=%d", test.deleters), func(t *testing.T) {
+			ctx := context.Background()
+			ctx, ci := fs.AddConfig(ctx)
+			ci.Checkers = 2
+			ci.Transfers = 3
+			ci.Deleters = test.deleters
+
+			var (
+				mu            sync.Mutex
+				active, peak  int
+				reachedOnce   sync.Once
+				reachedWanted = make(chan struct{})
+			)
+			remove := func() error {
+				mu.Lock()
+				active++
+				if active > peak {
+					peak = active
+				}
+				if peak >= test.want {
+					reachedOnce.Do(func() { close(reachedWanted) })
+				}
+				mu.Unlock()
+				// Hold the delete until the wanted concurrency is reached
+				select {
+				case <-reachedWanted:
+				case <-time.After(time.Second):
+				}
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil
+			}
+
+			toBeDeleted := make(fs.ObjectsChan, 20)
+			for i := 0; i < 20; i++ {
+				toBeDeleted <- removeObject{Object: mockobject.New(fmt.Sprintf("file%d", i)), remove: remove}
+			}
+			close(toBeDeleted)
+			require.NoError(t, operations.DeleteFiles(ctx, toBeDeleted))
+			assert.Equal(t, test.want, peak)
+		})
+	}
+}
+
+// removableObject is a mock object which records being removed
+type removableObject struct {
+	*mockobject.ContentMockObject
+	removed *bool
+}
+
+func (o removableObject) Remove(ctx context.Context) error {
+	*o.removed = true
+	return nil
+}
+
+func TestMoveBackupDirServerSide(t *testing.T) {
+	for _, feature := range []string{"Move", "Copy"} {
+		t.Run(feature, func(t *testing.T) {
+			ctx := context.Background()
+			f, err := mockfs.NewFs(ctx, "mock", "/", nil)
+			require.NoError(t, err)
+			// The backup dir is on the same remote
+			backupDir, err := mockfs.NewFs(ctx, "mock", "/backup", nil)
+			require.NoError(t, err)
+			var (
+				moved, copied, removed bool
+				content                = []byte("overwritten file")
+			)
+			newObject := func(remote string) fs.Object {
+				o := mockobject.New(remote).WithContent(content, mockobject.SeekModeNone)
+				o.SetFs(backupDir)
+				return o
+			}
+			// Put is not implemented by mockfs so the backup will
+			// fail if rclone tries to stream the data
+			switch feature {
+			case "Move":
+				backupDir.Features().Move = func(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+					moved = true
+					return newObject(remote), nil
+				}
+			case "Copy":
+				backupDir.Features().Copy = func(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+					copied = true
+					return newObject(remote), nil
+				}
+			}
+			o := mockobject.New("file.txt").WithContent(content, mockobject.SeekModeNone)
+			o.SetFs(f)
+			dst := removableObject{ContentMockObject: o, removed: &removed}
+
+			require.True(t, operations.CanServerSideMove(backupDir))
+			require.NoError(t, operations.MoveBackupDir(ctx, backupDir, dst))
+			switch feature {
+			case "Move":
+				assert.True(t, moved, "server-side move")
+				assert.False(t, removed, "source removed")
+			case "Copy":
+				assert.True(t, copied, "server-side copy")
+				assert.True(t, removed, "source removed")
+			}
+		})
+	}
+}
+
 func isChunker(f fs.Fs) bool {
 	return strings.HasPrefix(f.Name(), "TestChunker")
 }
@@ -568,6 +967,78 @@ func TestCat(t *testing.T) {
 	}
 }
 
+// rangeCountObject records the options it was opened with and the
+// number of bytes read from it
+type rangeCountObject struct {
+	*mockobject.ContentMockObject
+	mu      sync.Mutex
+	options []fs.OpenOption
+	read    int64
+}
+
+func (o *rangeCountObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	o.mu.Lock()
+	o.options = append(o.options, options...)
+	o.mu.Unlock()
+	in, err := o.ContentMockObject.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeCountReader{ReadCloser: in, o: o}, nil
+}
+
+type rangeCountReader struct {
+	io.ReadCloser
+	o *rangeCountObject
+}
+
+func (r *rangeCountReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.o.mu.Lock()
+	r.o.read += int64(n)
+	r.o.mu.Unlock()
+	return n, err
+}
+
+// Check Cat only reads the requested span from the source
+func TestCatRange(t *testing.T) {
+	ctx := context.Background()
+	const content = "0123456789abcdefghij"
+	for _, test := range []struct {
+		offset  int64
+		count   int64
+		want    string
+		wantOpt *fs.RangeOption
+	}{
+		{0, -1, content, nil},
+		{0, 4, "0123", &fs.RangeOption{Start: 0, End: 3}},
+		{5, 3, "567", &fs.RangeOption{Start: 5, End: 7}},
+		{-4, -1, "ghij", &fs.RangeOption{Start: 16, End: -1}},
+		{-4, 2, "gh", &fs.RangeOption{Start: 16, End: 17}},
+	} {
+		t.Run(fmt.Sprintf("%d,%d", test.offset, test.count), func(t *testing.T) {
+			f, err := mockfs.NewFs(ctx, "mock", "", nil)
+			require.NoError(t, err)
+			o := &rangeCountObject{
+				ContentMockObject: mockobject.New("file").WithContent([]byte(content), mockobject.SeekModeNone),
+			}
+			f.(*mockfs.Fs).AddObject(o)
+
+			var buf bytes.Buffer
+			require.NoError(t, operations.Cat(ctx, f, &buf, test.offset, test.count, nil))
+			assert.Equal(t, test.want, buf.String())
+			assert.Equal(t, int64(len(test.want)), o.read)
+			var gotOpt *fs.RangeOption
+			for _, option := range o.options {
+				if rangeOpt, ok := option.(*fs.RangeOption); ok {
+					gotOpt = rangeOpt
+				}
+			}
+			assert.Equal(t, test.wantOpt, gotOpt)
+		})
+	}
+}
+
 func TestPurge(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRunIndividual(t) // make new container (azureblob has delayed mkdir after rmdir)
@@ -640,6 +1111,36 @@ func TestPurge(t *testing.T) {
 
 }
 
+func TestPurgeFiltered(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRunIndividual(t)
+	r.Mkdir(ctx, r.Fremote)
+	file1 := r.WriteObject(ctx, "A1/one.jpg", "aaa", t1)
+	file2 := r.WriteObject(ctx, "A1/two.txt", "bbb", t2)
+	file3 := r.WriteObject(ctx, "A2/three.jpg", "ccc", t1)
+	file4 := r.WriteObject(ctx, "four.jpg", "ddd", t2)
+	r.CheckRemoteItems(t, file1, file2, file3, file4)
+
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	require.NoError(t, fi.AddRule("+ *.jpg"))
+	require.NoError(t, fi.AddRule("- *"))
+	filterCtx := filter.ReplaceConfig(ctx, fi)
+
+	// Only the matching files are removed in the directory
+	require.NoError(t, operations.Purge(filterCtx, r.Fremote, "A1"))
+	r.CheckRemoteItems(t, file2, file3, file4)
+
+	// Only the matching files and directories left empty are
+	// removed from the root which is left
+	require.NoError(t, operations.Purge(filterCtx, r.Fremote, ""))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file2}, []string{"A1"}, fs.GetModifyWindow(ctx, r.Fremote))
+
+	// Without filters everything is removed
+	require.NoError(t, operations.Purge(ctx, r.Fremote, ""))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{}, []string{}, fs.GetModifyWindow(ctx, r.Fremote))
+}
+
 func TestRmdirsNoLeaveRoot(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
@@ -894,6 +1395,41 @@ func TestCopyURL(t *testing.T) {
 	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, file2, fstest.NewItem(urlFileName, contents, t1), fstest.NewItem(headerFilename, contents, t1)}, nil, fs.ModTimeNotSupported)
 }
 
+// Check CopyURL sets the modtime from Last-Modified and copes with an
+// unknown Content-Length
+func TestCopyURLLastModified(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	r.Mkdir(ctx, r.Fremote)
+
+	contents := "file contents\n"
+	lastModified := fstest.Time("2001-02-03T04:05:06Z")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		_, err := w.Write([]byte(contents))
+		assert.NoError(t, err)
+		// Flushing before the end stops the server sending a Content-Length
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush()
+			_, err = w.Write([]byte(contents))
+			assert.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	o, err := operations.CopyURL(ctx, r.Fremote, "sized", ts.URL+"/sized", false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(contents)), o.Size())
+	o, err = operations.CopyURL(ctx, r.Fremote, "chunked", ts.URL+"/chunked", false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*len(contents)), o.Size())
+
+	r.CheckRemoteItems(t,
+		fstest.NewItem("sized", contents, lastModified),
+		fstest.NewItem("chunked", contents+contents, lastModified),
+	)
+}
+
 func TestCopyURLToWriter(t *testing.T) {
 	ctx := context.Background()
 	contents := "file contents\n"
@@ -955,6 +1491,44 @@ func TestMoveFile(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+func TestMoveVerified(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer accounting.Stats(ctx).ResetCounters()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+
+	file2 := file1
+	file2.Path = "sub/file2"
+
+	err := operations.MoveVerified(ctx, r.Fremote, r.Flocal, file2.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckLocalItems(t)
+	r.CheckRemoteItems(t, file2)
+
+	// Can't move a file onto itself
+	err = operations.MoveVerified(ctx, r.Fremote, r.Fremote, file2.Path, file2.Path)
+	assert.ErrorContains(t, err, "onto itself")
+	r.CheckRemoteItems(t, file2)
+
+	// If the copy doesn't verify the source is kept
+	ht, _ := operations.CommonHash(ctx, r.Fremote, r.Flocal)
+	if ht == hash.None {
+		t.Skip("skipping test as no common hash")
+	}
+	r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+	file3 := file1
+	file3.Path = "sub/file3"
+	fdst := &wrongHashFs{Fs: r.Fremote}
+	err = operations.MoveVerified(ctx, fdst, r.Flocal, file3.Path, file1.Path)
+	assert.ErrorContains(t, err, "verification failed")
+	assert.Equal(t, 1, fdst.lookups)
+	r.CheckLocalItems(t, file1)
+	r.CheckRemoteItems(t, file2, file3)
+}
+
 func TestMoveFileWithIgnoreExisting(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -980,6 +1554,48 @@ func TestMoveFileWithIgnoreExisting(t *testing.T) {
 	r.CheckRemoteItems(t, file1)
 }
 
+func TestMoveFileWithNoOverwrite(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	file2 := r.WriteBoth(ctx, "file2", "file2 contents", t1)
+	r.CheckLocalItems(t, file1, file2)
+	r.CheckRemoteItems(t, file2)
+
+	ci.NoOverwrite = true
+
+	// A missing file is moved
+	err := operations.MoveFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckLocalItems(t, file2)
+	r.CheckRemoteItems(t, file1, file2)
+
+	// An identical file is skipped and the source isn't deleted
+	err = operations.MoveFile(ctx, r.Fremote, r.Flocal, file2.Path, file2.Path)
+	require.NoError(t, err)
+	r.CheckLocalItems(t, file2)
+	r.CheckRemoteItems(t, file1, file2)
+
+	// A modified file is skipped and the source isn't deleted
+	file1b := r.WriteFile("file1", "file1 modified", t2)
+	err = operations.MoveFile(ctx, r.Fremote, r.Flocal, file1b.Path, file1b.Path)
+	require.NoError(t, err)
+	r.CheckLocalItems(t, file1b, file2)
+	r.CheckRemoteItems(t, file1, file2)
+
+	// It works without --ignore-existing being set, as when it is
+	// passed in the rc _config, and gives its own skip reason
+	assert.False(t, ci.IgnoreExisting)
+	src, err := r.Flocal.NewObject(ctx, file1b.Path)
+	require.NoError(t, err)
+	dst, err := r.Fremote.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	transfer, reason := operations.NeedTransferReason(ctx, dst, src)
+	assert.False(t, transfer)
+	assert.Equal(t, operations.SkipNoOverwrite, reason)
+}
+
 func TestCaseInsensitiveMoveFile(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
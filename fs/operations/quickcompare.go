@@ -0,0 +1,104 @@
+package operations
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// quickCompareObjects returns the src and dst as objects if
+// --quick-compare should be used to compare them.
+//
+// It isn't used if the source and destination both have a cheap hash
+// in common as that is quicker and more accurate. If either side has
+// to read the whole file to hash it then the quick compare is used.
+func quickCompareObjects(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (srcObj fs.Object, ok bool) {
+	ci := fs.GetConfig(ctx)
+	if !ci.QuickCompare || ci.QuickCompareBytes <= 0 {
+		return nil, false
+	}
+	if src.Size() < int64(ci.QuickCompareCutoff) || src.Size() != dst.Size() {
+		return nil, false
+	}
+	// Use the full hash if both sides can read a common one cheaply
+	if srcFs := src.Fs(); srcFs != nil {
+		dstFs := dst.Fs()
+		slowHash := srcFs.Features().SlowHash || dstFs.Features().SlowHash
+		if srcFs.Hashes().Overlap(dstFs.Hashes()).GetOne() != hash.None && !slowHash {
+			return nil, false
+		}
+	}
+	srcObj, ok = src.(fs.Object)
+	return srcObj, ok
+}
+
+// quickEqual compares the hashes of the first and last
+// --quick-compare-bytes of src and dst.
+//
+// This is a heuristic - files which only differ in the middle will
+// compare as equal. Read errors are logged and make it return false.
+func quickEqual(ctx context.Context, src, dst fs.Object) bool {
+	n := int64(fs.GetConfig(ctx).QuickCompareBytes)
+	srcSum, err := headTailHash(ctx, src, n)
+	if err != nil {
+		err = fs.CountError(ctx, err)
+		fs.Errorf(src, "Failed to quick compare src: %v", err)
+		return false
+	}
+	dstSum, err := headTailHash(ctx, dst, n)
+	if err != nil {
+		err = fs.CountError(ctx, err)
+		fs.Errorf(dst, "Failed to quick compare dst: %v", err)
+		return false
+	}
+	if srcSum != dstSum {
+		fs.Debugf(src, "Quick compare hashes differ (src %s vs dst %s)", srcSum, dstSum)
+		return false
+	}
+	fs.Debugf(src, "Quick compare hash = %s OK", srcSum)
+	return true
+}
+
+// headTailHash returns a hex SHA1 of the first n and the last n bytes
+// of o read with ranged reads.
+//
+// If o is no bigger than 2*n then all of it is hashed.
+func headTailHash(ctx context.Context, o fs.Object, n int64) (string, error) {
+	size := o.Size()
+	if size < 0 {
+		return "", fmt.Errorf("can't hash the head and tail of %q of unknown size", o.Remote())
+	}
+	h := sha1.New()
+	hashRange := func(start, end int64) error {
+		in, err := Open(ctx, o, &fs.RangeOption{Start: start, End: end - 1})
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(h, in, end-start)
+		closeErr := in.Close()
+		if err != nil {
+			return err
+		}
+		return closeErr
+	}
+	if size <= 2*n {
+		if size > 0 {
+			if err := hashRange(0, size); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		if err := hashRange(0, n); err != nil {
+			return "", err
+		}
+		if err := hashRange(size-n, size); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
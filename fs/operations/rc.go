@@ -158,6 +158,43 @@ func rcAbout(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/reconnect",
+		AuthRequired: true,
+		Fn:           rcReconnect,
+		Title:        "Force the remote to authenticate again",
+		Help: `This takes the following parameters:
+
+- fs - a remote name string e.g. "b2:"
+
+This discards the credentials the backend is currently using and
+authenticates with the remote again. This is useful for long running
+instances, e.g. a mount, when credentials have been rotated.
+
+Not all backends support this - use operations/fsinfo to check for
+the Reconnect feature.
+`,
+	})
+}
+
+// Reconnect the remote
+func rcReconnect(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, err := rc.GetFs(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	doReconnect := f.Features().Reconnect
+	if doReconnect == nil {
+		return nil, fmt.Errorf("%v doesn't support reconnect", f)
+	}
+	err = doReconnect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reconnect call failed: %w", err)
+	}
+	return nil, nil
+}
+
 func init() {
 	for _, copy := range []bool{false, true} {
 		copy := copy
@@ -470,6 +507,7 @@ This returns info about the remote passed in;
                 "PutUnchecked": false,
                 "ReadMetadata": true,
                 "ReadMimeType": false,
+                "Reconnect": false,
                 "ServerSideAcrossConfigs": false,
                 "SetTier": false,
                 "SetWrapper": false,
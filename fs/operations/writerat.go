@@ -0,0 +1,100 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// ErrorWriteAtNotSequential is returned by the WriterAt from
+// OpenWriterAt if the backend doesn't support random access writes
+// and the writes aren't sequential.
+var ErrorWriteAtNotSequential = errors.New("backend doesn't support random access writes so writes must be sequential")
+
+// OpenWriterAt opens remote on f for writing at offsets, using the
+// OpenWriterAt feature of f if it has one.
+//
+// If it doesn't then the data is streamed to the backend instead, so
+// each write must start where the previous one finished otherwise
+// ErrorWriteAtNotSequential is returned.
+//
+// size should be the size of the file if known or -1 if not.
+func OpenWriterAt(ctx context.Context, f fs.Fs, remote string, size int64) (fs.WriterAtCloser, error) {
+	if openWriterAt := f.Features().OpenWriterAt; openWriterAt != nil {
+		return openWriterAt(ctx, remote, size)
+	}
+	fs.Debugf(f, "%s: OpenWriterAt not supported so streaming writes sequentially", remote)
+	return newSequentialWriterAt(ctx, f, remote, size), nil
+}
+
+// sequentialWriterAt is an fs.WriterAtCloser which streams writes
+// made in order to the backend.
+type sequentialWriterAt struct {
+	mu     sync.Mutex
+	remote string
+	size   int64          // size of the file or -1 if unknown
+	offset int64          // offset the next write must be at
+	out    *io.PipeWriter // write end of the pipe to the upload
+	done   chan error     // result of the upload
+	closed bool
+}
+
+// newSequentialWriterAt starts an upload of remote to f which is fed
+// by the writes.
+func newSequentialWriterAt(ctx context.Context, f fs.Fs, remote string, size int64) *sequentialWriterAt {
+	in, out := io.Pipe()
+	w := &sequentialWriterAt{
+		remote: remote,
+		size:   size,
+		out:    out,
+		done:   make(chan error, 1),
+	}
+	go func() {
+		_, err := RcatSize(ctx, f, remote, in, size, time.Now(), nil)
+		// Make sure any blocked writes return
+		_ = in.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+// WriteAt writes p at off which must be the end of the previous write
+func (w *sequentialWriterAt) WriteAt(p []byte, off int64) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, fmt.Errorf("%s: write to closed file", w.remote)
+	}
+	if off != w.offset {
+		return 0, fmt.Errorf("%s: write at %d but expecting %d: %w", w.remote, off, w.offset, ErrorWriteAtNotSequential)
+	}
+	n, err = w.out.Write(p)
+	w.offset += int64(n)
+	return n, err
+}
+
+// Close finishes the upload returning any error from it
+func (w *sequentialWriterAt) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("%s: file already closed", w.remote)
+	}
+	w.closed = true
+	if w.size >= 0 && w.offset != w.size {
+		err := fmt.Errorf("%s: only %d of %d bytes written", w.remote, w.offset, w.size)
+		_ = w.out.CloseWithError(err)
+		<-w.done
+		return err
+	}
+	_ = w.out.Close()
+	return <-w.done
+}
+
+// Check interface
+var _ fs.WriterAtCloser = (*sequentialWriterAt)(nil)
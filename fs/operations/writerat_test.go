@@ -0,0 +1,80 @@
+package operations_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readRemote reads the contents of remote from f
+func readRemote(ctx context.Context, t *testing.T, f fs.Fs, remote string) string {
+	o, err := f.NewObject(ctx, remote)
+	require.NoError(t, err)
+	in, err := operations.Open(ctx, o)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, in.Close())
+	}()
+	data, err := io.ReadAll(in)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestOpenWriterAtFallback(t *testing.T) {
+	ctx := context.Background()
+	// Use an individual remote as we are disabling a feature on it
+	r := fstest.NewRunIndividual(t)
+	r.Fremote.Features().Disable("OpenWriterAt")
+	require.Nil(t, r.Fremote.Features().OpenWriterAt)
+
+	for _, size := range []int64{9, -1} {
+		out, err := operations.OpenWriterAt(ctx, r.Fremote, "file", size)
+		require.NoError(t, err)
+		n, err := out.WriteAt([]byte("abc"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		n, err = out.WriteAt([]byte("defghi"), 3)
+		require.NoError(t, err)
+		assert.Equal(t, 6, n)
+		require.NoError(t, out.Close())
+		assert.Equal(t, "abcdefghi", readRemote(ctx, t, r.Fremote, "file"), size)
+		assert.Error(t, out.Close(), "double close")
+	}
+
+	// Out of order writes aren't supported
+	out, err := operations.OpenWriterAt(ctx, r.Fremote, "file2", 9)
+	require.NoError(t, err)
+	_, err = out.WriteAt([]byte("def"), 3)
+	assert.True(t, errors.Is(err, operations.ErrorWriteAtNotSequential))
+	_, err = out.WriteAt([]byte("abc"), 0)
+	require.NoError(t, err)
+	assert.ErrorContains(t, out.Close(), "only 3 of 9 bytes written")
+	_, err = out.WriteAt([]byte("def"), 3)
+	assert.Error(t, err, "write after close")
+}
+
+func TestOpenWriterAtNative(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	if r.Fremote.Features().OpenWriterAt == nil {
+		t.Skip("FS has no OpenWriterAt interface")
+	}
+
+	out, err := operations.OpenWriterAt(ctx, r.Fremote, "file", 9)
+	require.NoError(t, err)
+	_, err = out.WriteAt([]byte("def"), 3)
+	require.NoError(t, err)
+	_, err = out.WriteAt([]byte("ghi"), 6)
+	require.NoError(t, err)
+	_, err = out.WriteAt([]byte("abc"), 0)
+	require.NoError(t, err)
+	require.NoError(t, out.Close())
+	assert.Equal(t, "abcdefghi", readRemote(ctx, t, r.Fremote, "file"))
+}
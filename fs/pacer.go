@@ -4,6 +4,7 @@ package fs
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/rclone/rclone/fs/fserrors"
@@ -53,6 +54,14 @@ func (d *logCalculator) Calculate(state pacer.State) time.Duration {
 	return newSleepTime
 }
 
+// Jitter calls the Jitter method of the Calculator if it has one
+func (d *logCalculator) Jitter(state pacer.State) time.Duration {
+	if j, ok := d.Calculator.(pacer.Jitterer); ok {
+		return j.Jitter(state)
+	}
+	return state.SleepTime
+}
+
 // SetCalculator sets the pacing algorithm. Don't modify the Calculator object
 // afterwards, use the ModifyCalculator method when needed.
 //
@@ -92,3 +101,33 @@ func pacerInvoker(try, retries int, f pacer.Paced) (retry bool, err error) {
 	}
 	return
 }
+
+// RetryFn is the signature of the shouldRetry functions backends
+// consult in their pacer calls. It returns whether the call should be
+// retried and the error to return.
+type RetryFn func(ctx context.Context, resp *http.Response, err error) (bool, error)
+
+// RetryWithReauth wraps policy so that 401 Unauthorized responses
+// from f are retried after fresh authorization.
+//
+// On a 401 invalidate is called, if not nil, to discard anything
+// cached with the old authorization, then reauth is called and the
+// call is retried. If reauth fails its error is returned instead so
+// that it is reported if the pacer runs out of retries. All other
+// responses are passed to policy.
+func RetryWithReauth(f Info, policy RetryFn, reauth func(ctx context.Context) error, invalidate func()) RetryFn {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			Debugf(f, "Unauthorized: %v", err)
+			if invalidate != nil {
+				invalidate()
+			}
+			authErr := reauth(ctx)
+			if authErr != nil {
+				err = authErr
+			}
+			return true, err
+		}
+		return policy(ctx, resp, err)
+	}
+}
@@ -124,7 +124,32 @@ func (x *SizeSuffix) multiplierFromSymbol(s byte) (found bool, multiplier float6
 	}
 }
 
+// siMultiplierFromSymbol returns the decimal SI multiplier for s as
+// used in suffixes like MB or GB
+func (x *SizeSuffix) siMultiplierFromSymbol(s byte) (found bool, multiplier float64) {
+	switch s {
+	case 'k', 'K':
+		return true, 1e3
+	case 'm', 'M':
+		return true, 1e6
+	case 'g', 'G':
+		return true, 1e9
+	case 't', 'T':
+		return true, 1e12
+	case 'p', 'P':
+		return true, 1e15
+	case 'e', 'E':
+		return true, 1e18
+	default:
+		return false, float64(SizeSuffixBase)
+	}
+}
+
 // Set a SizeSuffix
+//
+// The bare suffixes K, M, G etc and the IEC suffixes Ki, KiB, Mi, MiB
+// etc are binary multiples of 1024. The SI suffixes KB, MB, GB etc are
+// decimal multiples of 1000.
 func (x *SizeSuffix) Set(s string) error {
 	if len(s) == 0 {
 		return errors.New("empty string")
@@ -148,13 +173,11 @@ func (x *SizeSuffix) Set(s string) error {
 			if multiplierFound, multiplier = x.multiplierFromSymbol(suffix); !multiplierFound {
 				return fmt.Errorf("bad suffix %q", suffix)
 			}
-			// Could also support SI form MB, and treat it equivalent to MiB, but perhaps better to reserve it for CountSuffix?
-			//} else if len(s) > 1 {
-			//	suffix = s[len(s)-2]
-			//	if multiplierFound, multiplier = x.multiplierFromSymbol(suffix); multiplierFound {
-			//		suffixLen = 2
-			//	}
-			//}
+		} else if len(s) > 1 {
+			// SI form, e.g. MB
+			if multiplierFound, multiplier = x.siMultiplierFromSymbol(s[len(s)-2]); multiplierFound {
+				suffixLen = 2
+			}
 		} else {
 			multiplier = float64(SizeSuffixBase)
 		}
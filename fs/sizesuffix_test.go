@@ -96,9 +96,9 @@ func TestSizeSuffixSet(t *testing.T) {
 		{"0.1", 102, false},
 		{"1K", 1024, false},
 		{"1k", 1024, false},
-		//{"1KB", 1024, false},
-		//{"1kB", 1024, false},
-		//{"1kb", 1024, false},
+		{"1KB", 1000, false},
+		{"1kB", 1000, false},
+		{"1kb", 1000, false},
 		{"1KI", 1024, false},
 		{"1Ki", 1024, false},
 		{"1kI", 1024, false},
@@ -109,13 +109,18 @@ func TestSizeSuffixSet(t *testing.T) {
 		{"1", 1024, false},
 		{"2.5", 1024 * 2.5, false},
 		{"1M", 1024 * 1024, false},
-		//{"1MB", 1024 * 1024, false},
+		{"1MB", 1000 * 1000, false},
 		{"1Mi", 1024 * 1024, false},
 		{"1MiB", 1024 * 1024, false},
+		{"1.5GiB", 1024 * 1024 * 1024 * 1.5, false},
+		{"1.5GB", 1000 * 1000 * 1000 * 1.5, false},
 		{"1.g", 1024 * 1024 * 1024, false},
 		{"10G", 10 * 1024 * 1024 * 1024, false},
 		{"10T", 10 * 1024 * 1024 * 1024 * 1024, false},
 		{"10P", 10 * 1024 * 1024 * 1024 * 1024 * 1024, false},
+		{"10TB", 10 * 1000 * 1000 * 1000 * 1000, false},
+		{"10TiB", 10 * 1024 * 1024 * 1024 * 1024, false},
+		{"1PB", 1000 * 1000 * 1000 * 1000 * 1000, false},
 		{"off", -1, false},
 		{"OFF", -1, false},
 		{"", 0, true},
@@ -125,7 +130,8 @@ func TestSizeSuffixSet(t *testing.T) {
 		{"-1K", 0, true},
 		{"1i", 0, true},
 		{"1iB", 0, true},
-		{"1MB", 0, true},
+		{"1qB", 0, true},
+		{"1QiB", 0, true},
 	} {
 		ss := SizeSuffix(0)
 		err := ss.Set(test.in)
@@ -138,6 +144,33 @@ func TestSizeSuffixSet(t *testing.T) {
 	}
 }
 
+func TestSizeSuffixStringRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		"0",
+		"1Ki",
+		"1Mi",
+		"1.500Gi",
+		"10Ti",
+		"3Pi",
+		"off",
+	} {
+		ss := SizeSuffix(0)
+		require.NoError(t, ss.Set(in), in)
+		assert.Equal(t, in, ss.String())
+		ss2 := SizeSuffix(0)
+		require.NoError(t, ss2.Set(ss.String()), in)
+		assert.Equal(t, ss, ss2, in)
+	}
+	// SI values are shown in binary units but still round trip
+	for _, in := range []string{"1MB", "1.5GB", "7TB"} {
+		ss := SizeSuffix(0)
+		require.NoError(t, ss.Set(in), in)
+		ss2 := SizeSuffix(0)
+		require.NoError(t, ss2.Set(ss.String()), in)
+		assert.InDelta(t, float64(ss), float64(ss2), float64(ss)/1000, in)
+	}
+}
+
 func TestSizeSuffixScan(t *testing.T) {
 	var v SizeSuffix
 	n, err := fmt.Sscan(" 17M ", &v)
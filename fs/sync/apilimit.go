@@ -0,0 +1,50 @@
+package sync
+
+import (
+	"context"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// apiLimiter limits the number of backend operations the checkers,
+// transfers, renamers and directory walkers do at once to
+// --max-concurrent-api.
+//
+// A nil *apiLimiter doesn't limit anything.
+type apiLimiter struct {
+	sem *semaphore.Weighted
+}
+
+// newAPILimiter makes a limiter allowing n operations at once or
+// returns nil if n isn't positive.
+func newAPILimiter(n int) *apiLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &apiLimiter{sem: semaphore.NewWeighted(int64(n))}
+}
+
+// weighted returns the underlying semaphore or nil if not limiting.
+func (l *apiLimiter) weighted() *semaphore.Weighted {
+	if l == nil {
+		return nil
+	}
+	return l.sem
+}
+
+// do runs fn once there is a free slot. If ctx is cancelled while
+// waiting fn is run anyway so that it can return the error.
+//
+// fn mustn't wait for other users of the limiter or it may deadlock.
+func (l *apiLimiter) do(ctx context.Context, fn func()) {
+	if l == nil {
+		fn()
+		return
+	}
+	if err := l.sem.Acquire(ctx, 1); err != nil {
+		fn()
+		return
+	}
+	defer l.sem.Release(1)
+	fn()
+}
@@ -0,0 +1,207 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// checkpoint records which source directories a sync has finished
+// in the --checkpoint file so that an interrupted sync can skip
+// checking them when it is restarted.
+//
+// The file starts with a header naming the source and destination
+// followed by one line per finished directory containing a
+// fingerprint of the directory's source listing and its path. A
+// directory is only skipped if its fingerprint still matches, so
+// changes to the source invalidate it.
+type checkpoint struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	out     io.WriteCloser
+	done    map[string]string         // fingerprints of dirs finished in a previous run
+	pending map[string]*checkpointDir // dirs being processed in this run
+}
+
+// checkpointDir is a directory being processed by the sync
+type checkpointDir struct {
+	fingerprint string // fingerprint of the source listing
+	outstanding int    // number of objects not yet finished
+	listed      bool   // set when all the entries have been passed on
+	failed      bool   // set if any object failed
+}
+
+// checkpointHeader returns the first line of the --checkpoint file
+func checkpointHeader(fdst, fsrc fs.Fs) string {
+	return fmt.Sprintf("# rclone checkpoint from %q to %q", fs.ConfigString(fsrc), fs.ConfigString(fdst))
+}
+
+// newCheckpoint opens or creates the --checkpoint file at
+// checkpointPath for a sync from fsrc to fdst.
+//
+// Directories recorded in an existing file are read in. If the file
+// was written for a different source or destination it is started
+// again.
+func newCheckpoint(ctx context.Context, fdst, fsrc fs.Fs, checkpointPath string) (*checkpoint, error) {
+	c := &checkpoint{
+		ctx:     ctx,
+		done:    make(map[string]string),
+		pending: make(map[string]*checkpointDir),
+	}
+	header := checkpointHeader(fdst, fsrc)
+	valid := false
+	in, err := os.Open(checkpointPath)
+	if err == nil {
+		scanner := bufio.NewScanner(in)
+		valid = scanner.Scan() && scanner.Text() == header
+		for valid && scanner.Scan() {
+			fingerprint, dir, ok := strings.Cut(scanner.Text(), " ")
+			if ok {
+				c.done[dir] = fingerprint
+			}
+		}
+		err = scanner.Err()
+		_ = in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --checkpoint: %w", err)
+		}
+		if !valid {
+			fs.Logf(nil, "Ignoring --checkpoint %q as it was written for a different sync", checkpointPath)
+			c.done = make(map[string]string)
+		} else {
+			fs.Infof(nil, "Read %d finished directories from --checkpoint %q", len(c.done), checkpointPath)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open --checkpoint: %w", err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !valid {
+		flags |= os.O_TRUNC
+	}
+	out, err := os.OpenFile(checkpointPath, flags, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open --checkpoint: %w", err)
+	}
+	c.out = out
+	if !valid {
+		if _, err = fmt.Fprintln(out, header); err != nil {
+			_ = out.Close()
+			return nil, fmt.Errorf("failed to write --checkpoint: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// fingerprint returns a fingerprint of the objects in a source listing
+func (c *checkpoint) fingerprint(srcList fs.DirEntries) string {
+	h := sha1.New()
+	for _, entry := range srcList {
+		if o, ok := entry.(fs.Object); ok {
+			_, _ = fmt.Fprintf(h, "%s\x00%s\n", o.Remote(), fs.Fingerprint(c.ctx, o, true))
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// skipDir returns true if dir was finished by a previous run and its
+// source listing hasn't changed since. Otherwise it starts tracking
+// the objects in dir.
+func (c *checkpoint) skipDir(dir string, srcList fs.DirEntries) bool {
+	if c == nil {
+		return false
+	}
+	fingerprint := c.fingerprint(srcList)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done[dir] == fingerprint {
+		return true
+	}
+	c.pending[dir] = &checkpointDir{fingerprint: fingerprint}
+	return false
+}
+
+// objectDir returns the directory o is in
+func objectDir(o fs.Object) string {
+	dir := path.Dir(o.Remote())
+	if dir == "." {
+		dir = ""
+	}
+	return dir
+}
+
+// add marks o as being processed
+func (c *checkpoint) add(o fs.Object) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d := c.pending[objectDir(o)]; d != nil {
+		d.outstanding++
+	}
+}
+
+// finish marks o as finished with ok set if it was successful
+func (c *checkpoint) finish(o fs.Object, ok bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dir := objectDir(o)
+	if d := c.pending[dir]; d != nil {
+		d.outstanding--
+		if !ok {
+			d.failed = true
+		}
+		c.write(dir, d)
+	}
+}
+
+// dirDone marks all the entries in dir as having been passed on
+func (c *checkpoint) dirDone(dir string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d := c.pending[dir]; d != nil {
+		d.listed = true
+		c.write(dir, d)
+	}
+}
+
+// write records dir in the file if it is finished
+//
+// Call with c.mu held
+func (c *checkpoint) write(dir string, d *checkpointDir) {
+	if !d.listed || d.outstanding > 0 {
+		return
+	}
+	delete(c.pending, dir)
+	if d.failed {
+		return
+	}
+	if _, err := fmt.Fprintf(c.out, "%s %s\n", d.fingerprint, dir); err != nil {
+		fs.Errorf(nil, "Failed to write --checkpoint: %v", err)
+	}
+}
+
+// close closes the file
+func (c *checkpoint) close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out.Close()
+}
@@ -0,0 +1,267 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/dirtree"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// dirLister lists the entries in a directory
+type dirLister func(dir string) (fs.DirEntries, error)
+
+// listingItem is the part of an `rclone lsjson` item read by
+// --dst-listing
+type listingItem struct {
+	Path     string
+	Size     int64
+	ModTime  time.Time
+	IsDir    bool
+	Hashes   map[string]string
+	MimeType string
+}
+
+// listedObject is an object read from the --dst-listing file.
+//
+// The size, modification time and hashes come from the listing. The
+// object is only looked up on the destination if it needs to be read
+// or changed. Once it has been changed they come from the real object
+// as the listing is out of date.
+type listedObject struct {
+	f        fs.Fs
+	remote   string
+	size     int64
+	modTime  time.Time
+	hashes   map[hash.Type]string
+	mimeType string
+
+	mu      sync.Mutex
+	obj     fs.Object // the real object once looked up
+	written bool      // set once obj has been changed
+}
+
+// String returns a description of the Object
+func (o *listedObject) String() string {
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *listedObject) Remote() string {
+	return o.remote
+}
+
+// ModTime returns the modification time from the listing
+func (o *listedObject) ModTime(ctx context.Context) time.Time {
+	if obj := o.changed(); obj != nil {
+		return obj.ModTime(ctx)
+	}
+	return o.modTime
+}
+
+// Size returns the size from the listing
+func (o *listedObject) Size() int64 {
+	if obj := o.changed(); obj != nil {
+		return obj.Size()
+	}
+	return o.size
+}
+
+// Fs returns the destination Fs
+func (o *listedObject) Fs() fs.Info {
+	return o.f
+}
+
+// Hash returns the hash from the listing or "" if it didn't have it
+func (o *listedObject) Hash(ctx context.Context, ty hash.Type) (string, error) {
+	if obj := o.changed(); obj != nil {
+		return obj.Hash(ctx, ty)
+	}
+	if !o.f.Hashes().Contains(ty) {
+		return "", hash.ErrUnsupported
+	}
+	return o.hashes[ty], nil
+}
+
+// MimeType returns the mime type from the listing
+func (o *listedObject) MimeType(ctx context.Context) string {
+	return o.mimeType
+}
+
+// Storable says whether this object can be stored
+func (o *listedObject) Storable() bool {
+	return true
+}
+
+// object looks up the real object on the destination
+func (o *listedObject) object(ctx context.Context) (fs.Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.obj == nil {
+		obj, err := o.f.NewObject(ctx, o.remote)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find object from --dst-listing: %w", err)
+		}
+		o.obj = obj
+	}
+	return o.obj, nil
+}
+
+// changed returns the real object if it has been changed since the
+// listing was made or nil if the listing is still current
+func (o *listedObject) changed() fs.Object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.written {
+		return nil
+	}
+	return o.obj
+}
+
+// setWritten marks the listing as out of date once the real object
+// has been changed, even partially
+func (o *listedObject) setWritten() {
+	o.mu.Lock()
+	o.written = true
+	o.mu.Unlock()
+}
+
+// SetModTime sets the modification time on the real object
+func (o *listedObject) SetModTime(ctx context.Context, t time.Time) error {
+	obj, err := o.object(ctx)
+	if err != nil {
+		return err
+	}
+	defer o.setWritten()
+	return obj.SetModTime(ctx, t)
+}
+
+// Open opens the real object for read
+func (o *listedObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	obj, err := o.object(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Open(ctx, options...)
+}
+
+// Update updates the real object with the contents of in
+func (o *listedObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	obj, err := o.object(ctx)
+	if err != nil {
+		return err
+	}
+	defer o.setWritten()
+	return obj.Update(ctx, in, src, options...)
+}
+
+// Remove removes the real object
+func (o *listedObject) Remove(ctx context.Context) error {
+	obj, err := o.object(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Remove(ctx)
+}
+
+// readDstListing reads the `rclone lsjson -R` output in listingPath
+// into a tree of the entries in f, filtered by fi unless includeAll is
+// set.
+func readDstListing(ctx context.Context, f fs.Fs, fi *filter.Filter, includeAll bool, listingPath string) (dirtree.DirTree, error) {
+	data, err := os.ReadFile(listingPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --dst-listing: %w", err)
+	}
+	var items []listingItem
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --dst-listing: %w", err)
+	}
+	// Sort the items so directories come before their contents,
+	// otherwise adding a file creates its parent directory and the
+	// directory is added again when it is read.
+	for i := range items {
+		items[i].Path = strings.Trim(path.Clean(items[i].Path), "/")
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+	includeDirectory := fi.IncludeDirectory(ctx, f)
+	dt := dirtree.New()
+	for _, item := range items {
+		remote := item.Path
+		if remote == "" || remote == "." {
+			continue
+		}
+		if item.IsDir {
+			if !includeAll {
+				include, err := includeDirectory(remote)
+				if err != nil {
+					return nil, err
+				}
+				if !include {
+					continue
+				}
+			}
+			dt.AddEntry(fs.NewDir(remote, item.ModTime))
+			continue
+		}
+		if !includeAll && !fi.Include(remote, item.Size, item.ModTime, nil) {
+			continue
+		}
+		o := &listedObject{
+			f:        f,
+			remote:   remote,
+			size:     item.Size,
+			modTime:  item.ModTime,
+			hashes:   make(map[hash.Type]string, len(item.Hashes)),
+			mimeType: item.MimeType,
+		}
+		for name, value := range item.Hashes {
+			var ty hash.Type
+			if ty.Set(name) == nil {
+				o.hashes[ty] = value
+			}
+		}
+		dt.AddEntry(o)
+	}
+	dt.Sort()
+	return dt, nil
+}
+
+// dstListDir returns a function to list the destination from the
+// --dst-listing file for march.
+func (s *syncCopyMove) dstListDir(ctx context.Context) (dirLister, error) {
+	dt, err := readDstListing(ctx, s.fdst, s.fi, s.fi.Opt.DeleteExcluded, s.ci.DstListing)
+	if err != nil {
+		return nil, err
+	}
+	fs.Infof(s.fdst, "Using --dst-listing %q instead of listing the destination", s.ci.DstListing)
+	return func(dir string) (fs.DirEntries, error) {
+		entries, ok := dt[dir]
+		if !ok {
+			if dir == "" {
+				// An empty listing means an empty root
+				return nil, nil
+			}
+			return nil, fs.ErrorDirNotFound
+		}
+		return append(fs.DirEntries(nil), entries...), nil
+	}, nil
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Object    = (*listedObject)(nil)
+	_ fs.MimeTyper = (*listedObject)(nil)
+)
@@ -0,0 +1,230 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// hardlinkSidecar is the name of the file written to the root of the
+// destination recording the hard links which couldn't be copied
+// server-side with --preserve-hardlinks
+const hardlinkSidecar = ".rclone-hardlinks"
+
+// hardlinkKey identifies a file which may have several hard links
+type hardlinkKey struct {
+	dev uint64
+	ino uint64
+}
+
+// hardlinkGroup is the first link to a file to be transferred
+type hardlinkGroup struct {
+	done   chan struct{} // closed when the transfer has finished
+	remote string        // remote of the first link
+	dst    fs.Object     // the transferred object or nil if it failed
+}
+
+// hardlinkTracker uploads the content of hard linked source files
+// once for --preserve-hardlinks.
+//
+// The other links are server-side copied from the first if the
+// destination supports it, otherwise they are recorded in the
+// hardlinkSidecar file.
+type hardlinkTracker struct {
+	fdst     fs.Fs
+	fsrc     fs.Fs
+	canCopy  bool // set if fdst can server-side copy
+	mu       sync.Mutex
+	groups   map[hardlinkKey]*hardlinkGroup
+	links    map[string]string // remote of link to remote of the first link, for the sidecar
+	recorded map[string]string // links read from the sidecar of a previous sync
+}
+
+// newHardlinkTracker makes a hardlinkTracker for copies from fsrc to fdst
+func newHardlinkTracker(fdst, fsrc fs.Fs) *hardlinkTracker {
+	return &hardlinkTracker{
+		fdst:     fdst,
+		fsrc:     fsrc,
+		canCopy:  fdst.Features().Copy != nil,
+		groups:   make(map[hardlinkKey]*hardlinkGroup),
+		links:    make(map[string]string),
+		recorded: make(map[string]string),
+	}
+}
+
+// load reads the hardlinkSidecar written by a previous sync, if any,
+// so the links recorded in it aren't transferred again.
+func (h *hardlinkTracker) load(ctx context.Context) error {
+	if h.canCopy {
+		return nil
+	}
+	o, err := h.fdst.NewObject(ctx, hardlinkSidecar)
+	if errors.Is(err, fs.ErrorObjectNotFound) || errors.Is(err, fs.ErrorDirNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read hard links from %q: %w", hardlinkSidecar, err)
+	}
+	b, err := operations.ReadFile(ctx, o)
+	if err != nil {
+		return fmt.Errorf("failed to read hard links from %q: %w", hardlinkSidecar, err)
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		link, target, ok := strings.Cut(line, "\t")
+		if ok {
+			h.recorded[link] = target
+		}
+	}
+	return nil
+}
+
+// recordedLink returns the first link and its object on the
+// destination if a previous sync recorded src as a link to it and it
+// is still the same file in the source, or nil if not.
+func (h *hardlinkTracker) recordedLink(ctx context.Context, src fs.Object, key hardlinkKey) (remote string, dst fs.Object) {
+	remote, ok := h.recorded[src.Remote()]
+	if !ok {
+		return "", nil
+	}
+	first, err := h.fsrc.NewObject(ctx, remote)
+	if err != nil {
+		return "", nil
+	}
+	if firstKey, ok := hardlinkKeyOf(first); !ok || firstKey != key {
+		return "", nil
+	}
+	dst, err = h.fdst.NewObject(ctx, remote)
+	if err != nil {
+		return "", nil
+	}
+	return remote, dst
+}
+
+// hardlinkKeyOf returns the key for src if it has other hard links
+func hardlinkKeyOf(src fs.Object) (key hardlinkKey, ok bool) {
+	do, ok := src.(fs.Inoder)
+	if !ok {
+		return key, false
+	}
+	dev, ino, nlink, ok := do.Inode()
+	if !ok || nlink <= 1 {
+		return key, false
+	}
+	return hardlinkKey{dev: dev, ino: ino}, true
+}
+
+// copy copies src to dst, only transferring the content of a hard
+// linked file for its first link.
+//
+// It is safe to call on a nil hardlinkTracker.
+func (h *hardlinkTracker) copy(ctx context.Context, fdst fs.Fs, dst fs.Object, src fs.Object) error {
+	if h == nil {
+		_, err := operations.Copy(ctx, fdst, dst, src.Remote(), src)
+		return err
+	}
+	key, ok := hardlinkKeyOf(src)
+	if !ok {
+		_, err := operations.Copy(ctx, fdst, dst, src.Remote(), src)
+		return err
+	}
+	h.mu.Lock()
+	group, found := h.groups[key]
+	if !found {
+		group = &hardlinkGroup{
+			done:   make(chan struct{}),
+			remote: src.Remote(),
+		}
+		h.groups[key] = group
+	}
+	h.mu.Unlock()
+
+	if !found {
+		if dst == nil {
+			if remote, firstDst := h.recordedLink(ctx, src, key); firstDst != nil {
+				// The first link is already on the destination from a previous sync
+				fs.Debugf(src, "Keeping hard link to %q recorded in %q", remote, hardlinkSidecar)
+				group.remote = remote
+				group.dst = firstDst
+				close(group.done)
+				h.mu.Lock()
+				h.links[src.Remote()] = remote
+				h.mu.Unlock()
+				return nil
+			}
+		}
+		// The first link transfers the content
+		newDst, err := operations.Copy(ctx, fdst, dst, src.Remote(), src)
+		if err == nil {
+			group.dst = newDst
+		}
+		close(group.done)
+		return err
+	}
+
+	// Wait for the first link to be transferred
+	select {
+	case <-group.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	switch {
+	case group.dst == nil:
+		fs.Debugf(src, "Transferring hard link as the transfer of %q failed", group.remote)
+	case h.canCopy:
+		fs.Debugf(src, "Copying hard link server-side from %q", group.remote)
+		_, err := operations.Copy(ctx, fdst, dst, src.Remote(), group.dst)
+		return err
+	case dst == nil:
+		fs.Infof(src, "Recording hard link to %q in %q", group.remote, hardlinkSidecar)
+		h.mu.Lock()
+		h.links[src.Remote()] = group.remote
+		h.mu.Unlock()
+		return nil
+	default:
+		fs.Debugf(src, "Transferring hard link as it exists on the destination")
+	}
+	_, err := operations.Copy(ctx, fdst, dst, src.Remote(), src)
+	return err
+}
+
+// writeSidecar writes the hard links which weren't copied to the
+// hardlinkSidecar file in the root of the destination.
+//
+// Each line is the link then the file it links to separated by a tab.
+//
+// It is safe to call on a nil hardlinkTracker.
+func (h *hardlinkTracker) writeSidecar(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.links) == 0 {
+		return nil
+	}
+	remotes := make([]string, 0, len(h.links))
+	for remote := range h.links {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	var out strings.Builder
+	for _, remote := range remotes {
+		fmt.Fprintf(&out, "%s\t%s\n", remote, h.links[remote])
+	}
+	if operations.SkipDestructive(ctx, hardlinkSidecar, "write hard links") {
+		return nil
+	}
+	_, err := operations.Rcat(ctx, h.fdst, hardlinkSidecar, io.NopCloser(strings.NewReader(out.String())), time.Now(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to write hard links to %q: %w", hardlinkSidecar, err)
+	}
+	return nil
+}
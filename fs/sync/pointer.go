@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// maxPointerSize is the largest file which will be read as a pointer
+const maxPointerSize = 4096
+
+// pointerResolver follows --pointer-files to the objects they name
+type pointerResolver struct {
+	f  fs.Fs          // the source Fs which pointers are relative to
+	re *regexp.Regexp // matches the remotes of pointer files
+}
+
+// newPointerResolver makes a pointerResolver for the pointer files
+// matching glob in f
+func newPointerResolver(f fs.Fs, glob string) (*pointerResolver, error) {
+	re, err := filter.GlobPathToRegexp(glob, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --pointer-files glob: %w", err)
+	}
+	return &pointerResolver{f: f, re: re}, nil
+}
+
+// pointerObject is the object a pointer file names, transferred as
+// if it were the pointer file
+type pointerObject struct {
+	fs.Object
+	remote string // the remote of the pointer file
+}
+
+// Remote returns the remote of the pointer file
+func (o *pointerObject) Remote() string {
+	return o.remote
+}
+
+// String returns a description of the Object
+func (o *pointerObject) String() string {
+	return o.remote
+}
+
+// resolve returns the object that src names if it is a pointer file,
+// or src if it isn't.
+//
+// The pointer file should contain the path of the object relative to
+// the root of the source.
+func (p *pointerResolver) resolve(ctx context.Context, src fs.Object) (fs.Object, error) {
+	if p == nil || !p.re.MatchString(src.Remote()) {
+		return src, nil
+	}
+	if src.Size() > maxPointerSize {
+		return nil, fmt.Errorf("pointer file too large: %d bytes is more than %d", src.Size(), maxPointerSize)
+	}
+	in, err := operations.Open(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pointer file: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(in, maxPointerSize+1))
+	fs.CheckClose(in, &err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pointer file: %w", err)
+	}
+	if len(data) > maxPointerSize {
+		return nil, fmt.Errorf("pointer file too large: more than %d bytes", maxPointerSize)
+	}
+	target := strings.TrimPrefix(path.Clean(strings.TrimSpace(string(data))), "/")
+	if target == "" || target == "." {
+		return nil, fmt.Errorf("pointer file is empty")
+	}
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return nil, fmt.Errorf("invalid pointer file: %q is outside the source", target)
+	}
+	o, err := p.f.NewObject(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %q named in pointer file: %w", target, err)
+	}
+	fs.Debugf(src, "Following pointer file to %q", target)
+	return &pointerObject{Object: o, remote: src.Remote()}, nil
+}
+
+// resolvePointer follows src if it is a --pointer-files pointer,
+// returning false if it couldn't be resolved and should be skipped.
+func (s *syncCopyMove) resolvePointer(ctx context.Context, src fs.Object) (fs.Object, bool) {
+	o, err := s.pointers.resolve(ctx, src)
+	if err != nil {
+		fs.Errorf(src, "%v", err)
+		s.processFileError(err)
+		s.checkpoint.add(src)
+		s.checkpoint.finish(src, false)
+		s.logger(ctx, operations.TransferError, src, nil, err)
+		return nil, false
+	}
+	return o, true
+}
@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/rc"
 )
 
@@ -27,6 +28,10 @@ func init() {
 - createEmptySrcDirs - create empty src directories on destination if set
 ` + moveHelp + `
 
+The whole ` + name + ` is retried as set by --retries and --retries-sleep
+if it fails with errors which can be retried, as it is on the command
+line.
+
 See the [` + name + `](/commands/rclone_` + name + `/) command for more information on the above.`,
 		})
 	}
@@ -48,15 +53,21 @@ func rcSyncCopyMove(ctx context.Context, in rc.Params, name string) (out rc.Para
 	}
 	switch name {
 	case "sync":
-		return nil, Sync(ctx, dstFs, srcFs, createEmptySrcDirs)
+		return nil, accounting.Retry(ctx, true, func() error {
+			return Sync(ctx, dstFs, srcFs, createEmptySrcDirs)
+		})
 	case "copy":
-		return nil, CopyDir(ctx, dstFs, srcFs, createEmptySrcDirs)
+		return nil, accounting.Retry(ctx, true, func() error {
+			return CopyDir(ctx, dstFs, srcFs, createEmptySrcDirs)
+		})
 	case "move":
 		deleteEmptySrcDirs, err := in.GetBool("deleteEmptySrcDirs")
 		if rc.NotErrParamNotFound(err) {
 			return nil, err
 		}
-		return nil, MoveDir(ctx, dstFs, srcFs, deleteEmptySrcDirs, createEmptySrcDirs)
+		return nil, accounting.Retry(ctx, true, func() error {
+			return MoveDir(ctx, dstFs, srcFs, deleteEmptySrcDirs, createEmptySrcDirs)
+		})
 	}
 	panic("unknown rcSyncCopyMove type")
 }
@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// Reasons files are skipped by the sync itself rather than by
+// operations.NeedTransferReason
+const (
+	skipInUse            operations.SkipReason = "in use"
+	skipMaxTransferCount operations.SkipReason = "max transfer count"
+)
+
+// skipCounter tallies the files the sync skipped by reason so they
+// can be summarised at the end.
+type skipCounter struct {
+	mu     sync.Mutex
+	counts map[operations.SkipReason]int64
+}
+
+func newSkipCounter() *skipCounter {
+	return &skipCounter{
+		counts: make(map[operations.SkipReason]int64),
+	}
+}
+
+// add counts a file skipped for reason
+func (c *skipCounter) add(reason operations.SkipReason) {
+	c.mu.Lock()
+	c.counts[reason]++
+	c.mu.Unlock()
+}
+
+// snapshot returns a copy of the counts or nil if nothing was skipped
+func (c *skipCounter) snapshot() map[operations.SkipReason]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.counts) == 0 {
+		return nil
+	}
+	counts := make(map[operations.SkipReason]int64, len(c.counts))
+	for reason, n := range c.counts {
+		counts[reason] = n
+	}
+	return counts
+}
+
+// String returns a summary of the skipped files grouped by reason,
+// most common first, or "" if none were skipped.
+func (c *skipCounter) String() string {
+	counts := c.snapshot()
+	reasons := make([]operations.SkipReason, 0, len(counts))
+	var total int64
+	for reason, n := range counts {
+		reasons = append(reasons, reason)
+		total += n
+	}
+	if total == 0 {
+		return ""
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", counts[reason], reason)
+	}
+	return fmt.Sprintf("Skipped %d files: %s", total, strings.Join(parts, ", "))
+}
+
+// log prints the summary at the --stats-log-level if anything was
+// skipped.
+func (c *skipCounter) log(ci *fs.ConfigInfo) {
+	if summary := c.String(); summary != "" {
+		fs.LogLevelPrintf(ci.StatsLogLevel, nil, "%s", summary)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rclone/rclone/fs"
@@ -30,6 +33,16 @@ var ErrorMaxDurationReached = errors.New("max transfer duration reached as set b
 // duration limit is reached.
 var ErrorMaxDurationReachedFatal = fserrors.FatalError(ErrorMaxDurationReached)
 
+// ErrorMaxTransferCountReached defines error when the number of files
+// transferred reaches --max-transfer-count. It wraps
+// accounting.ErrorMaxTransferLimitReached so it gets the same exit code.
+var ErrorMaxTransferCountReached = fmt.Errorf("max transfer count reached as set by --max-transfer-count: %w", accounting.ErrorMaxTransferLimitReached)
+
+// ErrorMaxTransferCountReachedGraceful is returned when the max
+// transfer count is reached. Transfers in progress are allowed to
+// finish but no new ones are started.
+var ErrorMaxTransferCountReachedGraceful = fserrors.NoRetryError(ErrorMaxTransferCountReached)
+
 type syncCopyMove struct {
 	// parameters
 	fdst               fs.Fs
@@ -72,6 +85,8 @@ type syncCopyMove struct {
 	err                    error                  // normal error from copy process
 	noRetryErr             error                  // error with NoRetry set
 	fatalErr               error                  // fatal error
+	errs                   []error                // distinct errors seen, up to maxErrors
+	errsSeen               map[string]struct{}    // text of the errors in errs
 	commonHash             hash.Type              // common hash type between src and dst
 	modifyWindow           time.Duration          // modify window between fsrc, fdst
 	renameMapMu            sync.Mutex             // mutex to protect the below
@@ -85,6 +100,8 @@ type syncCopyMove struct {
 	backupDir              fs.Fs                  // place to store overwrites/deletes
 	checkFirst             bool                   // if set run all the checkers before starting transfers
 	maxDurationEndTime     time.Time              // end time if --max-duration is set
+	transferCount          atomic.Int64           // number of transfers started, for --max-transfer-count
+	dstListing             dirLister              // lists the dst from --dst-listing if set
 	logger                 operations.LoggerFn    // LoggerFn used to report the results of a sync (or bisync) to an io.Writer
 	usingLogger            bool                   // whether we are using logger
 	setDirMetadata         bool                   // if set we set the directory metadata
@@ -94,6 +111,81 @@ type syncCopyMove struct {
 	setDirModTimes         []setDirModTime        // directories that need their modtime set
 	setDirModTimesMaxLevel int                    // max level of the directories to set
 	modifiedDirs           map[string]struct{}    // dirs with changed contents (if s.setDirModTimeAfter)
+	hashes                 *hashCache             // hashes read during this sync if --track-renames by hash
+	manifestMu             sync.Mutex             // protect manifestFound
+	manifest               []string               // files in --sync-manifest in the order listed
+	manifestFound          map[string]bool        // files in --sync-manifest, true if found in the source
+	checkpoint             *checkpoint            // --checkpoint file if set
+	pointers               *pointerResolver       // resolves --pointer-files if set
+	hardlinks              *hardlinkTracker       // tracks hard linked files if --preserve-hardlinks
+	apiLimit               *apiLimiter            // limits backend operations if --max-concurrent-api
+	trash                  *trash                 // where deleted files go if --trash-dir
+	skips                  *skipCounter           // files skipped by reason
+}
+
+// hashCache caches the hashes of objects for the duration of a sync
+// so each object is only hashed once for each hash type.
+//
+// It is only used with --track-renames by hash as that is when
+// objects are hashed more than once.
+type hashCache struct {
+	mu     sync.Mutex
+	hashes map[hashCacheKey]string
+}
+
+// hashCacheKey identifies an object and hash type in the hashCache
+type hashCacheKey struct {
+	o  fs.ObjectInfo
+	ht hash.Type
+}
+
+func newHashCache() *hashCache {
+	return &hashCache{
+		hashes: make(map[hashCacheKey]string),
+	}
+}
+
+// Hash returns the hash of o, reading it from the cache if possible.
+//
+// It satisfies operations.HashFn and is safe to call on a nil
+// hashCache.
+func (c *hashCache) Hash(ctx context.Context, o fs.ObjectInfo, ht hash.Type) (string, error) {
+	// Objects which can't be used as map keys are never cached
+	if c == nil || !reflect.TypeOf(o).Comparable() {
+		return o.Hash(ctx, ht)
+	}
+	key := hashCacheKey{o: o, ht: ht}
+	c.mu.Lock()
+	sum, found := c.hashes[key]
+	c.mu.Unlock()
+	if found {
+		return sum, nil
+	}
+	sum, err := o.Hash(ctx, ht)
+	if err != nil {
+		return sum, err
+	}
+	c.mu.Lock()
+	c.hashes[key] = sum
+	c.mu.Unlock()
+	return sum, nil
+}
+
+// forget removes the hashes of o from the cache, for use when o has
+// been updated.
+//
+// It is safe to call on a nil hashCache.
+func (c *hashCache) forget(o fs.ObjectInfo) {
+	if c == nil || o == nil || !reflect.TypeOf(o).Comparable() {
+		return
+	}
+	c.mu.Lock()
+	for key := range c.hashes {
+		if key.o == o {
+			delete(c.hashes, key)
+		}
+	}
+	c.mu.Unlock()
 }
 
 // For keeping track of delayed modtime sets
@@ -131,6 +223,21 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	}
 	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
+	var (
+		manifest      []string
+		manifestFound map[string]bool
+	)
+	if ci.SyncManifest != "" {
+		var err error
+		ctx, fi, manifest, err = manifestFilter(ctx, fi, ci.SyncManifest)
+		if err != nil {
+			return nil, err
+		}
+		manifestFound = make(map[string]bool, len(manifest))
+		for _, remote := range manifest {
+			manifestFound[remote] = false
+		}
+	}
 	s := &syncCopyMove{
 		ci:                     ci,
 		fi:                     fi,
@@ -155,15 +262,20 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		commonHash:             fsrc.Hashes().Overlap(fdst.Hashes()).GetOne(),
 		modifyWindow:           fs.GetModifyWindow(ctx, fsrc, fdst),
 		trackRenamesCh:         make(chan fs.Object, ci.Checkers),
-		checkFirst:             ci.CheckFirst,
+		checkFirst:             ci.CheckFirst || ci.CheckFreeSpace,
 		setDirMetadata:         ci.Metadata && fsrc.Features().ReadDirMetadata && fdst.Features().WriteDirMetadata,
 		setDirModTime:          (!ci.NoUpdateDirModTime && fsrc.Features().CanHaveEmptyDirectories) && (fdst.Features().WriteDirSetModTime || fdst.Features().MkdirMetadata != nil || fdst.Features().DirSetModTime != nil),
 		setDirModTimeAfter:     !ci.NoUpdateDirModTime && (!copyEmptySrcDirs || fsrc.Features().CanHaveEmptyDirectories && fdst.Features().DirModTimeUpdatesOnWrite),
 		modifiedDirs:           make(map[string]struct{}),
+		manifest:               manifest,
+		manifestFound:          manifestFound,
 	}
 
 	s.logger, s.usingLogger = operations.GetLogger(ctx)
 
+	// Bound the source files open for transfer if --max-open-files
+	ctx = operations.WithOpenLimit(ctx, ci.MaxOpenFiles)
+
 	if deleteMode == fs.DeleteModeOff {
 		loggerOpt := operations.GetLoggerOpt(ctx)
 		loggerOpt.DeleteModeOff = true
@@ -218,6 +330,16 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		}
 		s.noTraverse = false
 	}
+	if ci.DstListing != "" {
+		if s.noTraverse {
+			fs.Errorf(nil, "Ignoring --no-traverse with --dst-listing")
+			s.noTraverse = false
+		}
+		s.dstListing, err = s.dstListDir(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 	s.trackRenamesStrategy, err = parseTrackRenamesStrategy(ci.TrackRenamesStrategy)
 	if err != nil {
 		return nil, err
@@ -263,7 +385,43 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 			fs.Errorf(nil, "Ignoring --no-traverse with --track-renames")
 			s.noTraverse = false
 		}
+		// Serve hashes from a cache as each object is hashed to
+		// find renames then again to check the transfer
+		if s.trackRenamesStrategy.hash() {
+			s.hashes = newHashCache()
+			s.ctx = operations.WithHashFn(s.ctx, s.hashes.Hash)
+			s.inCtx = operations.WithHashFn(s.inCtx, s.hashes.Hash)
+		}
+	}
+	if ci.Checkpoint != "" && s.deleteMode != fs.DeleteModeOnly {
+		if s.trackRenames {
+			return nil, errors.New("can't use --checkpoint with --track-renames")
+		}
+		s.checkpoint, err = newCheckpoint(ctx, fdst, fsrc, ci.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ci.PointerFiles != "" {
+		if s.DoMove {
+			return nil, errors.New("can't use --pointer-files with move as it would move the files pointed to")
+		}
+		s.pointers, err = newPointerResolver(fsrc, ci.PointerFiles)
+		if err != nil {
+			return nil, err
+		}
 	}
+	if ci.PreserveHardlinks {
+		if s.DoMove {
+			return nil, errors.New("can't use --preserve-hardlinks with move")
+		}
+		s.hardlinks = newHardlinkTracker(fdst, fsrc)
+		if err := s.hardlinks.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	s.apiLimit = newAPILimiter(ci.MaxConcurrentAPI)
+	s.skips = newSkipCounter()
 	// Make Fs for --backup-dir if required
 	if ci.BackupDir != "" || ci.Suffix != "" {
 		var err error
@@ -272,6 +430,13 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 			return nil, err
 		}
 	}
+	if s.deleteMode != fs.DeleteModeOff {
+		var err error
+		s.trash, err = newTrash(ctx, fdst, fsrc)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if len(ci.CompareDest) > 0 {
 		var err error
 		s.compareCopyDest, err = operations.GetCompareDest(ctx)
@@ -311,6 +476,38 @@ outer:
 	s.srcFilesResult <- nil
 }
 
+// maxErrors is the maximum number of distinct errors kept for Errors
+const maxErrors = 100
+
+// addError records err in the list of distinct errors unless an error
+// with the same text has been seen already or the list is full.
+//
+// Call with errorMu held.
+func (s *syncCopyMove) addError(err error) {
+	if len(s.errs) >= maxErrors {
+		return
+	}
+	text := err.Error()
+	if _, found := s.errsSeen[text]; found {
+		return
+	}
+	if s.errsSeen == nil {
+		s.errsSeen = make(map[string]struct{})
+	}
+	s.errsSeen[text] = struct{}{}
+	s.errs = append(s.errs, err)
+}
+
+// Errors returns the distinct errors seen so far in the order they
+// happened, up to a maximum of maxErrors.
+//
+// currentError should be used to find the error to return.
+func (s *syncCopyMove) Errors() []error {
+	s.errorMu.Lock()
+	defer s.errorMu.Unlock()
+	return append([]error(nil), s.errs...)
+}
+
 // This checks the types of errors returned while copying files
 func (s *syncCopyMove) processError(err error) {
 	if err == nil {
@@ -318,7 +515,7 @@ func (s *syncCopyMove) processError(err error) {
 	}
 	if err == context.DeadlineExceeded {
 		err = fserrors.NoRetryError(err)
-	} else if err == accounting.ErrorMaxTransferLimitReachedGraceful {
+	} else if err == accounting.ErrorMaxTransferLimitReachedGraceful || err == ErrorMaxTransferCountReachedGraceful {
 		if s.inCtx.Err() == nil {
 			fs.Logf(nil, "%v - stopping transfers", err)
 			// Cancel the march and stop the pipes
@@ -330,6 +527,7 @@ func (s *syncCopyMove) processError(err error) {
 	}
 	s.errorMu.Lock()
 	defer s.errorMu.Unlock()
+	s.addError(err)
 	switch {
 	case fserrors.IsFatalError(err):
 		if !s.aborting() {
@@ -344,6 +542,24 @@ func (s *syncCopyMove) processError(err error) {
 	}
 }
 
+// This processes an error which arose while handling a single file
+//
+// Normally this is the same as processError, but if
+// --suppress-fatal-abort is set a fatal error is recorded as a normal
+// error so the sync carries on with the other files rather than being
+// cancelled.
+func (s *syncCopyMove) processFileError(err error) {
+	if err != nil && s.ci.SuppressFatalAbort && fserrors.IsFatalError(err) {
+		fs.Errorf(nil, "Not cancelling sync due to fatal error on a single file: %v", err)
+		s.errorMu.Lock()
+		s.addError(err)
+		s.err = err
+		s.errorMu.Unlock()
+		return
+	}
+	s.processError(err)
+}
+
 // Returns the current error (if any) in the order of precedence
 //
 //	fatalErr
@@ -373,25 +589,36 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 		}
 		src := pair.Src
 		var err error
+		forwarded, failed := false, false
 		tr := accounting.Stats(s.ctx).NewCheckingTransfer(src, "checking")
 		// Check to see if can store this
 		if src.Storable() {
-			needTransfer := operations.NeedTransfer(s.ctx, pair.Dst, pair.Src)
-			if needTransfer {
-				NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, pair.Dst, pair.Src, s.compareCopyDest, s.backupDir)
-				if err != nil {
-					s.processError(err)
-					s.logger(s.ctx, operations.TransferError, pair.Src, pair.Dst, err)
-				}
-				if NoNeedTransfer {
-					needTransfer = false
+			var needTransfer bool
+			var reason operations.SkipReason
+			s.apiLimit.do(s.ctx, func() {
+				needTransfer, reason = operations.NeedTransferReason(s.ctx, pair.Dst, pair.Src)
+				if needTransfer {
+					NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, pair.Dst, pair.Src, s.compareCopyDest, s.backupDir)
+					if err != nil {
+						failed = true
+						s.processFileError(err)
+						s.logger(s.ctx, operations.TransferError, pair.Src, pair.Dst, err)
+					}
+					if NoNeedTransfer {
+						// Only a --compare-dest match is a skip, --copy-dest has copied the file
+						needTransfer, reason = false, ""
+						if len(s.ci.CompareDest) > 0 {
+							reason = operations.SkipCompareDest
+						}
+					}
 				}
-			}
+			})
 			// Fix case for case insensitive filesystems
 			if s.ci.FixCase && !s.ci.Immutable && src.Remote() != pair.Dst.Remote() {
 				if newDst, err := operations.Move(s.ctx, s.fdst, nil, src.Remote(), pair.Dst); err != nil {
 					fs.Errorf(pair.Dst, "Error while attempting to rename to %s: %v", src.Remote(), err)
-					s.processError(err)
+					failed = true
+					s.processFileError(err)
 				} else {
 					fs.Infof(pair.Dst, "Fixed case by renaming to: %s", src.Remote())
 					pair.Dst = newDst
@@ -402,7 +629,8 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 				if s.ci.Immutable && pair.Dst != nil {
 					err := fs.CountError(s.ctx, fserrors.NoRetryError(fs.ErrorImmutableModified))
 					fs.Errorf(pair.Dst, "Source and destination exist but do not match: %v", err)
-					s.processError(err)
+					failed = true
+					s.processFileError(err)
 				} else {
 					if pair.Dst != nil {
 						s.markDirModifiedObject(pair.Dst)
@@ -413,7 +641,8 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 					if pair.Dst != nil && s.backupDir != nil {
 						err := operations.MoveBackupDir(s.ctx, s.backupDir, pair.Dst)
 						if err != nil {
-							s.processError(err)
+							failed = true
+							s.processFileError(err)
 							s.logger(s.ctx, operations.TransferError, pair.Src, pair.Dst, err)
 						} else {
 							// If successful zero out the dst as it is no longer there and copy the file
@@ -422,15 +651,20 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 							if !ok {
 								return
 							}
+							forwarded = true
 						}
 					} else {
 						ok = out.Put(s.inCtx, pair)
 						if !ok {
 							return
 						}
+						forwarded = true
 					}
 				}
 			} else {
+				if reason != "" {
+					s.skips.add(reason)
+				}
 				// If moving need to delete the files we don't need to copy
 				if s.DoMove {
 					// Delete src if no error on copy
@@ -438,6 +672,10 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 						fs.Logf(src, "Not removing source file as it is the same file as the destination")
 					} else if s.ci.IgnoreExisting {
 						fs.Debugf(src, "Not removing source file as destination file exists and --ignore-existing is set")
+					} else if s.ci.NoOverwrite {
+						fs.Debugf(src, "Not removing source file as destination file exists and --no-overwrite is set")
+					} else if s.ci.SkipEmptyOverwrite && src.Size() == 0 && pair.Dst.Size() > 0 {
+						fs.Debugf(src, "Not removing source file as it is empty and --skip-empty-overwrite is set")
 					} else if s.checkFirst && s.ci.OrderBy != "" {
 						// If we want perfect ordering then use the transfers to delete the file
 						//
@@ -446,14 +684,19 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 						if !ok {
 							return
 						}
+						forwarded = true
 					} else {
 						deleteFileErr := operations.DeleteFile(s.ctx, src)
-						s.processError(deleteFileErr)
+						failed = deleteFileErr != nil
+						s.processFileError(deleteFileErr)
 						s.logger(s.ctx, operations.TransferError, pair.Src, pair.Dst, deleteFileErr)
 					}
 				}
 			}
 		}
+		if !forwarded {
+			s.checkpoint.finish(src, !failed)
+		}
 		tr.Done(s.ctx, err)
 	}
 }
@@ -468,7 +711,11 @@ func (s *syncCopyMove) pairRenamer(in *pipe, out *pipe, fraction int, wg *sync.W
 			return
 		}
 		src := pair.Src
-		if !s.tryRename(src) {
+		var renamed bool
+		s.apiLimit.do(s.ctx, func() {
+			renamed = s.tryRename(src)
+		})
+		if !renamed {
 			// pass on if not renamed
 			fs.Debugf(src, "Need to transfer - No matching file found at Destination")
 			ok = out.Put(s.inCtx, pair)
@@ -490,23 +737,73 @@ func (s *syncCopyMove) pairCopyOrMove(ctx context.Context, in *pipe, fdst fs.Fs,
 		}
 		src := pair.Src
 		dst := pair.Dst
-		if s.DoMove {
-			if src != dst {
-				_, err = operations.MoveTransfer(ctx, fdst, dst, src.Remote(), src)
+		if src != dst && s.ci.SkipInUse && s.inUse(ctx, src) {
+			// Leave the file for the next run
+			s.skips.add(skipInUse)
+			s.checkpoint.finish(src, false)
+			continue
+		}
+		if src != dst && !s.reserveTransfer() {
+			// Leave the file for the next run
+			s.skips.add(skipMaxTransferCount)
+			s.processError(ErrorMaxTransferCountReachedGraceful)
+			continue
+		}
+		s.apiLimit.do(ctx, func() {
+			if s.DoMove {
+				if src != dst {
+					_, err = operations.MoveTransfer(ctx, fdst, dst, src.Remote(), src)
+				} else {
+					// src == dst signals delete the src
+					err = operations.DeleteFile(ctx, src)
+				}
 			} else {
-				// src == dst signals delete the src
-				err = operations.DeleteFile(ctx, src)
+				err = s.hardlinks.copy(ctx, fdst, dst, src)
 			}
-		} else {
-			_, err = operations.Copy(ctx, fdst, dst, src.Remote(), src)
-		}
-		s.processError(err)
+		})
+		// dst may have been updated so its hashes are stale
+		s.hashes.forget(dst)
+		s.processFileError(err)
 		if err != nil {
 			s.logger(ctx, operations.TransferError, src, dst, err)
 		}
+		s.checkpoint.finish(src, err == nil)
 	}
 }
 
+// reserveTransfer counts a transfer towards --max-transfer-count,
+// returning false if the limit has been reached and the transfer
+// should not be started.
+func (s *syncCopyMove) reserveTransfer() bool {
+	if s.ci.MaxTransferCount <= 0 {
+		return true
+	}
+	return s.transferCount.Add(1) <= s.ci.MaxTransferCount
+}
+
+// inUse returns true if src looks like it is still being written so
+// should be skipped with --skip-in-use.
+//
+// This is only checked for local sources by reading the file again
+// to see if its size or modification time has changed since it was
+// listed.
+func (s *syncCopyMove) inUse(ctx context.Context, src fs.Object) bool {
+	f, ok := src.Fs().(fs.Fs)
+	if !ok || !f.Features().IsLocal {
+		return false
+	}
+	now, err := f.NewObject(ctx, src.Remote())
+	if err != nil {
+		fs.Infof(src, "Skipping as it couldn't be read again so may be being modified: %v", err)
+		return true
+	}
+	if now.Size() != src.Size() || !now.ModTime(ctx).Equal(src.ModTime(ctx)) {
+		fs.Infof(src, "Skipping as it is being modified - it will be transferred on the next run")
+		return true
+	}
+	return false
+}
+
 // This starts the background checkers.
 func (s *syncCopyMove) startCheckers() {
 	s.checkerWg.Add(s.ci.Checkers)
@@ -532,6 +829,34 @@ func (s *syncCopyMove) startTransfers() {
 	}
 }
 
+// checkFreeSpace checks the destination has enough free space for
+// everything queued for transfer.
+//
+// This needs all the checks to have been done.
+func (s *syncCopyMove) checkFreeSpace(ctx context.Context) error {
+	_, need := s.toBeUploaded.Stats()
+	doAbout := s.fdst.Features().About
+	if doAbout == nil {
+		fs.Infof(s.fdst, "Can't check free space as the destination doesn't support about")
+		return nil
+	}
+	usage, err := doAbout(ctx)
+	if err != nil {
+		fs.Infof(s.fdst, "Can't check free space: %v", err)
+		return nil
+	}
+	if usage.Free == nil {
+		fs.Infof(s.fdst, "Can't check free space as the destination doesn't report it")
+		return nil
+	}
+	free := *usage.Free
+	fs.Debugf(s.fdst, "Need %v for transfers with %v free", fs.SizeSuffix(need).ByteUnit(), fs.SizeSuffix(free).ByteUnit())
+	if need > free {
+		return fserrors.FatalError(fmt.Errorf("not enough free space on destination: need %v but only %v is free", fs.SizeSuffix(need).ByteUnit(), fs.SizeSuffix(free).ByteUnit()))
+	}
+	return nil
+}
+
 // This stops the background transfers
 func (s *syncCopyMove) stopTransfers() {
 	s.toBeUploaded.Close()
@@ -592,7 +917,7 @@ func (s *syncCopyMove) startDeleters() {
 	s.deletersWg.Add(1)
 	go func() {
 		defer s.deletersWg.Done()
-		err := operations.DeleteFilesWithBackupDir(s.ctx, s.deleteFilesCh, s.backupDir)
+		err := operations.DeleteFilesWithBackupDir(s.ctx, s.deleteFilesCh, s.deleteBackupDir())
 		s.processError(err)
 	}()
 }
@@ -627,29 +952,56 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 		return fs.ErrorNotDeleting
 	}
 
-	// Delete the spare files
+	// Delete the spare files, queued deepest first. The deleters
+	// run concurrently so this order is only best-effort.
+	var remotes []string
+	for remote := range s.dstFiles {
+		if checkSrcMap {
+			_, exists := s.srcFiles[remote]
+			if exists {
+				continue
+			}
+		}
+		remotes = append(remotes, remote)
+	}
+	sortDeepestFirst(remotes)
 	toDelete := make(fs.ObjectsChan, s.ci.Checkers)
 	go func() {
 	outer:
-		for remote, o := range s.dstFiles {
-			if checkSrcMap {
-				_, exists := s.srcFiles[remote]
-				if exists {
-					continue
-				}
-			}
+		for _, remote := range remotes {
 			if s.aborting() {
 				break
 			}
 			select {
 			case <-s.ctx.Done():
 				break outer
-			case toDelete <- o:
+			case toDelete <- s.dstFiles[remote]:
 			}
 		}
 		close(toDelete)
 	}()
-	return operations.DeleteFilesWithBackupDir(s.ctx, toDelete, s.backupDir)
+	return operations.DeleteFilesWithBackupDir(s.ctx, toDelete, s.deleteBackupDir())
+}
+
+// sortDeepestFirst sorts remotes so the deepest paths come first,
+// then by name.
+func sortDeepestFirst(remotes []string) {
+	sort.Slice(remotes, func(i, j int) bool {
+		di, dj := strings.Count(remotes[i], "/"), strings.Count(remotes[j], "/")
+		if di != dj {
+			return di > dj
+		}
+		return remotes[i] < remotes[j]
+	})
+}
+
+// deleteBackupDir returns where files deleted by the sync should be
+// moved to, or nil to delete them.
+func (s *syncCopyMove) deleteBackupDir() fs.Fs {
+	if dir := s.trash.fs(); dir != nil {
+		return dir
+	}
+	return s.backupDir
 }
 
 // This deletes the empty directories in the slice passed in.  It
@@ -766,7 +1118,7 @@ func (s *syncCopyMove) renameID(obj fs.Object, renamesStrategy trackRenamesStrat
 
 	if renamesStrategy.hash() {
 		var err error
-		hash, err := obj.Hash(s.ctx, s.commonHash)
+		hash, err := s.hashes.Hash(s.ctx, obj, s.commonHash)
 		if err != nil {
 			fs.Debugf(obj, "Hash failed: %v", err)
 			return ""
@@ -863,7 +1215,10 @@ func (s *syncCopyMove) makeRenameMap() {
 				// only create hash for dst fs.Object if its size could match
 				if _, found := possibleSizes[obj.Size()]; found {
 					tr := accounting.Stats(s.ctx).NewCheckingTransfer(obj, "renaming")
-					hash := s.renameID(obj, s.trackRenamesStrategy, s.modifyWindow)
+					var hash string
+					s.apiLimit.do(s.ctx, func() {
+						hash = s.renameID(obj, s.trackRenamesStrategy, s.modifyWindow)
+					})
 
 					if hash != "" {
 						s.pushRenameMap(hash, obj)
@@ -926,6 +1281,9 @@ func (s *syncCopyMove) run() error {
 		return nil
 	}
 
+	// Expire old trash while the sync runs
+	s.trash.startSweep(s.ctx)
+
 	// Start background checking and transferring pipeline
 	s.startCheckers()
 	s.startRenamers()
@@ -948,9 +1306,16 @@ func (s *syncCopyMove) run() error {
 		DstIncludeAll:          s.fi.Opt.DeleteExcluded,
 		NoCheckDest:            s.noCheckDest,
 		NoUnicodeNormalization: s.noUnicodeNormalization,
+		DstListDir:             s.dstListing,
+		Limiter:                s.apiLimit.weighted(),
 	}
 	s.processError(m.Run(s.ctx))
 
+	// Report any files in the manifest which weren't in the source
+	if s.manifestFound != nil && s.inCtx.Err() == nil {
+		s.processError(s.checkManifest())
+	}
+
 	s.stopTrackRenames()
 	if s.trackRenames {
 		// Build the map of the remaining dstFiles by hash
@@ -967,13 +1332,21 @@ func (s *syncCopyMove) run() error {
 	// Stop background checking and transferring pipeline
 	s.stopCheckers()
 	if s.checkFirst {
-		fs.Infof(s.fdst, "Checks finished, now starting transfers")
-		s.startTransfers()
+		if s.ci.CheckFreeSpace {
+			s.processError(s.checkFreeSpace(s.ctx))
+		}
+		if !s.aborting() {
+			fs.Infof(s.fdst, "Checks finished, now starting transfers")
+			s.startTransfers()
+		}
 	}
 	s.stopRenamers()
 	s.stopTransfers()
 	s.stopDeleters()
 
+	// Record any hard links which couldn't be copied
+	s.processError(s.hardlinks.writeSidecar(s.ctx))
+
 	// Delete files after
 	if s.deleteMode == fs.DeleteModeAfter {
 		if s.currentError() != nil && !s.ci.IgnoreErrors {
@@ -1004,6 +1377,8 @@ func (s *syncCopyMove) run() error {
 		s.processError(s.deleteEmptyDirectories(s.ctx, s.fsrc, s.srcMoveEmptyDirs))
 	}
 
+	s.processError(s.trash.stopSweep())
+
 	// Read the error out of the contexts if there is one
 	s.processError(s.ctx.Err())
 	s.processError(s.inCtx.Err())
@@ -1018,6 +1393,23 @@ func (s *syncCopyMove) run() error {
 	if s.deleteMode != fs.DeleteModeOnly && accounting.Stats(s.ctx).GetTransfers() == 0 && s.currentError() == nil {
 		fs.Infof(nil, "There was nothing to transfer")
 	}
+	s.skips.log(s.ci)
+
+	// Finish with the --checkpoint file, removing it if the sync completed
+	if s.checkpoint != nil {
+		s.processError(s.checkpoint.close())
+		if s.currentError() == nil {
+			s.processError(os.Remove(s.ci.Checkpoint))
+		}
+	}
+
+	// List the distinct errors together if there was more than one
+	if errs := s.Errors(); len(errs) > 1 {
+		fs.Logf(s.fdst, "There were %d distinct errors:", len(errs))
+		for _, err := range errs {
+			fs.Logf(s.fdst, "  %v", err)
+		}
+	}
 
 	// cancel the contexts to free resources
 	s.inCancel()
@@ -1025,6 +1417,76 @@ func (s *syncCopyMove) run() error {
 	return s.currentError()
 }
 
+// SkipDirObjects is called by march with the source listing of dir
+// before its entries are passed on. It skips the objects in
+// directories finished in a previous run recorded in --checkpoint.
+func (s *syncCopyMove) SkipDirObjects(dir string, srcList fs.DirEntries) bool {
+	if !s.checkpoint.skipDir(dir, srcList) {
+		return false
+	}
+	fs.Debugf(dir, "Skipping objects finished in a previous run recorded in --checkpoint")
+	for _, entry := range srcList {
+		if o, ok := entry.(fs.Object); ok {
+			s.markParentNotEmpty(o)
+			s.markInManifest(o)
+		}
+	}
+	return true
+}
+
+// DirDone is called by march once all the entries in dir have been
+// passed on
+func (s *syncCopyMove) DirDone(dir string) {
+	s.checkpoint.dirDone(dir)
+}
+
+// manifestFilter returns a ctx with a filter which only includes the
+// files listed in the --sync-manifest file at manifestPath, along with
+// the files listed in the order they appear in the manifest.
+func manifestFilter(ctx context.Context, fi *filter.Filter, manifestPath string) (context.Context, *filter.Filter, []string, error) {
+	if fi.HaveFilesFrom() {
+		return ctx, nil, nil, errors.New("can't use --sync-manifest with --files-from")
+	}
+	opt := fi.Opt
+	opt.FilesFrom = []string{manifestPath}
+	newFi, err := filter.NewFilter(&opt)
+	if err != nil {
+		return ctx, nil, nil, fmt.Errorf("failed to read --sync-manifest: %w", err)
+	}
+	return filter.ReplaceConfig(ctx, newFi), newFi, newFi.FilesInOrder(), nil
+}
+
+// markInManifest marks the object as found in the --sync-manifest
+func (s *syncCopyMove) markInManifest(o fs.Object) {
+	if s.manifestFound == nil {
+		return
+	}
+	s.manifestMu.Lock()
+	if _, ok := s.manifestFound[o.Remote()]; ok {
+		s.manifestFound[o.Remote()] = true
+	}
+	s.manifestMu.Unlock()
+}
+
+// checkManifest returns an error if any of the files in the
+// --sync-manifest weren't found in the source, logging them in the
+// order they appear in the manifest
+func (s *syncCopyMove) checkManifest() error {
+	s.manifestMu.Lock()
+	defer s.manifestMu.Unlock()
+	missing := 0
+	for _, remote := range s.manifest {
+		if !s.manifestFound[remote] {
+			fs.Errorf(remote, "Listed in --sync-manifest but not found in source")
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+	return fserrors.NoRetryError(fmt.Errorf("%d files listed in --sync-manifest not found in source", missing))
+}
+
 // DstOnly have an object which is in the destination only
 func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	if s.deleteMode == fs.DeleteModeOff {
@@ -1044,7 +1506,15 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	}
 	switch x := dst.(type) {
 	case fs.Object:
+		if s.hardlinks != nil && x.Remote() == hardlinkSidecar {
+			fs.Debugf(x, "Not deleting as written by --preserve-hardlinks")
+			return false
+		}
 		s.logger(s.ctx, operations.MissingOnSrc, nil, x, nil)
+		if s.fi.DeleteProtected(x.Remote()) {
+			fs.Debugf(x, "Not deleting as protected by --delete-protect")
+			return false
+		}
 		switch s.deleteMode {
 		case fs.DeleteModeAfter:
 			// record object as needs deleting
@@ -1121,8 +1591,8 @@ func (s *syncCopyMove) copyDirMetadata(ctx context.Context, f fs.Fs, dst fs.Dire
 				newDst, err = operations.SetDirModTime(ctx, f, dst, dir, src.ModTime(ctx))
 			}
 		} else if dst == nil {
-			// Create the directory if it doesn't exist
-			err = operations.Mkdir(ctx, f, dir)
+			// Create the directory and any missing parents if it doesn't exist
+			err = operations.MkdirAll(ctx, f, dir)
 		}
 	} else {
 		newDst = dst
@@ -1226,8 +1696,13 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 	}
 	switch x := src.(type) {
 	case fs.Object:
+		x, ok := s.resolvePointer(s.ctx, x)
+		if !ok {
+			return false
+		}
 		s.logger(s.ctx, operations.MissingOnDst, x, nil, nil)
 		s.markParentNotEmpty(src)
+		s.markInManifest(x)
 
 		if s.trackRenames {
 			// Save object to check for a rename later
@@ -1238,9 +1713,10 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 			}
 		} else {
 			// Check CompareDest && CopyDest
+			s.checkpoint.add(x)
 			NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, nil, x, s.compareCopyDest, s.backupDir)
 			if err != nil {
-				s.processError(err)
+				s.processFileError(err)
 				s.logger(s.ctx, operations.TransferError, x, nil, err)
 			}
 			if !NoNeedTransfer {
@@ -1251,6 +1727,12 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 				if !ok {
 					return
 				}
+			} else {
+				// Only a --compare-dest match is a skip, --copy-dest has copied the file
+				if err == nil && len(s.ci.CompareDest) > 0 {
+					s.skips.add(operations.SkipCompareDest)
+				}
+				s.checkpoint.finish(x, err == nil)
 			}
 		}
 	case fs.Directory:
@@ -1273,12 +1755,18 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 	switch srcX := src.(type) {
 	case fs.Object:
 		s.markParentNotEmpty(src)
+		s.markInManifest(srcX)
 
 		if s.deleteMode == fs.DeleteModeOnly {
 			return false
 		}
+		srcX, ok := s.resolvePointer(ctx, srcX)
+		if !ok {
+			return false
+		}
 		dstX, ok := dst.(fs.Object)
 		if ok {
+			s.checkpoint.add(srcX)
 			// No logger here because we'll handle it in equal()
 			ok = s.toBeChecked.Put(s.inCtx, fs.ObjectPair{Src: srcX, Dst: dstX})
 			if !ok {
@@ -1289,6 +1777,8 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 			err := errors.New("can't overwrite directory with file")
 			fs.Errorf(dst, "%v", err)
 			s.processError(err)
+			s.checkpoint.add(srcX)
+			s.checkpoint.finish(srcX, false)
 			s.logger(ctx, operations.TransferError, srcX, dstX, err)
 		}
 	case fs.Directory:
@@ -1325,6 +1815,69 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 	return false
 }
 
+// Stats is passed to the CompleteFn when a sync, copy or move finishes
+type Stats struct {
+	Transfers int64                           // number of files transferred
+	Deletes   int64                           // number of files deleted
+	Skipped   map[operations.SkipReason]int64 // number of files not transferred by reason
+	Errors    []error                         // the distinct errors seen, up to a maximum of maxErrors
+	Err       error                           // the error it finished with or nil
+}
+
+// CompleteFn is called once when a sync, copy or move finishes,
+// whether it succeeded or not.
+//
+// If it returns an error then that is returned instead of Stats.Err.
+type (
+	CompleteFn           func(ctx context.Context, stats Stats) error
+	completeFnContextKey struct{}
+)
+
+var completeFnKey = completeFnContextKey{}
+
+// WithCompleteFn stores completeFn in ctx and returns a copy of ctx in which completeFnKey = completeFn
+//
+// The counts in the Stats are read from the stats group in ctx.
+func WithCompleteFn(ctx context.Context, completeFn CompleteFn) context.Context {
+	return context.WithValue(ctx, completeFnKey, completeFn)
+}
+
+// completeFunc returns a function which calls the CompleteFn in ctx,
+// if any, and returns the error to finish with. The transfers and
+// deletes are counted from when completeFunc is called, the skipped
+// files and errors are read from s if it isn't nil.
+func completeFunc(ctx context.Context) func(s *syncCopyMove, err error) error {
+	completeFn, ok := ctx.Value(completeFnKey).(CompleteFn)
+	if !ok || completeFn == nil {
+		return func(_ *syncCopyMove, err error) error {
+			return err
+		}
+	}
+	stats := accounting.Stats(ctx)
+	transfers, deletes := stats.GetTransfers(), stats.GetDeletes()
+	return func(s *syncCopyMove, err error) error {
+		var (
+			skipped map[operations.SkipReason]int64
+			errs    []error
+		)
+		if s != nil {
+			skipped = s.skips.snapshot()
+			errs = s.Errors()
+		}
+		completeErr := completeFn(ctx, Stats{
+			Transfers: stats.GetTransfers() - transfers,
+			Deletes:   stats.GetDeletes() - deletes,
+			Skipped:   skipped,
+			Errors:    errs,
+			Err:       err,
+		})
+		if completeErr != nil {
+			return completeErr
+		}
+		return err
+	}
+}
+
 // Syncs fsrc into fdst
 //
 // If Delete is true then it deletes any files in fdst that aren't in fsrc
@@ -1332,8 +1885,13 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 // If DoMove is true then files will be moved instead of copied.
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (err error) {
 	ci := fs.GetConfig(ctx)
+	var s *syncCopyMove
+	complete := completeFunc(ctx)
+	defer func() {
+		err = complete(s, err)
+	}()
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
@@ -1354,11 +1912,11 @@ func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		// Next pass does a copy only
 		deleteMode = fs.DeleteModeOff
 	}
-	do, err := newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs)
+	s, err = newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs)
 	if err != nil {
 		return err
 	}
-	return do.run()
+	return s.run()
 }
 
 // Sync fsrc into fdst
@@ -1377,18 +1935,30 @@ func moveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 	return runSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, true, deleteEmptySrcDirs, copyEmptySrcDirs)
 }
 
+// needsFileMoves returns true if ci has options which only work when
+// the files are moved one by one so can't use a server-side DirMove.
+func needsFileMoves(ci *fs.ConfigInfo) bool {
+	return ci.SyncManifest != "" ||
+		ci.PointerFiles != "" ||
+		ci.Checkpoint != "" ||
+		ci.PreserveHardlinks ||
+		ci.MaxTransferCount > 0
+}
+
 // MoveDir moves fsrc into fdst
 func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
 	fi := filter.GetConfig(ctx)
+	complete := completeFunc(ctx)
 	if operations.Same(fdst, fsrc) {
 		fs.Errorf(fdst, "Nothing to do as source and destination are the same")
-		return nil
+		return complete(nil, nil)
 	}
 
-	// First attempt to use DirMover if exists, same Fs and no filters are active
-	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && fi.InActive() {
+	// First attempt to use DirMover if exists, same Fs and no filters
+	// or options which need the files moving one by one are active
+	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && fi.InActive() && !needsFileMoves(fs.GetConfig(ctx)) {
 		if operations.SkipDestructive(ctx, fdst, "server-side directory move") {
-			return nil
+			return complete(nil, nil)
 		}
 		fs.Debugf(fdst, "Using server-side directory move")
 		err := fdstDirMove(ctx, fsrc, "", "")
@@ -1397,11 +1967,11 @@ func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 			fs.Infof(fdst, "Server side directory move failed - fallback to file moves: %v", err)
 		case nil:
 			fs.Infof(fdst, "Server side directory move succeeded")
-			return nil
+			return complete(nil, nil)
 		default:
 			err = fs.CountError(ctx, err)
 			fs.Errorf(fdst, "Server side directory move failed: %v", err)
-			return err
+			return complete(nil, err)
 		}
 	}
 
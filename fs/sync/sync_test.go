@@ -8,11 +8,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"os/exec"
+	"path"
 	"runtime"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,6 +30,7 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/unicode/norm"
@@ -1414,6 +1418,420 @@ func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	r.CheckLocalItems(t, file2)
 }
 
+// Test with --delete-protect and --delete-during
+func TestSyncWithDeleteProtect(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	ci.DeleteMode = fs.DeleteModeDuring
+	r := fstest.NewRun(t)
+	file1 := r.WriteBoth(ctx, "potato2", "------------------------------------------------------------", t1)
+	file2 := r.WriteObject(ctx, ".htaccess", "deny from all", t2)
+	file3 := r.WriteObject(ctx, "dir/.htaccess", "deny from all", t2)
+	file4 := r.WriteObject(ctx, "empty space", "-", t2)
+	r.CheckRemoteItems(t, file1, file2, file3, file4)
+	r.CheckLocalItems(t, file1)
+
+	opt := filter.Opt
+	opt.DeleteProtect = []string{"/.htaccess"}
+	fi, err := filter.NewFilter(&opt)
+	require.NoError(t, err)
+	ctx = filter.ReplaceConfig(ctx, fi)
+
+	accounting.GlobalStats().ResetCounters()
+	ctx = predictDstFromLogger(ctx)
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	testLoggerVsLsf(ctx, r.Fremote, operations.GetLoggerOpt(ctx).JSON, t)
+
+	// only the protected file which is missing on the source survives
+	r.CheckRemoteItems(t, file1, file2)
+	r.CheckLocalItems(t, file1)
+}
+
+// aboutFs wraps an Fs adding an About which reports free bytes free
+type aboutFs struct {
+	fs.Fs
+	features *fs.Features
+	free     int64
+}
+
+func newAboutFs(f fs.Fs, free int64) *aboutFs {
+	a := &aboutFs{Fs: f, free: free}
+	ft := *f.Features()
+	ft.About = a.About
+	a.features = &ft
+	return a
+}
+
+func (a *aboutFs) Features() *fs.Features {
+	return a.features
+}
+
+func (a *aboutFs) About(ctx context.Context) (*fs.Usage, error) {
+	return &fs.Usage{Free: &a.free}, nil
+}
+
+// Test with --check-free-space
+func TestSyncCheckFreeSpace(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	ci.CheckFreeSpace = true
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1) // 11 bytes
+	file2 := r.WriteFile("potato", "abcdef", t2)                   // 6 bytes
+	r.CheckLocalItems(t, file1, file2)
+
+	// Not enough free space so nothing should be transferred
+	accounting.GlobalStats().ResetCounters()
+	err := Sync(ctx, newAboutFs(r.Fremote, 16), r.Flocal, false)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "not enough free space")
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t)
+
+	// Exactly enough free space
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, newAboutFs(r.Fremote, 17), r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1, file2)
+}
+
+// Test with --sync-manifest
+func TestSyncManifest(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("present1", "present1", t1)
+	file2 := r.WriteFile("sub/present2", "present2", t2)
+	file3 := r.WriteFile("extra", "not in the manifest", t2)
+	r.CheckLocalItems(t, file1, file2, file3)
+	file4 := r.WriteObject(ctx, "remoteonly", "not in the source", t3)
+	r.CheckRemoteItems(t, file4)
+
+	writeManifest := func(lines ...string) string {
+		manifest := t.TempDir() + "/manifest.txt"
+		require.NoError(t, os.WriteFile(manifest, []byte(strings.Join(lines, "\n")+"\n"), 0666))
+		return manifest
+	}
+
+	// Missing files are reported in manifest order but the present
+	// files are still transferred
+	ci.SyncManifest = writeManifest("zmissing", "present1", "# comment", "sub/present2", "amissing")
+	accounting.GlobalStats().ResetCounters()
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	log.SetOutput(os.Stderr)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsNoRetryError(err))
+	assert.Contains(t, err.Error(), "2 files listed in --sync-manifest not found in source")
+	logs := logBuf.String()
+	zmissing := strings.Index(logs, "zmissing: Listed in --sync-manifest but not found in source")
+	amissing := strings.Index(logs, "amissing: Listed in --sync-manifest but not found in source")
+	require.True(t, zmissing >= 0 && amissing >= 0, logs)
+	assert.Less(t, zmissing, amissing)
+	assert.Equal(t, int64(2), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, file1, file2, file4)
+
+	// All the files present so no error and the extra files on
+	// either side are left alone
+	ci.SyncManifest = writeManifest("present1", "sub/present2")
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, file1, file2, file4)
+	r.CheckLocalItems(t, file1, file2, file3)
+
+	// Can't be combined with --files-from
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	require.NoError(t, fi.AddFile("present1"))
+	err = Sync(filter.ReplaceConfig(ctx, fi), r.Fremote, r.Flocal, false)
+	assert.ErrorContains(t, err, "can't use --sync-manifest with --files-from")
+}
+
+// listCountFs counts the directory listings of the wrapped Fs
+type listCountFs struct {
+	fs.Fs
+	mu    mutex.Mutex
+	lists int
+}
+
+func (f *listCountFs) List(ctx context.Context, dir string) (fs.DirEntries, error) {
+	f.mu.Lock()
+	f.lists++
+	f.mu.Unlock()
+	return f.Fs.List(ctx, dir)
+}
+
+// Test with --dst-listing the destination is read from the listing
+func TestCopyDstListing(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("file1", "new file", t1)
+	file2 := r.WriteFile("sub/file2", "already there", t2)
+	r.CheckLocalItems(t, file1, file2)
+	obj2 := r.WriteObject(ctx, "sub/file2", "already there", t2)
+	r.CheckRemoteItems(t, obj2)
+
+	listing := t.TempDir() + "/listing.json"
+	require.NoError(t, os.WriteFile(listing, []byte(fmt.Sprintf(`[
+{"Path":"sub","Name":"sub","Size":-1,"ModTime":%q,"IsDir":true},
+{"Path":"sub/file2","Name":"file2","Size":%d,"ModTime":%q,"IsDir":false}
+]`, t2.Format(time.RFC3339Nano), len("already there"), t2.Format(time.RFC3339Nano))), 0666))
+	ci.DstListing = listing
+
+	fdst := &listCountFs{Fs: r.Fremote}
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, fdst, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), accounting.GlobalStats().GetTransfers())
+	assert.Equal(t, 0, fdst.lists, "destination was listed")
+	r.CheckRemoteItems(t, file1, obj2)
+
+	// Directories listed after their contents aren't duplicated
+	require.NoError(t, os.WriteFile(listing, []byte(fmt.Sprintf(`[
+{"Path":"sub/file2","Name":"file2","Size":%d,"ModTime":%q,"IsDir":false},
+{"Path":"sub/dir/file3","Name":"file3","Size":1,"ModTime":%q,"IsDir":false},
+{"Path":"sub/dir","Name":"dir","Size":-1,"ModTime":%q,"IsDir":true},
+{"Path":"sub","Name":"sub","Size":-1,"ModTime":%q,"IsDir":true}
+]`, len("already there"), t2.Format(time.RFC3339Nano), t2.Format(time.RFC3339Nano), t2.Format(time.RFC3339Nano), t2.Format(time.RFC3339Nano))), 0666))
+	dt, err := readDstListing(ctx, r.Fremote, filter.GetConfig(ctx), false, listing)
+	require.NoError(t, err)
+	require.Len(t, dt[""], 1)
+	assert.Equal(t, "sub", dt[""][0].Remote())
+	assert.True(t, dt[""][0].ModTime(ctx).Equal(t2))
+	assert.Len(t, dt["sub"], 2)
+
+	// A bad listing is an error
+	require.NoError(t, os.WriteFile(listing, []byte("not json"), 0666))
+	err = CopyDir(ctx, fdst, r.Flocal, false)
+	assert.ErrorContains(t, err, "failed to parse --dst-listing")
+}
+
+// Test updating a file in place which is in the --dst-listing checks
+// the new object, not the one in the listing
+func TestCopyDstListingInplace(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("file1", "changed and longer", t2)
+	r.CheckLocalItems(t, file1)
+	obj1 := r.WriteObject(ctx, "file1", "original", t1)
+	r.CheckRemoteItems(t, obj1)
+
+	listing := t.TempDir() + "/listing.json"
+	require.NoError(t, os.WriteFile(listing, []byte(fmt.Sprintf(`[
+{"Path":"file1","Name":"file1","Size":%d,"ModTime":%q,"IsDir":false}
+]`, len("original"), t1.Format(time.RFC3339Nano))), 0666))
+	ci.DstListing = listing
+	ci.Inplace = true
+
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), accounting.GlobalStats().GetTransfers())
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+	r.CheckRemoteItems(t, file1)
+}
+
+// Test with --checkpoint resuming an interrupted copy
+func TestCopyCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	if r.Fremote.Features().BucketBased {
+		t.Skip("Skipping test as remote can have a file and directory with the same name")
+	}
+	fileA := r.WriteFile("a/one", "one", t1)
+	fileB := r.WriteFile("b/two", "two", t1)
+	fileClash := r.WriteFile("clash", "clash", t1)
+	// A directory in the way of a file makes the first copy fail
+	// part way through
+	objClash := r.WriteObject(ctx, "clash/file", "in the way", t1)
+	ci.Checkpoint = t.TempDir() + "/checkpoint.txt"
+
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.Error(t, err)
+	assert.Equal(t, int64(2), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, fileA, fileB, objClash)
+
+	// The finished directories are recorded but not the root
+	checkpoint, err := os.ReadFile(ci.Checkpoint)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(checkpoint)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, checkpointHeader(r.Fremote, r.Flocal), lines[0])
+	var dirs []string
+	for _, line := range lines[1:] {
+		_, dir, _ := strings.Cut(line, " ")
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	assert.Equal(t, []string{"a", "b"}, dirs)
+
+	// Clear the clash, remove a/one from the destination so we can
+	// tell it was skipped and change b/two in the source so its
+	// checkpoint is invalidated
+	for _, remote := range []string{"clash/file", "a/one"} {
+		o, err := r.Fremote.NewObject(ctx, remote)
+		require.NoError(t, err)
+		require.NoError(t, o.Remove(ctx))
+	}
+	require.NoError(t, operations.Rmdir(ctx, r.Fremote, "clash"))
+	fileB = r.WriteFile("b/two", "two changed", t2)
+
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, fileB, fileClash)
+
+	// The checkpoint is removed once the copy completes
+	_, err = os.Stat(ci.Checkpoint)
+	assert.True(t, os.IsNotExist(err))
+
+	// So the next copy checks everything
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, fileA, fileB, fileClash)
+}
+
+// Test with --pointer-files copying the files named in pointers
+func TestCopyPointerFiles(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	fileReal := r.WriteFile("data/real.txt", "real content", t1)
+	filePointer := r.WriteFile("link.ptr", "data/real.txt\n", t2)
+	r.CheckLocalItems(t, fileReal, filePointer)
+	ci.PointerFiles = "*.ptr"
+
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), accounting.GlobalStats().GetTransfers())
+
+	// The pointer is replaced by the file it names
+	fileResolved := fstest.NewItem("link.ptr", "real content", t1)
+	r.CheckRemoteItems(t, fileReal, fileResolved)
+
+	// Nothing needs copying the second time
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetTransfers())
+
+	// A pointer to a missing file is an error
+	r.WriteFile("missing.ptr", "data/missing.txt", t2)
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.Error(t, err)
+	r.CheckRemoteItems(t, fileReal, fileResolved)
+
+	// Moving would move the files pointed to so isn't allowed
+	_, err = newSyncCopyMove(ctx, r.Fremote, r.Flocal, fs.DeleteModeOff, true, false, false)
+	assert.ErrorContains(t, err, "--pointer-files")
+}
+
+// Test with --pointer-files can't read files outside the source
+func TestCopyPointerFilesTraversal(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	fileSecret := r.WriteFile("secret.txt", "secret content", t1)
+	filePointer := r.WriteFile("src/evil.ptr", "../secret.txt", t2)
+	r.CheckLocalItems(t, fileSecret, filePointer)
+	ci.PointerFiles = "*.ptr"
+
+	fsrc, err := fs.NewFs(ctx, r.Flocal.Root()+"/src")
+	require.NoError(t, err)
+	err = CopyDir(ctx, r.Fremote, fsrc, false)
+	assert.ErrorContains(t, err, "invalid pointer file")
+	r.CheckRemoteItems(t)
+}
+
+// Test with --preserve-hardlinks uploading hard linked files once
+func TestCopyPreserveHardlinks(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("one.txt", "hard linked content", t1)
+	require.NoError(t, os.Link(path.Join(r.LocalName, "one.txt"), path.Join(r.LocalName, "two.txt")))
+	file2 := fstest.NewItem("two.txt", "hard linked content", t1)
+	r.CheckLocalItems(t, file1, file2)
+	o, err := r.Flocal.NewObject(ctx, "one.txt")
+	require.NoError(t, err)
+	if _, ok := hardlinkKeyOf(o); !ok {
+		t.Skip("Can't read hard links on this OS")
+	}
+	ci.PreserveHardlinks = true
+	size := int64(len("hard linked content"))
+
+	// Copy to a destination which can server-side copy
+	fdst, err := fs.NewFs(ctx, ":memory:preserve-hardlinks")
+	require.NoError(t, err)
+	require.NotNil(t, fdst.Features().Copy)
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, fdst, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), accounting.GlobalStats().GetTransfers())
+	stats, err := accounting.GlobalStats().RemoteStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats["serverSideCopies"])
+	assert.Equal(t, size, accounting.GlobalStats().GetBytes()-stats["serverSideCopyBytes"].(int64), "content uploaded once")
+	fstest.CheckListingWithPrecision(t, fdst, []fstest.Item{file1, file2}, nil, fs.GetModifyWindow(ctx, fdst))
+
+	// Copy to the remote recording the link in the sidecar if it
+	// can't server-side copy
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	if r.Fremote.Features().Copy != nil {
+		assert.Equal(t, size, accounting.GlobalStats().GetBytes())
+		r.CheckRemoteItems(t, file1, file2)
+	} else {
+		sidecarContent := "two.txt\tone.txt\n"
+		assert.Equal(t, size+int64(len(sidecarContent)), accounting.GlobalStats().GetBytes())
+		sidecar := fstest.NewItem(hardlinkSidecar, sidecarContent, t1)
+		fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, sidecar}, nil, fs.ModTimeNotSupported)
+	}
+
+	// Moving isn't allowed
+	_, err = newSyncCopyMove(ctx, r.Fremote, r.Flocal, fs.DeleteModeOff, true, false, false)
+	assert.ErrorContains(t, err, "--preserve-hardlinks")
+}
+
+// Test with --preserve-hardlinks the sidecar isn't deleted by later syncs
+func TestSyncPreserveHardlinksSidecar(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	if r.Fremote.Features().Copy != nil {
+		t.Skip("Hard links are copied server-side on this remote")
+	}
+	file1 := r.WriteFile("one.txt", "hard linked content", t1)
+	require.NoError(t, os.Link(path.Join(r.LocalName, "one.txt"), path.Join(r.LocalName, "two.txt")))
+	o, err := r.Flocal.NewObject(ctx, "one.txt")
+	require.NoError(t, err)
+	if _, ok := hardlinkKeyOf(o); !ok {
+		t.Skip("Can't read hard links on this OS")
+	}
+	ci.PreserveHardlinks = true
+
+	sidecar := fstest.NewItem(hardlinkSidecar, "two.txt\tone.txt\n", t1)
+	for i := 0; i < 2; i++ {
+		err = Sync(ctx, r.Fremote, r.Flocal, false)
+		require.NoError(t, err)
+		fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, sidecar}, nil, fs.ModTimeNotSupported)
+	}
+}
+
 // Test with UpdateOlder set
 func TestSyncWithUpdateOlder(t *testing.T) {
 	ctx := context.Background()
@@ -1458,6 +1876,97 @@ func TestSyncWithUpdateOlder(t *testing.T) {
 	r.CheckRemoteItems(t, oneO, twoF, threeF, fourF, fiveF)
 }
 
+// Test --order-by modtime transfers the files in modification time order
+func TestCopyOrderByModTime(t *testing.T) {
+	for _, test := range []struct {
+		orderBy string
+		want    []string
+	}{
+		{orderBy: "modtime", want: []string{"b", "c", "a"}},
+		{orderBy: "modtime,ascending", want: []string{"b", "c", "a"}},
+		{orderBy: "modtime,descending", want: []string{"a", "c", "b"}},
+	} {
+		t.Run(test.orderBy, func(t *testing.T) {
+			ctx := context.Background()
+			ctx, ci := fs.AddConfig(ctx)
+			r := fstest.NewRun(t)
+			t3 := t2.Add(time.Hour)
+			fileA := r.WriteFile("a", "a", t3)
+			fileB := r.WriteFile("b", "b", t1)
+			fileC := r.WriteFile("c", "c", t2)
+			r.CheckLocalItems(t, fileA, fileB, fileC)
+
+			// Find all the transfers first and do them one at a
+			// time so they are done in order
+			ci.OrderBy = test.orderBy
+			ci.CheckFirst = true
+			ci.Transfers = 1
+
+			accounting.GlobalStats().ResetCounters()
+			err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+			require.NoError(t, err)
+			r.CheckRemoteItems(t, fileA, fileB, fileC)
+
+			var got []string
+			for _, tr := range accounting.GlobalStats().Transferred() {
+				if !tr.Checked {
+					got = append(got, tr.Name)
+				}
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+// inUseFs wraps an Fs so the listing of one file reports a stale size
+// as if the file was written to after it was listed.
+type inUseFs struct {
+	fs.Fs
+	changed string
+}
+
+func (f *inUseFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	for i, entry := range entries {
+		if o, ok := entry.(fs.Object); ok && o.Remote() == f.changed {
+			entries[i] = inUseObject{o}
+		}
+	}
+	return entries, err
+}
+
+type inUseObject struct {
+	fs.Object
+}
+
+func (o inUseObject) Size() int64 {
+	return o.Object.Size() - 1
+}
+
+// Test --skip-in-use skips local files which changed since listing
+func TestCopySkipInUse(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("stable.txt", "not changing", t1)
+	file2 := r.WriteFile("growing.txt", "being written", t1)
+	r.CheckLocalItems(t, file1, file2)
+	fsrc := &inUseFs{Fs: r.Flocal, changed: "growing.txt"}
+
+	ci.SkipInUse = true
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, fsrc, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), accounting.GlobalStats().GetTransfers())
+	r.CheckRemoteItems(t, file1)
+
+	// Once the file is stable it is transferred
+	fsrc.changed = ""
+	err = CopyDir(ctx, r.Fremote, fsrc, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1, file2)
+}
+
 // Test with a max transfer duration
 func testSyncWithMaxDuration(t *testing.T, cutoffMode fs.CutoffMode) {
 	ctx := context.Background()
@@ -1685,6 +2194,59 @@ func toyFileTransfers(r *fstest.Run) int64 {
 	return int64(transfers)
 }
 
+func TestMoveWithNoOverwrite(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteBoth(ctx, "identical", "potato", t1)
+	file2 := r.WriteObject(ctx, "changed", "tomato", t1)
+	file2b := r.WriteFile("changed", "newtomatoes", t2)
+	file3 := r.WriteFile("missing", "carrot", t1)
+
+	ci.NoOverwrite = true
+
+	accounting.GlobalStats().ResetCounters()
+	ctx = predictDstFromLogger(ctx)
+	err := MoveDir(ctx, r.Fremote, r.Flocal, false, false)
+	require.NoError(t, err)
+	testLoggerVsLsf(ctx, r.Fremote, operations.GetLoggerOpt(ctx).JSON, t)
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+
+	// Only the missing file is moved - the sources of the skipped
+	// files are left in place
+	r.CheckLocalItems(t, file1, file2b)
+	// Existing files on the destination are left untouched
+	r.CheckRemoteItems(t, file1, file2, file3)
+}
+
+// Test --skip-empty-overwrite doesn't replace good data with empty files
+func TestSyncSkipEmptyOverwrite(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteObject(ctx, "truncated", "good data", t1)
+	file1b := r.WriteFile("truncated", "", t2)
+	r.WriteObject(ctx, "empty", "", t1)
+	file2b := r.WriteFile("empty", "", t2)
+	file3 := r.WriteFile("missing", "", t2)
+
+	ci.SkipEmptyOverwrite = true
+
+	accounting.GlobalStats().ResetCounters()
+	ctx = predictDstFromLogger(ctx)
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	testLoggerVsLsf(ctx, r.Fremote, operations.GetLoggerOpt(ctx).JSON, t)
+	assert.Equal(t, int64(0), accounting.GlobalStats().GetErrors())
+	r.CheckRemoteItems(t, file1, file2b, file3)
+
+	// When moving the empty source is left in place
+	err = MoveDir(ctx, r.Fremote, r.Flocal, false, false)
+	require.NoError(t, err)
+	r.CheckLocalItems(t, file1b)
+	r.CheckRemoteItems(t, file1, file2b, file3)
+}
+
 // Test a server-side move if possible, or the backup path if not
 func testServerSideMove(ctx context.Context, t *testing.T, r *fstest.Run, withFilter, testDeleteEmptyDirs bool) {
 	FremoteMove, _, finaliseMove, err := fstest.RandomRemote()
@@ -1774,6 +2336,31 @@ func TestServerSideMoveLocal(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// Test MoveDir on Local with --sync-manifest doesn't use DirMove
+func TestServerSideMoveLocalSyncManifest(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	f1 := r.WriteFile("dir1/file1.txt", "hello", t1)
+	f2 := r.WriteFile("dir1/file2.txt", "hello again", t2)
+	r.CheckLocalItems(t, f1, f2)
+
+	manifest := t.TempDir() + "/manifest.txt"
+	require.NoError(t, os.WriteFile(manifest, []byte("file1.txt\n"), 0666))
+	ci.SyncManifest = manifest
+
+	dir1, err := fs.NewFs(ctx, r.Flocal.Root()+"/dir1")
+	require.NoError(t, err)
+	dir2, err := fs.NewFs(ctx, r.Flocal.Root()+"/dir2")
+	require.NoError(t, err)
+	require.NotNil(t, dir2.Features().DirMove)
+	err = MoveDir(ctx, dir2, dir1, false, false)
+	require.NoError(t, err)
+
+	f1.Path = "dir2/file1.txt"
+	r.CheckLocalItems(t, f1, f2)
+}
+
 // Test move
 func TestMoveWithDeleteEmptySrcDirs(t *testing.T) {
 	ctx := context.Background()
@@ -2707,6 +3294,229 @@ func TestMaxTransfer(t *testing.T) {
 	t.Run("Cautious", func(t *testing.T) { test(t, fs.CutoffModeCautious) })
 }
 
+// Test that --max-transfer-count transfers exactly that many files
+// and the rest are transferred by the next run
+func TestMaxTransferCount(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	ci.MaxTransferCount = 3
+	ci.Transfers = 2
+	r := fstest.NewRun(t)
+
+	var items []fstest.Item
+	for i := 0; i < 5; i++ {
+		items = append(items, r.WriteFile(fmt.Sprintf("file%d", i), fmt.Sprintf("content %d", i), t1))
+	}
+	r.CheckLocalItems(t, items...)
+	r.CheckRemoteItems(t)
+
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	assert.Equal(t, ErrorMaxTransferCountReachedGraceful, err)
+	assert.True(t, errors.Is(err, accounting.ErrorMaxTransferLimitReached))
+	fserrors.Count(err)
+	objects, _, _, err := operations.Count(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), objects)
+
+	// The next run picks up the rest
+	accounting.GlobalStats().ResetCounters()
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, items...)
+}
+
+// Test that all the distinct errors are kept and the most serious
+// is returned
+func TestSyncErrors(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	s, err := newSyncCopyMove(ctx, r.Fremote, r.Flocal, fs.DeleteModeOff, false, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, s.Errors())
+
+	err1 := errors.New("file1: permission denied")
+	err2 := fserrors.NoRetryError(errors.New("file2: too big"))
+	err3 := errors.New("file3: checksum mismatch")
+	var wg mutex.WaitGroup
+	for _, err := range []error{err1, err2, err1, err3, errors.New("file1: permission denied")} {
+		wg.Add(1)
+		go func(err error) {
+			defer wg.Done()
+			s.processFileError(err)
+		}(err)
+	}
+	wg.Wait()
+	s.processFileError(nil)
+	assert.ElementsMatch(t, []error{err1, err2, err3}, s.Errors())
+	assert.Contains(t, []error{err1, err3}, s.currentError())
+
+	// Check the list is capped
+	for i := 0; i < 2*maxErrors; i++ {
+		s.processError(fmt.Errorf("error %d", i))
+	}
+	assert.Len(t, s.Errors(), maxErrors)
+}
+
+// Test the CompleteFn is called once with the counts and error
+func TestCompleteFn(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	file2 := r.WriteFile("file2", "file2 contents", t1)
+	file3 := r.WriteObject(ctx, "file3", "file3 contents", t1)
+	r.CheckLocalItems(t, file1, file2)
+	r.CheckRemoteItems(t, file3)
+
+	var calls []Stats
+	completeErr := errors.New("cache warm failed")
+	ctx = WithCompleteFn(ctx, func(ctx context.Context, stats Stats) error {
+		calls = append(calls, stats)
+		return completeErr
+	})
+	accounting.GlobalStats().ResetCounters()
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	assert.Equal(t, completeErr, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, Stats{Transfers: 2, Deletes: 1}, calls[0])
+	r.CheckRemoteItems(t, file1, file2)
+
+	// The error is passed in and kept if the CompleteFn returns nil
+	calls = nil
+	ctx = WithCompleteFn(ctx, func(ctx context.Context, stats Stats) error {
+		calls = append(calls, stats)
+		return nil
+	})
+	err = Sync(ctx, r.Fremote, r.Fremote, false)
+	assert.Error(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, err, calls[0].Err)
+
+	// Called when there is nothing to do as source and destination are the same
+	calls = nil
+	require.NoError(t, CopyDir(ctx, r.Flocal, r.Flocal, false))
+	require.NoError(t, MoveDir(ctx, r.Flocal, r.Flocal, false, false))
+	assert.Equal(t, []Stats{{}, {}}, calls)
+
+	// The distinct errors are passed in
+	calls = nil
+	fdst, err := fs.NewFs(ctx, ":memory:complete-fn")
+	require.NoError(t, err)
+	err = CopyDir(ctx, &putErrorFs{Fs: fdst}, r.Flocal, false)
+	assert.Error(t, err)
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0].Errors, 2)
+	assert.ElementsMatch(t, []string{`failed to put "file1"`, `failed to put "file2"`},
+		[]string{calls[0].Errors[0].Error(), calls[0].Errors[1].Error()})
+}
+
+// putErrorFs wraps an Fs so every upload fails with a different error
+type putErrorFs struct {
+	fs.Fs
+}
+
+func (f *putErrorFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return nil, fmt.Errorf("failed to put %q", src.Remote())
+}
+
+// Test the CompleteFn is called after a server-side directory move
+func TestCompleteFnDirMove(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	r.WriteFile("dir1/file1.txt", "hello", t1)
+
+	dir1, err := fs.NewFs(ctx, r.Flocal.Root()+"/dir1")
+	require.NoError(t, err)
+	dir2, err := fs.NewFs(ctx, r.Flocal.Root()+"/dir2")
+	require.NoError(t, err)
+	require.NotNil(t, dir2.Features().DirMove)
+
+	var calls []Stats
+	ctx = WithCompleteFn(ctx, func(ctx context.Context, stats Stats) error {
+		calls = append(calls, stats)
+		return nil
+	})
+	require.NoError(t, MoveDir(ctx, dir2, dir1, false, false))
+	assert.Equal(t, []Stats{{}}, calls)
+}
+
+// Test the skipped files are counted by reason
+func TestSyncSkipSummary(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	same := r.WriteBoth(ctx, "same", "same", t1)
+	newFile := r.WriteFile("new", "new", t1)
+	changed := r.WriteFile("changed", "changed", t2)
+	r.WriteObject(ctx, "changed", "old", t1)
+	r.WriteFile("older", "older", t1)
+	newer := r.WriteObject(ctx, "older", "newer on dst", t2)
+	r.WriteFile("empty", "", t2)
+	full := r.WriteObject(ctx, "empty", "good data", t1)
+
+	ci.UpdateOlder = true
+	ci.SkipEmptyOverwrite = true
+	var stats Stats
+	ctx = WithCompleteFn(ctx, func(ctx context.Context, s Stats) error {
+		stats = s
+		return nil
+	})
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, same, newFile, changed, newer, full)
+
+	assert.Equal(t, int64(2), stats.Transfers)
+	assert.Equal(t, map[operations.SkipReason]int64{
+		operations.SkipUnchanged:      1,
+		operations.SkipDstNewer:       1,
+		operations.SkipEmptyOverwrite: 1,
+	}, stats.Skipped)
+
+	c := newSkipCounter()
+	assert.Equal(t, "", c.String())
+	for _, reason := range []operations.SkipReason{operations.SkipUnchanged, skipInUse, operations.SkipUnchanged, operations.SkipDstNewer} {
+		c.add(reason)
+	}
+	assert.Equal(t, "Skipped 4 files: 2 unchanged, 1 destination newer, 1 in use", c.String())
+}
+
+// Test files copied with --copy-dest aren't counted as skipped but
+// files matched with --compare-dest are
+func TestSyncSkipSummaryCompareCopyDest(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	r.WriteFile("one", "one", t1)
+	// Use the memory backend as it can server-side copy
+	const root = ":memory:skip-summary/"
+	fextra, err := fs.NewFs(ctx, root+"extra")
+	require.NoError(t, err)
+	_, err = operations.Rcat(ctx, fextra, "one", io.NopCloser(strings.NewReader("one")), t1, nil)
+	require.NoError(t, err)
+
+	var stats Stats
+	ctx = WithCompleteFn(ctx, func(ctx context.Context, s Stats) error {
+		stats = s
+		return nil
+	})
+
+	ci.CopyDest = []string{root + "extra"}
+	fdst, err := fs.NewFs(ctx, root+"copy")
+	require.NoError(t, err)
+	require.NoError(t, CopyDir(ctx, fdst, r.Flocal, false))
+	assert.Equal(t, int64(1), stats.Transfers)
+	assert.Empty(t, stats.Skipped)
+
+	ci.CopyDest = nil
+	ci.CompareDest = []string{root + "extra"}
+	fdst, err = fs.NewFs(ctx, root+"compare")
+	require.NoError(t, err)
+	require.NoError(t, CopyDir(ctx, fdst, r.Flocal, false))
+	assert.Equal(t, int64(0), stats.Transfers)
+	assert.Equal(t, map[operations.SkipReason]int64{operations.SkipCompareDest: 1}, stats.Skipped)
+}
+
 func testSyncConcurrent(t *testing.T, subtest string) {
 	const (
 		NFILES     = 20
@@ -2875,6 +3685,97 @@ func TestNothingToTransferWithoutEmptyDirs(t *testing.T) {
 	testNothingToTransfer(t, false)
 }
 
+// Test --suppress-fatal-abort only demotes fatal errors on single files
+func TestSuppressFatalAbort(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	fatalErr := fserrors.FatalError(errors.New("backend unavailable"))
+
+	newSync := func() *syncCopyMove {
+		s := &syncCopyMove{ci: ci}
+		s.ctx, s.cancel = context.WithCancel(ctx)
+		s.inCtx, s.inCancel = context.WithCancel(s.ctx)
+		return s
+	}
+
+	t.Run("FileFatalAborts", func(t *testing.T) {
+		ci.SuppressFatalAbort = false
+		s := newSync()
+		s.processFileError(fatalErr)
+		assert.True(t, s.aborting())
+		assert.Equal(t, fatalErr, s.currentError())
+	})
+
+	t.Run("FileFatalContinues", func(t *testing.T) {
+		ci.SuppressFatalAbort = true
+		s := newSync()
+		s.processFileError(fatalErr)
+		assert.False(t, s.aborting())
+		assert.Nil(t, s.fatalErr)
+		assert.Equal(t, fatalErr, s.currentError())
+		s.cancel()
+	})
+
+	t.Run("GlobalFatalAborts", func(t *testing.T) {
+		ci.SuppressFatalAbort = true
+		s := newSync()
+		s.processError(fatalErr)
+		assert.True(t, s.aborting())
+		assert.Equal(t, fatalErr, s.fatalErr)
+	})
+}
+
+// countHashObject is a mock object which counts the calls to Hash
+type countHashObject struct {
+	mockobject.Object
+	calls map[hash.Type]int
+}
+
+// Hash returns a fake hash of the object and counts the call
+func (o *countHashObject) Hash(ctx context.Context, ht hash.Type) (string, error) {
+	o.calls[ht]++
+	return o.String() + "-" + ht.String(), nil
+}
+
+// Test the hash cache only reads each hash once per object
+func TestHashCache(t *testing.T) {
+	ctx := context.Background()
+	c := newHashCache()
+	newObject := func(remote string) *countHashObject {
+		return &countHashObject{Object: mockobject.New(remote), calls: map[hash.Type]int{}}
+	}
+	o1 := newObject("potato")
+	o2 := newObject("sausage")
+	for i := 0; i < 3; i++ {
+		for _, o := range []*countHashObject{o1, o2} {
+			for _, ht := range []hash.Type{hash.MD5, hash.SHA1} {
+				sum, err := c.Hash(ctx, o, ht)
+				require.NoError(t, err)
+				assert.Equal(t, o.String()+"-"+ht.String(), sum)
+			}
+		}
+	}
+	want := map[hash.Type]int{hash.MD5: 1, hash.SHA1: 1}
+	assert.Equal(t, want, o1.calls)
+	assert.Equal(t, want, o2.calls)
+
+	// Forgetting an object reads its hashes again
+	c.forget(o1)
+	_, err := c.Hash(ctx, o1, hash.MD5)
+	require.NoError(t, err)
+	_, err = c.Hash(ctx, o2, hash.MD5)
+	require.NoError(t, err)
+	assert.Equal(t, map[hash.Type]int{hash.MD5: 2, hash.SHA1: 1}, o1.calls)
+	assert.Equal(t, want, o2.calls)
+
+	// A nil cache reads the hashes directly
+	var nilCache *hashCache
+	_, err = nilCache.Hash(ctx, o2, hash.MD5)
+	require.NoError(t, err)
+	nilCache.forget(o2)
+	assert.Equal(t, map[hash.Type]int{hash.MD5: 2, hash.SHA1: 1}, o2.calls)
+}
+
 // for testing logger:
 func predictDstFromLogger(ctx context.Context) context.Context {
 	opt := operations.NewLoggerOpt()
@@ -2997,3 +3898,259 @@ func testLoggerVsLsf(ctx context.Context, Fremote fs.Fs, logger *bytes.Buffer, t
 		require.NoError(t, err)
 	}
 }
+
+// apiCounter counts the backend operations in flight on the Fses
+// wrapped by countingFs
+type apiCounter struct {
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+}
+
+func (c *apiCounter) op() {
+	n := c.inFlight.Add(1)
+	for {
+		old := c.maxInFlight.Load()
+		if n <= old || c.maxInFlight.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	c.inFlight.Add(-1)
+}
+
+// countingFs wraps an Fs counting the listings and uploads on it
+type countingFs struct {
+	fs.Fs
+	c *apiCounter
+}
+
+func (f *countingFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	f.c.op()
+	return f.Fs.List(ctx, dir)
+}
+
+func (f *countingFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	f.c.op()
+	return f.Fs.Put(ctx, in, src, options...)
+}
+
+// Test --max-concurrent-api limits the operations in flight across
+// the walkers, checkers and transfers
+func TestCopyMaxConcurrentAPI(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	var items []fstest.Item
+	for i := 0; i < 20; i++ {
+		items = append(items, r.WriteFile(fmt.Sprintf("dir%d/file%d", i%4, i), "content", t1))
+	}
+	ci.Checkers = 8
+	ci.Transfers = 8
+
+	copyCounting := func(name string) int64 {
+		c := &apiCounter{}
+		fdst, err := fs.NewFs(ctx, ":memory:max-concurrent-api-"+name)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, operations.Purge(ctx, fdst, ""))
+		}()
+		err = CopyDir(ctx, &countingFs{Fs: fdst, c: c}, &countingFs{Fs: r.Flocal, c: c}, false)
+		require.NoError(t, err)
+		fstest.CheckItems(t, fdst, items...)
+		return c.maxInFlight.Load()
+	}
+
+	// Check the test can see more than 2 operations at once
+	assert.Greater(t, copyCounting("unlimited"), int64(2))
+
+	ci.MaxConcurrentAPI = 2
+	assert.LessOrEqual(t, copyCounting("limited"), int64(2))
+}
+
+// Test --trash-dir moves deleted files into the trash and
+// --trash-retention purges old trash
+func TestSyncTrashDir(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteBoth(ctx, "keep", "keep", t1)
+	r.WriteObject(ctx, "sub dir/delete me", "delete me", t1)
+	r.CheckLocalItems(t, file1)
+
+	trashDir := t.TempDir()
+	ci.TrashDir = trashDir
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1)
+
+	// The deleted file is in a timestamped directory in the trash
+	ftrash, err := fs.NewFs(ctx, trashDir)
+	require.NoError(t, err)
+	entries, err := ftrash.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	stamp := entries[0].Remote()
+	when, err := time.Parse(trashTimeFormat, stamp)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), when, time.Minute)
+	fstest.CheckListingWithPrecision(t, ftrash, []fstest.Item{
+		fstest.NewItem(stamp+"/sub dir/delete me", "delete me", t1),
+	}, nil, fs.GetModifyWindow(ctx, ftrash))
+
+	// Expired trash is purged leaving the rest
+	for _, name := range []string{"2000-01-02-030405/old", "not trash/notes"} {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(trashDir, name)), 0777))
+		require.NoError(t, os.WriteFile(path.Join(trashDir, name), []byte(name), 0666))
+	}
+	ci.TrashRetention = fs.Duration(24 * time.Hour)
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	entries, err = ftrash.List(ctx, "")
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Remote())
+	}
+	assert.Equal(t, []string{stamp, "not trash"}, names)
+
+	// The trash must be on the same remote as the destination
+	ci.TrashDir = ":memory:trash"
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	assert.ErrorContains(t, err, "same remote")
+}
+
+// deleteOrderFs wraps an Fs recording the order files and
+// directories are removed in
+type deleteOrderFs struct {
+	fs.Fs
+	mu      mutex.Mutex
+	removed []string
+}
+
+func (f *deleteOrderFs) record(name string) {
+	f.mu.Lock()
+	f.removed = append(f.removed, name)
+	f.mu.Unlock()
+}
+
+func (f *deleteOrderFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	for i, entry := range entries {
+		if o, ok := entry.(fs.Object); ok {
+			entries[i] = deleteOrderObject{Object: o, f: f}
+		}
+	}
+	return entries, err
+}
+
+func (f *deleteOrderFs) Rmdir(ctx context.Context, dir string) error {
+	err := f.Fs.Rmdir(ctx, dir)
+	if err == nil {
+		f.record(dir + "/")
+	}
+	return err
+}
+
+type deleteOrderObject struct {
+	fs.Object
+	f *deleteOrderFs
+}
+
+func (o deleteOrderObject) Remove(ctx context.Context) error {
+	o.f.record(o.Remote())
+	return o.Object.Remove(ctx)
+}
+
+// Test files are deleted deepest first and before the directories
+// which contain them
+func TestSyncDeleteOrder(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	file1 := r.WriteBoth(ctx, "keep", "keep", t1)
+	for _, name := range []string{"a", "d1/b", "d1/d2/c", "d1/d2/d3/e", "d1/d2/d3/f", "z/y"} {
+		r.WriteObject(ctx, name, name, t1)
+	}
+	fdst := &deleteOrderFs{Fs: r.Fremote}
+
+	ci.Checkers = 1
+	ci.DeleteMode = fs.DeleteModeAfter
+	err := Sync(ctx, fdst, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteListing(t, []fstest.Item{file1}, []string{})
+	assert.Equal(t, []string{
+		"d1/d2/d3/e", "d1/d2/d3/f", "d1/d2/c", "d1/b", "z/y", "a",
+		"z/", "d1/d2/d3/", "d1/d2/", "d1/",
+	}, fdst.removed)
+}
+
+// openCountFs wraps an Fs recording the most objects open for
+// reading at once
+type openCountFs struct {
+	fs.Fs
+	mu      mutex.Mutex
+	open    int
+	maxOpen int
+}
+
+func (f *openCountFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	for i, entry := range entries {
+		if o, ok := entry.(fs.Object); ok {
+			entries[i] = openCountObject{Object: o, f: f}
+		}
+	}
+	return entries, err
+}
+
+type openCountObject struct {
+	fs.Object
+	f *openCountFs
+}
+
+func (o openCountObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	in, err := o.Object.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	o.f.mu.Lock()
+	o.f.open++
+	o.f.maxOpen = max(o.f.maxOpen, o.f.open)
+	o.f.mu.Unlock()
+	// Give the other transfers a chance to open their files
+	time.Sleep(10 * time.Millisecond)
+	return openCountReader{ReadCloser: in, f: o.f}, nil
+}
+
+type openCountReader struct {
+	io.ReadCloser
+	f *openCountFs
+}
+
+func (r openCountReader) Close() error {
+	r.f.mu.Lock()
+	r.f.open--
+	r.f.mu.Unlock()
+	return r.ReadCloser.Close()
+}
+
+// Test --max-open-files limits the source files open at once
+func TestSyncMaxOpenFiles(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	var items []fstest.Item
+	for i := 0; i < 16; i++ {
+		items = append(items, r.WriteFile(fmt.Sprintf("file%02d", i), "data", t1))
+	}
+	fsrc := &openCountFs{Fs: r.Flocal}
+
+	ci.Transfers = 8
+	ci.MaxOpenFiles = 2
+	err := Sync(ctx, r.Fremote, fsrc, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, items...)
+	assert.Equal(t, 0, fsrc.open)
+	assert.LessOrEqual(t, fsrc.maxOpen, 2)
+	assert.Greater(t, fsrc.maxOpen, 0)
+}
@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// trashTimeFormat is the format of the name of the directory in
+// --trash-dir which each sync moves the files it deletes into
+const trashTimeFormat = "2006-01-02-150405"
+
+// trash moves the files deleted by a sync into a timestamped directory
+// in --trash-dir and purges directories in there older than
+// --trash-retention.
+//
+// A nil *trash does nothing.
+type trash struct {
+	root      fs.Fs         // --trash-dir
+	dir       fs.Fs         // the directory in root for this sync
+	now       time.Time     // time this sync started
+	retention time.Duration // how long to keep trash for, 0 for forever
+	sweepErr  chan error    // result of the background sweep if running
+}
+
+// newTrash makes the trash for a sync from fsrc to fdst, returning
+// nil if --trash-dir isn't set.
+func newTrash(ctx context.Context, fdst, fsrc fs.Fs) (*trash, error) {
+	ci := fs.GetConfig(ctx)
+	if ci.TrashDir == "" {
+		return nil, nil
+	}
+	root, err := cache.Get(ctx, ci.TrashDir)
+	if err != nil {
+		return nil, fserrors.FatalError(fmt.Errorf("failed to make fs for --trash-dir %q: %w", ci.TrashDir, err))
+	}
+	if !operations.SameConfig(fdst, root) {
+		return nil, fserrors.FatalError(errors.New("parameter to --trash-dir has to be on the same remote as destination"))
+	}
+	if operations.OverlappingFilterCheck(ctx, root, fdst) {
+		return nil, fserrors.FatalError(errors.New("destination and parameter to --trash-dir mustn't overlap"))
+	}
+	if operations.OverlappingFilterCheck(ctx, root, fsrc) {
+		return nil, fserrors.FatalError(errors.New("source and parameter to --trash-dir mustn't overlap"))
+	}
+	t := &trash{
+		root:      root,
+		now:       time.Now().UTC(),
+		retention: time.Duration(ci.TrashRetention),
+	}
+	dirPath := fspath.JoinRootPath(ci.TrashDir, t.now.Format(trashTimeFormat))
+	t.dir, err = cache.Get(ctx, dirPath)
+	if err != nil {
+		return nil, fserrors.FatalError(fmt.Errorf("failed to make fs for --trash-dir %q: %w", dirPath, err))
+	}
+	return t, nil
+}
+
+// fs returns the directory to move deleted files into or nil if not
+// using the trash.
+func (t *trash) fs() fs.Fs {
+	if t == nil {
+		return nil
+	}
+	return t.dir
+}
+
+// startSweep starts purging the expired trash in the background if
+// --trash-retention is set.
+func (t *trash) startSweep(ctx context.Context) {
+	if t == nil || t.retention <= 0 {
+		return
+	}
+	t.sweepErr = make(chan error, 1)
+	go func() {
+		t.sweepErr <- t.sweep(ctx)
+	}()
+}
+
+// stopSweep waits for the background sweep to finish and returns its
+// error.
+func (t *trash) stopSweep() error {
+	if t == nil || t.sweepErr == nil {
+		return nil
+	}
+	return <-t.sweepErr
+}
+
+// sweep purges the directories in the trash older than the retention.
+func (t *trash) sweep(ctx context.Context) error {
+	entries, err := t.root.List(ctx, "")
+	if errors.Is(err, fs.ErrorDirNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list --trash-dir: %w", err)
+	}
+	// Purge the whole of each expired directory regardless of the
+	// filters in use for the sync.
+	fi, err := filter.NewFilter(nil)
+	if err != nil {
+		return err
+	}
+	purgeCtx := filter.ReplaceConfig(ctx, fi)
+	var sweepErr error
+	for _, entry := range entries {
+		dir, ok := entry.(fs.Directory)
+		if !ok {
+			continue
+		}
+		when, err := time.Parse(trashTimeFormat, dir.Remote())
+		if err != nil {
+			fs.Debugf(t.root, "Ignoring %q in --trash-dir as it isn't a trash directory", dir.Remote())
+			continue
+		}
+		if t.now.Sub(when) <= t.retention {
+			continue
+		}
+		fs.Infof(t.root, "Purging %q from --trash-dir as it is older than --trash-retention %v", dir.Remote(), fs.Duration(t.retention))
+		err = operations.Purge(purgeCtx, t.root, dir.Remote())
+		if err != nil {
+			sweepErr = err
+		}
+	}
+	return sweepErr
+}
@@ -174,6 +174,14 @@ type ParentIDer interface {
 	ParentID() string
 }
 
+// Inoder is an optional interface for Object
+type Inoder interface {
+	// Inode returns the device and inode numbers of the Object and
+	// the number of hard links to it. ok is false if these aren't
+	// known.
+	Inode() (dev, ino, nlink uint64, ok bool)
+}
+
 // ObjectUnWrapper is an optional interface for Object
 type ObjectUnWrapper interface {
 	// UnWrap returns the Object that this Object is wrapping or
@@ -23,6 +23,14 @@ type Calculator interface {
 	Calculate(state State) time.Duration
 }
 
+// Jitterer is an optional interface for a Calculator which randomizes
+// the time slept without changing the SleepTime kept in the State.
+type Jitterer interface {
+	// Jitter takes the current Pacer state and returns the time to
+	// sleep for its SleepTime.
+	Jitter(state State) time.Duration
+}
+
 // Pacer is the primary type of the pacer package. It allows to retry calls
 // with a configurable delay in between.
 type Pacer struct {
@@ -162,11 +170,15 @@ func (p *Pacer) beginCall() {
 	}
 
 	p.mu.Lock()
+	sleepTime := p.state.SleepTime
+	if j, ok := p.calculator.(Jitterer); ok {
+		sleepTime = j.Jitter(p.state)
+	}
 	// Restart the timer
 	go func(t time.Duration) {
 		time.Sleep(t)
 		p.pacer <- struct{}{}
-	}(p.state.SleepTime)
+	}(sleepTime)
 	p.mu.Unlock()
 }
 
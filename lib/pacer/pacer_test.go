@@ -163,6 +163,50 @@ func TestDefaultPacer(t *testing.T) {
 
 }
 
+func TestDefaultPacerJitter(t *testing.T) {
+	c := NewDefault(MinSleep(1*time.Millisecond), MaxSleep(1*time.Second), DecayConstant(2), Jitter(0.5))
+	for _, test := range []struct {
+		state    State
+		min, max time.Duration
+	}{
+		{State{SleepTime: 200 * time.Millisecond, ConsecutiveRetries: 1}, 100 * time.Millisecond, 200 * time.Millisecond},
+		{State{SleepTime: 1 * time.Second, ConsecutiveRetries: 1}, 500 * time.Millisecond, 1 * time.Second},
+		{State{SleepTime: 2 * time.Millisecond, ConsecutiveRetries: 1}, 1 * time.Millisecond, 2 * time.Millisecond},
+		{State{SleepTime: 500 * time.Millisecond, ConsecutiveRetries: 1, LastError: RetryAfterError(errFoo, 500*time.Millisecond)}, 500 * time.Millisecond, 750 * time.Millisecond},
+		{State{SleepTime: 800 * time.Millisecond, ConsecutiveRetries: 1, LastError: RetryAfterError(errFoo, 800*time.Millisecond)}, 800 * time.Millisecond, 1 * time.Second},
+	} {
+		seen := map[time.Duration]struct{}{}
+		for i := 0; i < 100; i++ {
+			got := c.Jitter(test.state)
+			assert.GreaterOrEqual(t, got, test.min, "test: %+v", test)
+			assert.LessOrEqual(t, got, test.max, "test: %+v", test)
+			seen[got] = struct{}{}
+		}
+		assert.Greater(t, len(seen), 1, "retry sleeps should not all be identical: %+v", test)
+	}
+
+	// A Retry-After longer than the maximum sleep is kept
+	state := State{SleepTime: 2 * time.Second, ConsecutiveRetries: 1, LastError: RetryAfterError(errFoo, 2*time.Second)}
+	assert.Equal(t, 2*time.Second, c.Jitter(state))
+
+	// Sleeps without retries aren't randomized
+	assert.Equal(t, 750*time.Millisecond, c.Jitter(State{SleepTime: 750 * time.Millisecond}))
+
+	// The calculated sleep time isn't randomized
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 200*time.Millisecond, c.Calculate(State{SleepTime: 100 * time.Millisecond, ConsecutiveRetries: 1}))
+		assert.Equal(t, 2*time.Second, c.Calculate(State{ConsecutiveRetries: 1, LastError: RetryAfterError(errFoo, 2*time.Second)}))
+	}
+
+	// The backoff carries on from the calculated sleep time
+	p := New(CalculatorOption(c))
+	p.state.SleepTime = 100 * time.Millisecond
+	p.endCall(true, errFoo)
+	assert.Equal(t, 200*time.Millisecond, p.state.SleepTime)
+	p.endCall(true, errFoo)
+	assert.Equal(t, 400*time.Millisecond, p.state.SleepTime)
+}
+
 func TestAzureIMDSPacer(t *testing.T) {
 	c := NewAzureIMDS()
 	for _, test := range []struct {
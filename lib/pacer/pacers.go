@@ -18,6 +18,9 @@ type (
 	AttackConstant uint
 	// Burst configures the number of API calls to allow without sleeping
 	Burst int
+	// Jitter configures the fraction of each retry sleep of a
+	// Calculator which is randomized, from 0 to 1
+	Jitter float64
 )
 
 // Default is a truncated exponential attack and decay.
@@ -27,11 +30,19 @@ type (
 //
 // The sleep never goes below that set with SetMinSleep or above that set
 // with SetMaxSleep.
+//
+// If Jitter is set then retry sleeps are randomized so that callers
+// which were rate limited together don't all retry at the same time.
+// Up to that fraction is taken off exponential backoff sleeps and added
+// on to sleeps asked for with a Retry-After, without going over
+// MaxSleep. Only the time slept is randomized so the backoff carries on
+// from the calculated sleep time.
 type Default struct {
 	minSleep       time.Duration // minimum sleep time
 	maxSleep       time.Duration // maximum sleep time
 	decayConstant  uint          // decay constant
 	attackConstant uint          // attack constant
+	jitter         float64       // fraction of retry sleeps to randomize
 }
 
 // DefaultOption is the interface implemented by all options for the Default Calculator
@@ -78,11 +89,25 @@ func (o AttackConstant) ApplyDefault(c *Default) {
 	c.attackConstant = uint(o)
 }
 
+// ApplyDefault updates the value on the Calculator
+func (o Jitter) ApplyDefault(c *Default) {
+	c.jitter = float64(o)
+}
+
+// randomize returns a random duration in [0, jitter*d)
+func (c *Default) randomize(d time.Duration) time.Duration {
+	n := int64(c.jitter * float64(d))
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(n))
+}
+
 // Calculate takes the current Pacer state and return the wait time until the next try.
 func (c *Default) Calculate(state State) time.Duration {
 	if t, ok := IsRetryAfter(state.LastError); ok {
 		if t < c.minSleep {
-			return c.minSleep
+			t = c.minSleep
 		}
 		return t
 	}
@@ -95,6 +120,9 @@ func (c *Default) Calculate(state State) time.Duration {
 		if sleepTime > c.maxSleep {
 			sleepTime = c.maxSleep
 		}
+		if sleepTime < c.minSleep {
+			sleepTime = c.minSleep
+		}
 		return sleepTime
 	}
 	sleepTime := (state.SleepTime<<c.decayConstant - state.SleepTime) >> c.decayConstant
@@ -104,6 +132,29 @@ func (c *Default) Calculate(state State) time.Duration {
 	return sleepTime
 }
 
+// Jitter takes the current Pacer state and returns the time to sleep
+// for its SleepTime, randomized if Jitter is set and the call is being
+// retried.
+func (c *Default) Jitter(state State) time.Duration {
+	sleepTime := state.SleepTime
+	if _, ok := IsRetryAfter(state.LastError); ok {
+		// Don't let the jitter take the sleep over maxSleep unless
+		// the Retry-After asked for longer than that already
+		jittered := sleepTime + c.randomize(sleepTime)
+		if jittered > c.maxSleep {
+			jittered = max(sleepTime, c.maxSleep)
+		}
+		return jittered
+	}
+	if state.ConsecutiveRetries > 0 {
+		sleepTime -= c.randomize(sleepTime)
+		if sleepTime < c.minSleep {
+			sleepTime = c.minSleep
+		}
+	}
+	return sleepTime
+}
+
 // ZeroDelayCalculator is a Calculator that never delays.
 type ZeroDelayCalculator struct {
 }
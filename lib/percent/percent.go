@@ -0,0 +1,95 @@
+// Package percent implements percent-encoding with a configurable set
+// of characters which are left unencoded.
+package percent
+
+import (
+	"errors"
+	"strings"
+)
+
+// SafeSet is a set of bytes which don't need percent-encoding
+type SafeSet [256]bool
+
+// NewSafeSet makes a SafeSet which leaves the bytes in chars unencoded
+func NewSafeSet(chars string) *SafeSet {
+	var safe SafeSet
+	for i := 0; i < len(chars); i++ {
+		safe[chars[i]] = true
+	}
+	return &safe
+}
+
+// Unreserved is the set of characters RFC 3986 says never need encoding
+var Unreserved = NewSafeSet(`abcdefghijklmnopqrstuvwxyz` +
+	`ABCDEFGHIJKLMNOPQRSTUVWXYZ` +
+	`0123456789` +
+	`-._~`)
+
+// ErrBadEncoding is returned by Decode for a malformed % sequence
+var ErrBadEncoding = errors.New("bad percent-encoding")
+
+const upperHex = "0123456789ABCDEF"
+
+// Encode percent-encodes in, leaving bytes in safe alone.
+//
+// Each other byte is written as "%" followed by two upper case hex
+// digits.
+func Encode(in string, safe *SafeSet) string {
+	var out strings.Builder
+	out.Grow(len(in))
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		if safe[c] {
+			_ = out.WriteByte(c)
+		} else {
+			_ = out.WriteByte('%')
+			_ = out.WriteByte(upperHex[c>>4])
+			_ = out.WriteByte(upperHex[c&15])
+		}
+	}
+	return out.String()
+}
+
+// unhex returns the value of the hex digit c and whether it was valid
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Decode undoes percent-encoding in in.
+//
+// Any character which isn't part of a % sequence is passed through
+// unchanged, so "+" is not turned into a space. It returns
+// ErrBadEncoding if a "%" isn't followed by two hex digits.
+func Decode(in string) (string, error) {
+	if strings.IndexByte(in, '%') < 0 {
+		return in, nil
+	}
+	var out strings.Builder
+	out.Grow(len(in))
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		if c != '%' {
+			_ = out.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(in) {
+			return "", ErrBadEncoding
+		}
+		hi, okHi := unhex(in[i+1])
+		lo, okLo := unhex(in[i+2])
+		if !okHi || !okLo {
+			return "", ErrBadEncoding
+		}
+		_ = out.WriteByte(hi<<4 | lo)
+		i += 2
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,89 @@
+package percent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSafeSet(t *testing.T) {
+	safe := NewSafeSet("ab/")
+	assert.True(t, safe['a'])
+	assert.True(t, safe['/'])
+	assert.False(t, safe['c'])
+	assert.False(t, safe['%'])
+}
+
+func TestEncode(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		safe *SafeSet
+		want string
+	}{
+		{in: "", safe: Unreserved, want: ""},
+		{in: "hello", safe: Unreserved, want: "hello"},
+		{in: "a b/c", safe: Unreserved, want: "a%20b%2Fc"},
+		{in: "a b/c", safe: NewSafeSet("abc/"), want: "a%20b/c"},
+		{in: "\x00\x01\n\x7f\xff", safe: Unreserved, want: "%00%01%0A%7F%FF"},
+		{in: "100%", safe: Unreserved, want: "100%25"},
+		{in: "%41", safe: Unreserved, want: "%2541"},
+		{in: "自由", safe: Unreserved, want: "%E8%87%AA%E7%94%B1"},
+		{in: "+", safe: Unreserved, want: "%2B"},
+	} {
+		assert.Equal(t, test.want, Encode(test.in, test.safe), test.in)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{in: "", want: ""},
+		{in: "hello", want: "hello"},
+		{in: "a%20b%2Fc", want: "a b/c"},
+		{in: "a%2fb", want: "a/b"},
+		{in: "a+b", want: "a+b"},
+		{in: "%2541", want: "%41"},
+		{in: "%E8%87%AA%E7%94%B1", want: "自由"},
+		{in: "%", wantErr: ErrBadEncoding},
+		{in: "abc%4", wantErr: ErrBadEncoding},
+		{in: "%G1", wantErr: ErrBadEncoding},
+		{in: "%1G", wantErr: ErrBadEncoding},
+	} {
+		got, err := Decode(test.in)
+		if test.wantErr != nil {
+			assert.ErrorIs(t, err, test.wantErr, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var all []byte
+	for i := 0; i < 256; i++ {
+		all = append(all, byte(i))
+	}
+	// All printable ASCII apart from %
+	printable := NewSafeSet(strings.ReplaceAll(string(all[32:127]), "%", ""))
+	for _, in := range []string{
+		"",
+		"plain",
+		string(all),
+		"%41%42 already encoded %%",
+		"a+b c",
+		"😀 emoji/path/自由",
+	} {
+		for _, safe := range []*SafeSet{Unreserved, NewSafeSet(""), printable} {
+			got, err := Decode(Encode(in, safe))
+			require.NoError(t, err, fmt.Sprintf("%q", in))
+			assert.Equal(t, in, got, fmt.Sprintf("%q", in))
+		}
+	}
+}
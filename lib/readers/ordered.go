@@ -0,0 +1,163 @@
+package readers
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// orderedChunkSize is the size of the chunks parts are read in
+const orderedChunkSize = 32 * 1024
+
+// OpenFn opens a sub reader, typically a ranged download
+//
+// The ctx passed in is cancelled when the reader is no longer needed.
+type OpenFn func(ctx context.Context) (io.ReadCloser, error)
+
+// orderedPart is one sub reader of an OrderedMultiReader
+type orderedPart struct {
+	open OpenFn
+	data chan []byte // chunks read from the part - closed when done
+	err  error       // error reading the part - read only after data is closed
+	cur  []byte      // unread remains of the current chunk
+}
+
+// OrderedMultiReader presents several sub readers as one continuous
+// stream.
+//
+// The bytes are served strictly in the order of the sub readers, but
+// up to concurrency sub readers are opened and read in the background
+// into buffers of bufferSize bytes each.
+type OrderedMultiReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	parts  []*orderedPart
+	slots  chan struct{} // one token for each part in progress
+	wg     sync.WaitGroup
+	i      int // index of the part being read
+}
+
+// NewOrderedMultiReader returns a reader which reads the sub readers
+// opened by opens in order.
+//
+// At most concurrency sub readers will be in progress at once, each
+// buffering up to bufferSize bytes. Sub readers are opened in order
+// so the next one to be read is always in progress.
+//
+// Any error from opening, reading or closing a sub reader is returned
+// from Read once the data before it has been read.
+//
+// Close must be called to release the resources.
+func NewOrderedMultiReader(ctx context.Context, opens []OpenFn, concurrency int, bufferSize int) *OrderedMultiReader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	chunks := bufferSize / orderedChunkSize
+	if chunks < 1 {
+		chunks = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r := &OrderedMultiReader{
+		ctx:    ctx,
+		cancel: cancel,
+		parts:  make([]*orderedPart, len(opens)),
+		slots:  make(chan struct{}, concurrency),
+	}
+	for i, open := range opens {
+		r.parts[i] = &orderedPart{
+			open: open,
+			data: make(chan []byte, chunks),
+		}
+	}
+	r.wg.Add(1)
+	go r.start()
+	return r
+}
+
+// start the parts in order as slots become available
+func (r *OrderedMultiReader) start() {
+	defer r.wg.Done()
+	for _, part := range r.parts {
+		select {
+		case r.slots <- struct{}{}:
+		case <-r.ctx.Done():
+			return
+		}
+		r.wg.Add(1)
+		go r.readPart(part)
+	}
+}
+
+// readPart opens the part and reads it into its buffer
+func (r *OrderedMultiReader) readPart(part *orderedPart) {
+	defer r.wg.Done()
+	defer close(part.data)
+	in, err := part.open(r.ctx)
+	if err != nil {
+		part.err = err
+		return
+	}
+	defer func() {
+		closeErr := in.Close()
+		if part.err == nil {
+			part.err = closeErr
+		}
+	}()
+	for {
+		buf := make([]byte, orderedChunkSize)
+		n, err := io.ReadFull(in, buf)
+		if n > 0 {
+			select {
+			case part.data <- buf[:n]:
+			case <-r.ctx.Done():
+				part.err = r.ctx.Err()
+				return
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return
+		}
+		if err != nil {
+			part.err = err
+			return
+		}
+	}
+}
+
+// Read bytes as per io.Reader interface
+func (r *OrderedMultiReader) Read(p []byte) (n int, err error) {
+	for r.i < len(r.parts) {
+		part := r.parts[r.i]
+		if len(part.cur) == 0 {
+			select {
+			case chunk, ok := <-part.data:
+				if !ok {
+					if part.err != nil {
+						return 0, part.err
+					}
+					// Finished with this part so free its slot
+					<-r.slots
+					r.i++
+					continue
+				}
+				part.cur = chunk
+			case <-r.ctx.Done():
+				return 0, r.ctx.Err()
+			}
+		}
+		n = copy(p, part.cur)
+		part.cur = part.cur[n:]
+		return n, nil
+	}
+	return 0, io.EOF
+}
+
+// Close stops any background reading and closes all the sub readers
+func (r *OrderedMultiReader) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
+
+// Check interface
+var _ io.ReadCloser = (*OrderedMultiReader)(nil)
@@ -0,0 +1,201 @@
+package readers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPart is a sub reader which can be held up until released
+type testPart struct {
+	io.Reader
+	release chan struct{}
+	closed  *int32
+}
+
+func (p *testPart) Read(b []byte) (int, error) {
+	<-p.release
+	return p.Reader.Read(b)
+}
+
+func (p *testPart) Close() error {
+	atomic.AddInt32(p.closed, 1)
+	return nil
+}
+
+func TestOrderedMultiReaderOutOfOrder(t *testing.T) {
+	const n = 5
+	var (
+		closed   int32
+		want     bytes.Buffer
+		opens    []OpenFn
+		releases []chan struct{}
+	)
+	for i := 0; i < n; i++ {
+		data := bytes.Repeat([]byte(fmt.Sprintf("part %d,", i)), 1000*(i+1))
+		want.Write(data)
+		release := make(chan struct{})
+		releases = append(releases, release)
+		opens = append(opens, func(ctx context.Context) (io.ReadCloser, error) {
+			return &testPart{Reader: bytes.NewReader(data), release: release, closed: &closed}, nil
+		})
+	}
+	r := NewOrderedMultiReader(context.Background(), opens, n, 1024*1024)
+
+	// Let the parts complete in reverse order
+	go func() {
+		for i := n - 1; i >= 0; i-- {
+			close(releases[i])
+		}
+	}()
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, want.Bytes(), got)
+	require.NoError(t, r.Close())
+	assert.Equal(t, int32(n), atomic.LoadInt32(&closed))
+}
+
+func TestOrderedMultiReaderConcurrency(t *testing.T) {
+	const (
+		n           = 20
+		concurrency = 3
+	)
+	var (
+		mu            sync.Mutex
+		open, maxOpen int
+		want          bytes.Buffer
+		opens         []OpenFn
+	)
+	for i := 0; i < n; i++ {
+		data := bytes.Repeat([]byte{byte(i)}, 3*orderedChunkSize+i)
+		want.Write(data)
+		opens = append(opens, func(ctx context.Context) (io.ReadCloser, error) {
+			mu.Lock()
+			open++
+			if open > maxOpen {
+				maxOpen = open
+			}
+			mu.Unlock()
+			return readCloser{Reader: bytes.NewReader(data), close: func() error {
+				mu.Lock()
+				open--
+				mu.Unlock()
+				return nil
+			}}, nil
+		})
+	}
+	r := NewOrderedMultiReader(context.Background(), opens, concurrency, orderedChunkSize)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, want.Bytes(), got)
+	require.NoError(t, r.Close())
+	assert.LessOrEqual(t, maxOpen, concurrency)
+	assert.Equal(t, 0, open)
+}
+
+// readCloser adds a close function to a reader
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+func TestOrderedMultiReaderErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	ok := func(s string) OpenFn {
+		return func(ctx context.Context) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewBufferString(s)), nil
+		}
+	}
+	for _, test := range []struct {
+		name string
+		part OpenFn
+		want string
+	}{
+		{
+			name: "Open",
+			part: func(ctx context.Context) (io.ReadCloser, error) {
+				return nil, errBoom
+			},
+			want: "one,",
+		},
+		{
+			name: "Read",
+			part: func(ctx context.Context) (io.ReadCloser, error) {
+				return io.NopCloser(io.MultiReader(bytes.NewBufferString("two,"), ErrorReader{Err: errBoom})), nil
+			},
+			want: "one,two,",
+		},
+		{
+			name: "Close",
+			part: func(ctx context.Context) (io.ReadCloser, error) {
+				return readCloser{Reader: bytes.NewBufferString("two,"), close: func() error {
+					return errBoom
+				}}, nil
+			},
+			want: "one,two,",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewOrderedMultiReader(context.Background(), []OpenFn{ok("one,"), test.part, ok("three,")}, 3, 1024)
+			got, err := io.ReadAll(r)
+			assert.Equal(t, errBoom, err)
+			assert.Equal(t, test.want, string(got))
+			require.NoError(t, r.Close())
+		})
+	}
+}
+
+func TestOrderedMultiReaderClose(t *testing.T) {
+	const n = 10
+	var opened, closed int32
+	var opens []OpenFn
+	for i := 0; i < n; i++ {
+		opens = append(opens, func(ctx context.Context) (io.ReadCloser, error) {
+			atomic.AddInt32(&opened, 1)
+			// Blocks reading until the ctx is cancelled
+			return readCloser{Reader: &blockingReader{ctx: ctx}, close: func() error {
+				atomic.AddInt32(&closed, 1)
+				return nil
+			}}, nil
+		})
+	}
+	r := NewOrderedMultiReader(context.Background(), opens, 4, 1024)
+	require.NoError(t, r.Close())
+	assert.Equal(t, atomic.LoadInt32(&opened), atomic.LoadInt32(&closed))
+	assert.LessOrEqual(t, atomic.LoadInt32(&opened), int32(4))
+
+	// Reading after close should give an error
+	_, err := r.Read(make([]byte, 1))
+	assert.Equal(t, context.Canceled, err)
+}
+
+// blockingReader blocks until the ctx is cancelled
+type blockingReader struct {
+	ctx context.Context
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func TestOrderedMultiReaderEmpty(t *testing.T) {
+	r := NewOrderedMultiReader(context.Background(), nil, 4, 1024)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	require.NoError(t, r.Close())
+}
@@ -350,14 +350,15 @@ func (d *Dir) countActiveWriters() (writers int) {
 
 // age returns the duration since the last time the directory contents
 // was read and the content is considered stale. age will be 0 and
-// stale true if the last read time is empty.
+// stale true if the last read time is empty. The content is always
+// stale if --vfs-no-dir-cache is set.
 // age must be called with d.mu held.
 func (d *Dir) _age(when time.Time) (age time.Duration, stale bool) {
 	if d.read.IsZero() {
 		return age, true
 	}
 	age = when.Sub(d.read)
-	stale = age > time.Duration(d.vfs.Opt.DirCacheTime)
+	stale = d.vfs.Opt.NoDirCache || age > time.Duration(d.vfs.Opt.DirCacheTime)
 	return
 }
 
@@ -927,6 +928,17 @@ func (d *Dir) Stat(name string) (node Node, err error) {
 	return node, nil
 }
 
+// Refresh re-reads the contents of the directory from the backend now
+// even if the directory cache hasn't expired.
+//
+// Unlike ForgetAll the existing nodes are kept and updated.
+func (d *Dir) Refresh() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.read = time.Time{}
+	return d._readDir()
+}
+
 // ReadDirAll reads the contents of the directory sorted
 func (d *Dir) ReadDirAll() (items Nodes, err error) {
 	// fs.Debugf(d.path, "Dir.ReadDirAll")
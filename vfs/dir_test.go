@@ -12,6 +12,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -327,6 +328,37 @@ func TestDirReadDirAll(t *testing.T) {
 	})
 }
 
+// Check files written directly to the remote appear when the
+// directory cache is bypassed even though it hasn't expired
+func TestDirRefresh(t *testing.T) {
+	for _, noDirCache := range []bool{false, true} {
+		t.Run(fmt.Sprintf("NoDirCache=%v", noDirCache), func(t *testing.T) {
+			opt := vfscommon.Opt
+			opt.DirCacheTime = fs.Duration(time.Hour)
+			opt.NoDirCache = noDirCache
+			r, vfs := newTestVFSOpt(t, &opt)
+
+			_ = r.WriteObject(context.Background(), "dir/file1", "file1 contents", t1)
+			node, err := vfs.Stat("dir")
+			require.NoError(t, err)
+			dir := node.(*Dir)
+			checkListing(t, dir, []string{"file1,14,false"})
+
+			// Write behind the back of the VFS
+			_ = r.WriteObject(context.Background(), "dir/file2", "file2- contents", t2)
+			if noDirCache {
+				checkListing(t, dir, []string{"file1,14,false", "file2,15,false"})
+				return
+			}
+			checkListing(t, dir, []string{"file1,14,false"})
+
+			// Force a fresh listing
+			require.NoError(t, dir.Refresh())
+			checkListing(t, dir, []string{"file1,14,false", "file2,15,false"})
+		})
+	}
+}
+
 func TestDirOpen(t *testing.T) {
 	_, _, dir, _ := dirCreate(t)
 
@@ -460,6 +460,7 @@ the |--vfs-cache-mode| is off, it will return an empty result.
                 "tries":     1,        // integer: number of times we have tried to upload
                 "delay":     5.0,      // float: seconds between upload attempts
                 "uploading": false,    // boolean: true if item is being uploaded
+                "error":     "",       // string: error from the last failed upload or empty
             },
        ],
     }
@@ -471,6 +472,12 @@ only transfers |--transfers| files at once, only the lowest
 may be files with negative expiry times for which |uploading| is
 |false|.
 
+If an upload fails then the file stays in the queue, marked as dirty
+in the cache, and will be retried after |delay| seconds. The |error|
+from the last failed attempt is shown so uploads which are failing can
+be found even though the application which wrote the file has already
+closed it successfully.
+
 `, "|", "`") + getVFSHelp,
 		Fn: rcQueue,
 	})
@@ -19,6 +19,8 @@ import (
 // ReadFileHandle is an open for read file handle on a File
 type ReadFileHandle struct {
 	baseHandle
+	ctx         context.Context    // cancelled when the handle is closed
+	cancel      context.CancelFunc // cancel ctx
 	done        func(ctx context.Context, err error)
 	mu          sync.Mutex
 	cond        sync.Cond // cond lock for out of sequence reads
@@ -34,6 +36,7 @@ type ReadFileHandle struct {
 	noSeek      bool
 	sizeUnknown bool // set if size of source is not known
 	opened      bool
+	grown       bool // set if the object has grown so the reader needs reopening
 }
 
 // Check interfaces
@@ -56,7 +59,10 @@ func newReadFileHandle(f *File) (*ReadFileHandle, error) {
 		}
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	fh := &ReadFileHandle{
+		ctx:         ctx,
+		cancel:      cancel,
 		remote:      o.Remote(),
 		noSeek:      f.VFS().Opt.NoSeek,
 		file:        f,
@@ -76,18 +82,28 @@ func (fh *ReadFileHandle) openPending() (err error) {
 	}
 	o := fh.file.getObject()
 	opt := &fh.file.VFS().Opt
-	r, err := chunkedreader.New(context.TODO(), o, int64(opt.ChunkSize), int64(opt.ChunkSizeLimit), opt.ChunkStreams).Open()
+	r, err := chunkedreader.New(fh.ctx, o, int64(opt.ChunkSize), int64(opt.ChunkSizeLimit), opt.ChunkStreams).Open()
 	if err != nil {
 		return err
 	}
 	tr := accounting.GlobalStats().NewTransfer(o, nil)
 	fh.done = tr.Done
-	fh.r = tr.Account(context.TODO(), r).WithBuffer() // account the transfer
+	fh.r = tr.Account(fh.ctx, r).WithBufferSize(fh.bufferSize()) // account the transfer
 	fh.opened = true
+	fh.grown = false
 
 	return nil
 }
 
+// bufferSize returns the size of the in memory buffer to use for
+// reading, from --vfs-read-buffer-size or --buffer-size if not set
+func (fh *ReadFileHandle) bufferSize() int64 {
+	if size := fh.file.VFS().Opt.ReadBufferSize; size >= 0 {
+		return int64(size)
+	}
+	return int64(fs.GetConfig(fh.ctx).BufferSize)
+}
+
 // String converts it to printable
 func (fh *ReadFileHandle) String() string {
 	if fh == nil {
@@ -135,7 +151,7 @@ func (fh *ReadFileHandle) seek(offset int64, reopen bool) (err error) {
 	}
 	if !reopen {
 		fs.Debugf(fh.remote, "ReadFileHandle.seek from %d to %d (fs.RangeSeeker)", fh.offset, offset)
-		_, err = r.RangeSeek(context.TODO(), offset, io.SeekStart, -1)
+		_, err = r.RangeSeek(fh.ctx, offset, io.SeekStart, -1)
 		if err != nil {
 			fs.Debugf(fh.remote, "ReadFileHandle.Read fs.RangeSeeker failed: %v", err)
 			return err
@@ -150,7 +166,7 @@ func (fh *ReadFileHandle) seek(offset int64, reopen bool) (err error) {
 		// re-open with a seek
 		o := fh.file.getObject()
 		opt := &fh.file.VFS().Opt
-		r = chunkedreader.New(context.TODO(), o, int64(opt.ChunkSize), int64(opt.ChunkSizeLimit), opt.ChunkStreams)
+		r = chunkedreader.New(fh.ctx, o, int64(opt.ChunkSize), int64(opt.ChunkSizeLimit), opt.ChunkStreams)
 		_, err := r.Seek(offset, 0)
 		if err != nil {
 			fs.Debugf(fh.remote, "ReadFileHandle.Read seek failed: %v", err)
@@ -161,8 +177,9 @@ func (fh *ReadFileHandle) seek(offset int64, reopen bool) (err error) {
 			fs.Debugf(fh.remote, "ReadFileHandle.Read seek failed: %v", err)
 			return err
 		}
+		fh.grown = false
 	}
-	fh.r.UpdateReader(context.TODO(), r)
+	fh.r.UpdateReader(fh.ctx, r)
 	fh.offset = offset
 	return nil
 }
@@ -276,10 +293,21 @@ func (fh *ReadFileHandle) readAt(p []byte, off int64) (n int, err error) {
 	if doSeek && fh.noSeek {
 		return 0, ESPIPE
 	}
+	// Reading at or beyond the end of the file is EOF unless the
+	// file has grown since it was opened.
+	if off >= fh.size && !fh.sizeUnknown && (fh.noSeek || !fh.checkGrown()) {
+		fs.Debugf(fh.remote, "ReadFileHandle.Read attempt to read at or beyond end of file: %d >= %d", off, fh.size)
+		return 0, io.EOF
+	}
+	doReopen := false
+	if fh.grown {
+		// The reader was opened on the old object so open the new one
+		doSeek = true
+		doReopen = true
+	}
 	var newOffset int64
 	retries := 0
 	reqSize := len(p)
-	doReopen := false
 	lowLevelRetries := fs.GetConfig(context.TODO()).LowLevelRetries
 	for {
 		if doSeek {
@@ -348,6 +376,29 @@ func (fh *ReadFileHandle) readAt(p []byte, off int64) (n int, err error) {
 	return n, err
 }
 
+// checkGrown reads the object from the remote again to see if it has
+// grown since the handle was opened, for example because another
+// writer has appended to it. If it has then it updates the file's
+// object and the size, marks the handle so the reader is reopened and
+// returns true.
+//
+// Must be called with fh.mu held
+func (fh *ReadFileHandle) checkGrown() bool {
+	o, err := fh.file.Fs().NewObject(fh.ctx, fh.file.Path())
+	if err != nil {
+		fs.Debugf(fh.remote, "ReadFileHandle.Read failed to check if file has grown: %v", err)
+		return false
+	}
+	if o.Size() <= fh.size {
+		return false
+	}
+	fs.Debugf(fh.remote, "ReadFileHandle.Read file has grown from %d to %d since it was opened", fh.size, o.Size())
+	fh.file.setObjectNoUpdate(o)
+	fh.size = o.Size()
+	fh.grown = true
+	return true
+}
+
 func (fh *ReadFileHandle) checkHash() error {
 	if fh.hash == nil || !fh.readCalled || fh.offset < fh.size {
 		return nil
@@ -398,7 +449,7 @@ func (fh *ReadFileHandle) checkHash() error {
 func (fh *ReadFileHandle) Read(p []byte) (n int, err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
-	if fh.roffset >= fh.size && !fh.sizeUnknown {
+	if fh.roffset >= fh.size && !fh.sizeUnknown && (fh.noSeek || !fh.checkGrown()) {
 		return 0, io.EOF
 	}
 	n, err = fh.readAt(p, fh.roffset)
@@ -415,6 +466,9 @@ func (fh *ReadFileHandle) close() error {
 		return ECLOSED
 	}
 	fh.closed = true
+	// Stop any read ahead in progress - otherwise it carries on
+	// downloading data which nobody will read
+	fh.cancel()
 
 	if fh.opened {
 		var err error
@@ -4,8 +4,11 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -178,6 +181,46 @@ func TestReadFileHandleReadAt(t *testing.T) {
 	assert.Equal(t, ECLOSED, err)
 }
 
+func TestReadFileHandleEOF(t *testing.T) {
+	r, _, fh := readHandleCreate(t)
+	ctx := context.Background()
+	buf := make([]byte, 256)
+
+	// read exactly at the end
+	n, err := fh.ReadAt(buf, 16)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// read past the end
+	n, err = fh.ReadAt(buf, 17)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// read up to the end with Read then read at the end
+	assert.Equal(t, "0123456789abcdef", readString(t, fh, 256))
+	n, err = fh.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// Another writer appends to the file on the remote
+	file1 := r.WriteObject(ctx, "dir/file1", "0123456789abcdefGHIJ", t2)
+	r.CheckRemoteItems(t, file1)
+
+	// read after the file grew
+	assert.Equal(t, "GHIJ", readString(t, fh, 256))
+	assert.Equal(t, int64(20), fh.Size())
+	n, err = fh.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// read past the new end
+	n, err = fh.ReadAt(buf, 20)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	assert.NoError(t, fh.Close())
+}
+
 func TestReadFileHandleFlush(t *testing.T) {
 	_, _, fh := readHandleCreate(t)
 
@@ -230,3 +273,108 @@ func TestReadFileHandleRelease(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, fh.closed)
 }
+
+// slowObject is a large Object whose readers supply some data then
+// block until their context is cancelled, like a slow download
+type slowObject struct {
+	fs.Object
+	mu     sync.Mutex
+	read   int  // bytes read from all the readers
+	closed bool // set if a reader has been closed
+}
+
+func (o *slowObject) Size() int64 {
+	return 1 << 30
+}
+
+func (o *slowObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	return &slowReader{ctx: ctx, o: o, avail: 4096}, nil
+}
+
+type slowReader struct {
+	ctx   context.Context
+	o     *slowObject
+	avail int // bytes available before blocking
+}
+
+func (r *slowReader) Read(p []byte) (n int, err error) {
+	if r.avail <= 0 {
+		<-r.ctx.Done()
+		return 0, r.ctx.Err()
+	}
+	n = min(len(p), r.avail)
+	for i := range p[:n] {
+		p[i] = 'x'
+	}
+	r.avail -= n
+	r.o.mu.Lock()
+	r.o.read += n
+	r.o.mu.Unlock()
+	return n, nil
+}
+
+func (r *slowReader) Close() error {
+	r.o.mu.Lock()
+	r.o.closed = true
+	r.o.mu.Unlock()
+	return nil
+}
+
+// Check the read buffer size comes from the VFS options
+func TestReadFileHandleBufferSize(t *testing.T) {
+	_, vfs, fh := readHandleCreate(t)
+	ci := fs.GetConfig(context.Background())
+
+	// Defaults to --buffer-size
+	assert.Equal(t, int64(ci.BufferSize), fh.bufferSize())
+
+	vfs.Opt.ReadBufferSize = 64 * fs.Mebi
+	assert.Equal(t, int64(64*fs.Mebi), fh.bufferSize())
+
+	vfs.Opt.ReadBufferSize = 0
+	assert.Equal(t, int64(0), fh.bufferSize())
+
+	// Check reading still works without a buffer
+	assert.Equal(t, "0123", readString(t, fh, 4))
+	require.NoError(t, fh.Close())
+}
+
+// Check closing a handle stops the read ahead promptly
+func TestReadFileHandleCloseStopsReadAhead(t *testing.T) {
+	r, vfs := newTestVFS(t)
+	file1 := r.WriteObject(context.Background(), "file1", "0123456789abcdef", t1)
+	r.CheckRemoteItems(t, file1)
+
+	node, err := vfs.Stat("file1")
+	require.NoError(t, err)
+	file := node.(*File)
+	o := &slowObject{Object: file.getObject()}
+	file.setObjectNoUpdate(o)
+
+	fh, err := newReadFileHandle(file)
+	require.NoError(t, err)
+	assert.Equal(t, "x", readString(t, fh, 1))
+
+	// Close while the read ahead is blocked in the source
+	closed := make(chan error, 1)
+	go func() {
+		closed <- fh.Close()
+	}()
+	select {
+	case err = <-closed:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for Close - read ahead not stopped")
+	}
+
+	o.mu.Lock()
+	assert.True(t, o.closed, "source reader not closed")
+	read := o.read
+	o.mu.Unlock()
+
+	// Check nothing more is read from the source after close
+	time.Sleep(100 * time.Millisecond)
+	o.mu.Lock()
+	assert.Equal(t, read, o.read)
+	o.mu.Unlock()
+}
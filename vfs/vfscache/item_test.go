@@ -266,6 +266,41 @@ func TestItemWriteAtExisting(t *testing.T) {
 	checkObject(t, r, "existing", contents[:10]+"HELLO"+contents[15:95]+"THEND"+zeroes[:20]+"THEVERYEND")
 }
 
+func TestItemReadAtSparse(t *testing.T) {
+	r, c := newItemTestCache(t)
+
+	contents, obj, item := newFile(t, r, c, "existing")
+
+	require.NoError(t, item.Open(obj))
+
+	// Read the existing contents into the cache
+	buf := make([]byte, 100)
+	n, err := item.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(buf[:n]))
+
+	// Extend the file leaving holes then write past the end
+	const size = 1024 * 1024
+	require.NoError(t, item.Truncate(size/2))
+	n, err = item.WriteAt([]byte("THEND"), size)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.True(t, item.present())
+
+	// Remove the object so any read from the backend would fail
+	require.NoError(t, obj.Remove(context.Background()))
+
+	// Reading the holes returns zeros from the cache file
+	for _, off := range []int64{100, size / 4, size/2 - 50, size - 100} {
+		n, err = item.ReadAt(buf, off)
+		require.NoError(t, err, off)
+		assert.Equal(t, 100, n, off)
+		assert.Equal(t, zeroes, string(buf[:n]), off)
+	}
+
+	require.NoError(t, item.Close(nil))
+}
+
 func TestItemLoadMeta(t *testing.T) {
 	r, c := newItemTestCache(t)
 
@@ -74,6 +74,7 @@ type writeBackItem struct {
 	putFn     PutFn              // To write the object data
 	tries     int                // number of times we have tried to upload
 	delay     time.Duration      // delay between upload attempts
+	err       error              // error from the last upload attempt if it failed
 }
 
 // A writeBackItems implements a priority queue by implementing
@@ -374,6 +375,7 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 			// Upload was cancelled so reset timer
 			wbItem.delay = time.Duration(wb.opt.WriteBack)
 		} else {
+			wbItem.err = err
 			fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, will retry in %v: %v", wbItem.tries, wbItem.delay, err)
 		}
 		// push the item back on the queue for retry
@@ -478,6 +480,7 @@ type QueueInfo struct {
 	Tries     int     `json:"tries"`     // number of times we have tried to upload
 	Delay     float64 `json:"delay"`     // delay between upload attempts (s)
 	Uploading bool    `json:"uploading"` // true if item is being uploaded
+	Error     string  `json:"error"`     // error from the last failed upload attempt or empty
 }
 
 // Queue return info about the current upload queue
@@ -490,6 +493,10 @@ func (wb *WriteBack) Queue() []QueueInfo {
 
 	// Lookup all the items in no particular order
 	for _, wbItem := range wb.lookup {
+		var errString string
+		if wbItem.err != nil {
+			errString = wbItem.err.Error()
+		}
 		items = append(items, QueueInfo{
 			Name:      wbItem.name,
 			ID:        wbItem.id,
@@ -498,6 +505,7 @@ func (wb *WriteBack) Queue() []QueueInfo {
 			Tries:     wbItem.tries,
 			Delay:     wbItem.delay.Seconds(),
 			Uploading: wbItem.uploading,
+			Error:     errString,
 		})
 	}
 
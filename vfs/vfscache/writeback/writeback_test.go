@@ -337,6 +337,12 @@ func TestWriteBackAddFailRetry(t *testing.T) {
 	checkOnHeap(t, wb, wbItem)
 	checkInLookup(t, wb, wbItem)
 
+	// check the error is reported in the queue
+	queue := wb.Queue()
+	require.Equal(t, 1, len(queue))
+	assert.Equal(t, 1, queue[0].Tries)
+	assert.Equal(t, "transfer failed BOOM", queue[0].Error)
+
 	// check the retry
 	<-pi.started
 	checkNotOnHeap(t, wb, wbItem)
@@ -35,6 +35,11 @@ var OptionsInfo = fs.Options{{
 	Default: false,
 	Help:    "Refreshes the directory cache recursively in the background on start",
 	Groups:  "VFS",
+}, {
+	Name:    "vfs_no_dir_cache",
+	Default: false,
+	Help:    "Don't cache directory listings - re-read them from the backend every time",
+	Groups:  "VFS",
 }, {
 	Name:    "poll_interval",
 	Default: fs.Duration(time.Minute),
@@ -135,6 +140,11 @@ var OptionsInfo = fs.Options{{
 	Default: 0 * fs.Mebi,
 	Help:    "Extra read ahead over --buffer-size when using cache-mode full",
 	Groups:  "VFS",
+}, {
+	Name:    "vfs_read_buffer_size",
+	Default: fs.SizeSuffix(-1),
+	Help:    "In memory buffer size for each file open for reading without the cache (default --buffer-size)",
+	Groups:  "VFS",
 }, {
 	Name:    "vfs_used_is_size",
 	Default: false,
@@ -201,8 +211,10 @@ type Options struct {
 	ReadWait           fs.Duration   `config:"vfs_read_wait"`        // time to wait for in-sequence read
 	WriteBack          fs.Duration   `config:"vfs_write_back"`       // time to wait before writing back dirty files
 	ReadAhead          fs.SizeSuffix `config:"vfs_read_ahead"`       // bytes to read ahead in cache mode "full"
+	ReadBufferSize     fs.SizeSuffix `config:"vfs_read_buffer_size"` // in memory buffer for each uncached read file handle, -1 for --buffer-size
 	UsedIsSize         bool          `config:"vfs_used_is_size"`     // if true, use the `rclone size` algorithm for Used size
 	FastFingerprint    bool          `config:"vfs_fast_fingerprint"` // if set use fast fingerprints
+	NoDirCache         bool          `config:"vfs_no_dir_cache"`     // re-read directory listings every time
 	DiskSpaceTotalSize fs.SizeSuffix `config:"vfs_disk_space_total_size"`
 }
 